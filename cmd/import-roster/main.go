@@ -0,0 +1,62 @@
+// 批量导入学生名单
+//
+// 用法：go run ./cmd/import-roster -file roster.xlsx [-dry-run] [-batch 500] [-out roster-result.csv]
+// 名单为 CSV 或 XLSX，表头包含 username（学号）、name（姓名）、class（班级）、role（角色）。
+// 已有账号更新姓名以及名单中包含的班级、角色列（缺少的列保留原值），新账号生成初始密码写入结果文件；
+// 结果文件含密码，请妥善保管。
+package main
+
+import (
+	"CMS/config"
+	"CMS/internal/pkg/database"
+	"CMS/internal/services"
+	"flag"
+	"log"
+	"os"
+)
+
+func main() {
+	file := flag.String("file", "", "名单文件路径（.csv 或 .xlsx）")
+	dryRun := flag.Bool("dry-run", false, "只预览导入结果，不写入数据库")
+	batchSize := flag.Int("batch", services.RosterDefaultBatch, "每个事务处理的行数")
+	out := flag.String("out", "roster-result.csv", "结果文件路径")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	config.LoadedConfig = cfg
+
+	database.Init()
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal("读取名单文件失败:", err)
+	}
+	rows, invalid, serviceErr := services.ParseRoster(*file, data)
+	if serviceErr != nil {
+		log.Fatal("解析名单失败:", serviceErr.Message)
+	}
+	result, serviceErr := services.ImportRoster(0, rows, invalid, *dryRun, *batchSize)
+	if serviceErr != nil {
+		log.Fatal("导入名单失败:", serviceErr.Message)
+	}
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		log.Fatal("创建结果文件失败:", err)
+	}
+	defer f.Close()
+	if err := services.WriteRosterResult(f, result); err != nil {
+		log.Fatal("写入结果文件失败:", err)
+	}
+
+	log.Printf("导入名单完成（dry_run=%t）: 共%d行, 新建%d, 更新%d, 未变化%d, 失败%d, 结果文件 %s",
+		result.DryRun, result.Total, result.Created, result.Updated, result.Unchanged, result.Failed, *out)
+}
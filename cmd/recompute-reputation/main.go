@@ -0,0 +1,32 @@
+// 重算用户声望
+//
+// 用法：go run ./cmd/recompute-reputation
+// 根据 MySQL 中的点赞、采纳、举报记录重新计算所有用户的声望并重建 Redis 排行榜，
+// 在声望规则调整或增量数据与历史记录不一致时执行。可在线执行：重算期间的增量更新
+// 会在整体写回后按最新记录重新计算，不会丢失。
+package main
+
+import (
+	"CMS/config"
+	"CMS/internal/pkg/database"
+	"CMS/internal/services"
+	"CMS/pkg/redis"
+	"log"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	config.LoadedConfig = cfg
+
+	database.Init()
+	redis.Init()
+
+	changed, err := services.RecomputeReputation()
+	if err != nil {
+		log.Fatalf("重算声望失败: 已更新%d名用户, err=%v", changed, err)
+	}
+	log.Printf("重算声望完成: 共%d名用户声望发生变化", changed)
+}
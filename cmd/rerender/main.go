@@ -0,0 +1,32 @@
+// 重新渲染帖子 HTML
+//
+// 用法：go run ./cmd/rerender [-batch 200]
+// 在 Markdown 渲染规则变更后，或为引入 content_html 之前的历史帖子生成 HTML 时执行。
+package main
+
+import (
+	"CMS/config"
+	"CMS/internal/pkg/database"
+	"CMS/internal/services"
+	"flag"
+	"log"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 200, "每批处理的帖子数")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	config.LoadedConfig = cfg
+
+	database.Init()
+
+	updated, err := services.RerenderPosts(*batchSize)
+	if err != nil {
+		log.Fatalf("重新渲染帖子失败: 已更新%d条, err=%v", updated, err)
+	}
+	log.Printf("重新渲染帖子完成: 共更新%d条", updated)
+}
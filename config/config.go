@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
@@ -8,13 +10,28 @@ import (
 
 var LoadedConfig *Config
 
+const (
+	defaultJWTSecretKey = "cms_secret_key" // JWT 密钥的默认值，源码公开，不能作为文件签名密钥
+	minSigningKeyLength = 32
+)
+
 // Config 应用配置
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Log      LogConfig
-	Redis    RedisConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Log        LogConfig
+	Redis      RedisConfig
+	Realtime   RealtimeConfig
+	Webhook    WebhookConfig
+	Storage    StorageConfig
+	Feed       FeedConfig
+	Post       PostConfig
+	Reputation ReputationConfig
+	Login      LoginConfig
+	TwoFactor  TwoFactorConfig
+	OIDC       OIDCConfig
+	Privacy    PrivacyConfig
 }
 
 // ServerConfig 服务器配置
@@ -53,6 +70,90 @@ type RedisConfig struct {
 	DB       int
 }
 
+// RealtimeConfig 实时推送配置
+type RealtimeConfig struct {
+	MaxConnsPerUser  int      // 单个用户的最大长连接数
+	HeartbeatSeconds int      // 心跳间隔（秒）
+	HistorySize      int      // 用于断线重连补发的事件缓存条数
+	TicketSeconds    int      // WebSocket 连接票据有效期（秒）
+	AllowedOrigins   []string // 允许建立 WebSocket 连接的页面来源（如 https://cms.example.com），同源请求始终允许
+}
+
+// WebhookConfig 外发 Webhook 配置
+type WebhookConfig struct {
+	MaxAttempts      int // 最大投递次数，超过后进入死信
+	TimeoutSeconds   int // 单次请求超时（秒）
+	BaseDelaySeconds int // 重试退避的基础间隔（秒），按 2^n 递增
+}
+
+// StorageConfig 附件存储配置
+type StorageConfig struct {
+	Driver           string // 存储类型，目前支持 local
+	LocalPath        string // 本地存储根目录
+	SigningKey       string // 文件访问链接签名密钥，必须单独配置的随机字符串（至少32个字符）
+	URLExpireMinutes int    // 文件访问链接有效期（分钟）
+	StudentMaxBytes  int64  // 学生单个文件大小上限
+	AdminMaxBytes    int64  // 管理员单个文件大小上限
+	ThumbnailSize    int    // 缩略图长边像素
+	OrphanHours      int    // 未关联帖子的附件保留时长（小时）
+}
+
+// FeedConfig 关注动态配置
+type FeedConfig struct {
+	FanoutThreshold int // 粉丝数达到该值的用户改为读扩散，不再写入粉丝的时间线
+	TimelineSize    int // 每个用户 Redis 时间线保留的最大条数
+	BackfillSize    int // 关注用户时回填到时间线的帖子数
+}
+
+// PostConfig 发帖配置
+type PostConfig struct {
+	AnonymousPerDay int // 每个用户24小时内最多发布的匿名帖子数
+}
+
+// ReputationConfig 声望配置
+type ReputationConfig struct {
+	PreModeration    bool // 是否开启发帖先审后发
+	TrustedThreshold int  // 声望达到该值的用户发帖免审核
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	WindowMinutes   int // 登录失败次数的统计窗口（分钟）
+	DelayAfter      int // 同一账号失败达到该次数后，每次失败都需等待递增的间隔才能重试
+	MaxDelaySeconds int // 递增等待间隔的上限（秒）
+	MaxUserFailures int // 同一账号在窗口内失败达到该次数后锁定账号
+	MaxIPFailures   int // 同一 IP 在窗口内失败达到该次数后锁定该 IP
+	LockMinutes     int // 锁定时长（分钟）
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer           string // 验证器应用中显示的发行方名称
+	RequiredForAdmin bool   // 是否强制管理员启用两步验证后才能访问 /api/admin/*
+}
+
+// OIDCConfig 统一身份认证（OpenID Connect）登录配置
+type OIDCConfig struct {
+	Enabled       bool     // 是否启用统一身份认证登录
+	Issuer        string   // 身份提供方地址，需与发现文档中的 issuer 完全一致
+	ClientID      string   // 在身份提供方注册的客户端ID
+	ClientSecret  string   // 客户端密钥，公共客户端可留空（仅依赖 PKCE）
+	RedirectURL   string   // 回调地址，需与身份提供方登记的一致
+	Scopes        []string // 申请的 scope，必须包含 openid
+	UsernameClaim string   // 作为用户名（学号）的声明
+	NameClaim     string   // 作为姓名的声明
+	GroupsClaim   string   // 用户组声明
+	AdminGroups   []string // 属于其中任一组的用户映射为管理员；为空时不按用户组同步角色
+	AutoProvision bool     // 首次登录时是否自动创建学生账号
+}
+
+// PrivacyConfig 个人数据导出和账号注销配置
+type PrivacyConfig struct {
+	ExportExpireHours    int    // 导出文件的保留时长（小时），过期后删除
+	ExportCooldownHours  int    // 同一用户两次申请导出的最短间隔（小时）
+	DeletedContentPolicy string // 注销账号时已发布内容的处理方式：anonymize-匿名化保留，delete-删除
+}
+
 // Load 加载配置
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
@@ -77,6 +178,20 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// Validate 校验启动服务所需的安全配置，不满足时拒绝启动
+func (c *Config) Validate() error {
+	key := c.Storage.SigningKey
+	switch {
+	case key == "":
+		return errors.New("未配置 storage.signingKey，请设置随机生成的文件签名密钥")
+	case key == defaultJWTSecretKey || key == c.JWT.SecretKey:
+		return errors.New("storage.signingKey 不能使用默认值或与 jwt.secretKey 相同")
+	case len(key) < minSigningKeyLength:
+		return fmt.Errorf("storage.signingKey 长度不能少于%d个字符", minSigningKeyLength)
+	}
+	return nil
+}
+
 // 设置默认配置
 func setDefaults() {
 	// 服务器默认配置
@@ -93,7 +208,7 @@ func setDefaults() {
 	viper.SetDefault("database.connMaxIdleTime", 60)
 
 	// JWT默认配置
-	viper.SetDefault("jwt.secretKey", "cms_secret_key")
+	viper.SetDefault("jwt.secretKey", defaultJWTSecretKey)
 	viper.SetDefault("jwt.expirationHours", 24)
 
 	// 日志默认配置
@@ -106,4 +221,64 @@ func setDefaults() {
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 
+	// 实时推送默认配置
+	viper.SetDefault("realtime.maxConnsPerUser", 3)
+	viper.SetDefault("realtime.heartbeatSeconds", 15)
+	viper.SetDefault("realtime.historySize", 1000)
+	viper.SetDefault("realtime.ticketSeconds", 30)
+	viper.SetDefault("realtime.allowedOrigins", []string{})
+
+	// Webhook默认配置
+	viper.SetDefault("webhook.maxAttempts", 8)
+	viper.SetDefault("webhook.timeoutSeconds", 10)
+	viper.SetDefault("webhook.baseDelaySeconds", 30)
+
+	// 附件存储默认配置
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.localPath", "uploads/")
+	viper.SetDefault("storage.signingKey", "")
+	viper.SetDefault("storage.urlExpireMinutes", 30)
+	viper.SetDefault("storage.studentMaxBytes", 5<<20)
+	viper.SetDefault("storage.adminMaxBytes", 20<<20)
+	viper.SetDefault("storage.thumbnailSize", 320)
+	viper.SetDefault("storage.orphanHours", 24)
+
+	// 关注动态默认配置
+	viper.SetDefault("feed.fanoutThreshold", 1000)
+	viper.SetDefault("feed.timelineSize", 800)
+	viper.SetDefault("feed.backfillSize", 50)
+
+	// 发帖默认配置
+	viper.SetDefault("post.anonymousPerDay", 3)
+
+	// 声望默认配置
+	viper.SetDefault("reputation.preModeration", false)
+	viper.SetDefault("reputation.trustedThreshold", 50)
+
+	// 登录防暴力破解默认配置
+	viper.SetDefault("login.windowMinutes", 15)
+	viper.SetDefault("login.delayAfter", 3)
+	viper.SetDefault("login.maxDelaySeconds", 60)
+	viper.SetDefault("login.maxUserFailures", 10)
+	viper.SetDefault("login.maxIPFailures", 50)
+	viper.SetDefault("login.lockMinutes", 15)
+
+	// 两步验证默认配置
+	viper.SetDefault("twoFactor.issuer", "CMS")
+	viper.SetDefault("twoFactor.requiredForAdmin", false)
+
+	// 统一身份认证默认配置
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.scopes", []string{"openid", "profile"})
+	viper.SetDefault("oidc.usernameClaim", "student_id")
+	viper.SetDefault("oidc.nameClaim", "name")
+	viper.SetDefault("oidc.groupsClaim", "groups")
+	viper.SetDefault("oidc.adminGroups", []string{})
+	viper.SetDefault("oidc.autoProvision", true)
+
+	// 个人数据导出和账号注销默认配置
+	viper.SetDefault("privacy.exportExpireHours", 72)
+	viper.SetDefault("privacy.exportCooldownHours", 24)
+	viper.SetDefault("privacy.deletedContentPolicy", "anonymize")
+
 }
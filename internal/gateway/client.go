@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/services"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	sendQueueSize   = 256              // 单连接发送缓冲区，写满视为慢连接并断开
+	writeTimeout    = 10 * time.Second // 单条消息写超时
+	maxMessageBytes = 4 << 10          // 客户端单条消息上限
+)
+
+// Client 一条 WebSocket 连接
+type Client struct {
+	hub      *Hub
+	conn     *websocket.Conn
+	userID   uint
+	send     chan []byte
+	channels map[string]struct{} // 由 hub.mu 保护
+
+	// 已订阅的匿名帖子频道，这些频道的在线状态和输入提示不带用户ID，避免暴露匿名作者；只在读协程中访问
+	anonymousChannels map[string]bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, userID uint) *Client {
+	conn.MaxPayloadBytes = maxMessageBytes
+	return &Client{
+		hub:      hub,
+		conn:     conn,
+		userID:   userID,
+		send:     make(chan []byte, sendQueueSize),
+		channels: make(map[string]struct{}),
+		done:     make(chan struct{}),
+
+		anonymousChannels: make(map[string]bool),
+	}
+}
+
+// enqueue 非阻塞地放入发送队列，队列已满说明客户端消费过慢，直接断开连接（背压）
+func (c *Client) enqueue(payload []byte) {
+	select {
+	case <-c.done:
+	case c.send <- payload:
+	default:
+		logger.GetLogger().Errorf("WebSocket发送队列已满，断开慢连接: user_id=%d", c.userID)
+		c.close()
+	}
+}
+
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func heartbeatInterval() time.Duration {
+	return time.Duration(config.LoadedConfig.Realtime.HeartbeatSeconds) * time.Second
+}
+
+// writeLoop 负责所有写操作，并定时发送心跳
+func (c *Client) writeLoop() {
+	ticker := time.NewTicker(heartbeatInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case payload := <-c.send:
+			if err := c.write(payload); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			services.RefreshStreamSlot(c.userID)
+			if err := c.write(encode(OutboundMessage{Type: MessageTypePing})); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) write(payload []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return websocket.Message.Send(c.conn, string(payload))
+}
+
+// readLoop 读取并处理客户端消息，超过3个心跳周期没有任何消息视为断线
+func (c *Client) readLoop() {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(3 * heartbeatInterval()))
+
+		var raw string
+		if err := websocket.Message.Receive(c.conn, &raw); err != nil {
+			return
+		}
+
+		var msg InboundMessage
+		if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+			c.enqueue(errorMessage("消息格式错误"))
+			continue
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *Client) handle(msg InboundMessage) {
+	switch msg.Action {
+	case ActionSubscribe:
+		c.handleSubscribe(msg.Channel)
+	case ActionUnsubscribe:
+		c.handleUnsubscribe(msg.Channel)
+	case ActionTyping:
+		c.handleTyping(msg.Channel)
+	case ActionLike:
+		c.handleLike(msg.PostID)
+	case ActionPing:
+		c.enqueue(encode(OutboundMessage{Type: MessageTypePong}))
+	case ActionPong:
+		// 读超时已在 readLoop 中续期
+	default:
+		c.enqueue(errorMessage("未知的操作: " + msg.Action))
+	}
+}
+
+func (c *Client) handleSubscribe(channel string) {
+	post, err := validateChannel(channel)
+	if err != nil {
+		c.enqueue(errorMessage(err.Error()))
+		return
+	}
+	if !c.hub.subscribe(c, channel) {
+		return
+	}
+
+	data := map[string]interface{}{}
+	if post != nil {
+		online := joinPresence(channel, c.userID)
+		if post.IsAnonymous {
+			c.anonymousChannels[channel] = true
+			data["online_count"] = len(online)
+		} else {
+			data["online_users"] = online
+		}
+		broadcastPresence(channel, c.userID, "join", post.IsAnonymous)
+	}
+	c.enqueue(encode(OutboundMessage{Type: MessageTypeSubscribed, Channel: channel, Data: data}))
+}
+
+func (c *Client) handleUnsubscribe(channel string) {
+	if !c.hub.unsubscribe(c, channel) {
+		return
+	}
+	if isPostChannel(channel) {
+		leavePresence(channel, c.userID)
+		broadcastPresence(channel, c.userID, "leave", c.anonymousChannels[channel])
+		delete(c.anonymousChannels, channel)
+	}
+	c.enqueue(encode(OutboundMessage{Type: MessageTypeUnsubscribed, Channel: channel}))
+}
+
+func (c *Client) handleTyping(channel string) {
+	if !isPostChannel(channel) || !c.hub.isSubscribed(c, channel) {
+		c.enqueue(errorMessage("请先订阅该帖子频道"))
+		return
+	}
+	data := map[string]uint{}
+	if !c.anonymousChannels[channel] {
+		data["user_id"] = c.userID
+	}
+	publish(envelope{
+		Channel:       channel,
+		ExcludeUserID: c.userID,
+		Payload: encode(OutboundMessage{
+			Type:    MessageTypeTyping,
+			Channel: channel,
+			Data:    data,
+		}),
+	})
+}
+
+func (c *Client) handleLike(postID uint) {
+	result, serviceErr := services.ToggleLike(postID, c.userID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("WebSocket点赞失败: user_id=%d, post_id=%d, error=%v", c.userID, postID, serviceErr)
+		c.enqueue(errorMessage(serviceErr.Message))
+		return
+	}
+	result["post_id"] = postID
+	c.enqueue(encode(OutboundMessage{Type: MessageTypeLikeResult, Data: result}))
+}
+
+// cleanup 断开后注销连接并广播离开
+func (c *Client) cleanup() {
+	for _, channel := range c.hub.unregister(c) {
+		if isPostChannel(channel) {
+			leavePresence(channel, c.userID)
+			broadcastPresence(channel, c.userID, "leave", c.anonymousChannels[channel])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import "sync"
+
+// Hub 管理本实例上的所有 WebSocket 连接及其频道订阅
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[*Client]struct{}
+	channels map[string]map[*Client]struct{}
+}
+
+var defaultHub = newHub()
+
+func newHub() *Hub {
+	return &Hub{
+		clients:  make(map[*Client]struct{}),
+		channels: make(map[string]map[*Client]struct{}),
+	}
+}
+
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = struct{}{}
+}
+
+// unregister 移除连接并返回其订阅过的频道
+func (h *Hub) unregister(c *Client) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, c)
+	channels := make([]string, 0, len(c.channels))
+	for channel := range c.channels {
+		h.removeFromChannel(c, channel)
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// subscribe 订阅频道，已订阅时返回 false
+func (h *Hub) subscribe(c *Client, channel string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := c.channels[channel]; ok {
+		return false
+	}
+	if h.channels[channel] == nil {
+		h.channels[channel] = make(map[*Client]struct{})
+	}
+	h.channels[channel][c] = struct{}{}
+	c.channels[channel] = struct{}{}
+	return true
+}
+
+// unsubscribe 取消订阅频道，未订阅时返回 false
+func (h *Hub) unsubscribe(c *Client, channel string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := c.channels[channel]; !ok {
+		return false
+	}
+	h.removeFromChannel(c, channel)
+	return true
+}
+
+// isSubscribed 判断连接是否订阅了频道
+func (h *Hub) isSubscribed(c *Client, channel string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := c.channels[channel]
+	return ok
+}
+
+// removeFromChannel 调用方需持有写锁
+func (h *Hub) removeFromChannel(c *Client, channel string) {
+	delete(c.channels, channel)
+	if subs, ok := h.channels[channel]; ok {
+		delete(subs, c)
+		if len(subs) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+// deliver 将消息投递给本实例上的目标连接
+func (h *Hub) deliver(env envelope) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if env.UserID != 0 {
+		for c := range h.clients {
+			if c.userID == env.UserID {
+				c.enqueue(env.Payload)
+			}
+		}
+		return
+	}
+
+	for c := range h.channels[env.Channel] {
+		if env.ExcludeUserID != 0 && c.userID == env.ExcludeUserID {
+			continue
+		}
+		c.enqueue(env.Payload)
+	}
+}
@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 客户端发送的动作
+const (
+	ActionSubscribe   = "subscribe"   // 订阅频道
+	ActionUnsubscribe = "unsubscribe" // 取消订阅频道
+	ActionTyping      = "typing"      // 正在输入（评论区）
+	ActionLike        = "like"        // 点赞/取消点赞帖子
+	ActionPing        = "ping"        // 客户端心跳
+	ActionPong        = "pong"        // 回应服务端心跳
+)
+
+// 服务端推送的消息类型
+const (
+	MessageTypeSubscribed   = "subscribed"
+	MessageTypeUnsubscribed = "unsubscribed"
+	MessageTypeTyping       = "typing"
+	MessageTypePresence     = "presence"
+	MessageTypeLikeResult   = "like_result"
+	MessageTypePing         = "ping"
+	MessageTypePong         = "pong"
+	MessageTypeError        = "error"
+)
+
+// 频道前缀：post:{帖子ID}、board:{版块ID}（board:0 为全站）
+const (
+	channelPostPrefix  = "post:"
+	channelBoardPrefix = "board:"
+)
+
+// InboundMessage 客户端消息
+type InboundMessage struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel,omitempty"`
+	PostID  uint   `json:"post_id,omitempty"`
+}
+
+// OutboundMessage 服务端消息
+type OutboundMessage struct {
+	Type    string      `json:"type"`
+	Channel string      `json:"channel,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// envelope 跨实例分发的消息封装：UserID 非0时定向发给该用户，否则发给频道订阅者
+type envelope struct {
+	Channel       string          `json:"channel,omitempty"`
+	UserID        uint            `json:"user_id,omitempty"`
+	ExcludeUserID uint            `json:"exclude_user_id,omitempty"` // 不发给该用户（如输入提示不回显给自己）
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func encode(msg OutboundMessage) []byte {
+	payload, _ := json.Marshal(msg)
+	return payload
+}
+
+func errorMessage(message string) []byte {
+	return encode(OutboundMessage{Type: MessageTypeError, Data: map[string]string{"message": message}})
+}
+
+// PostChannel 帖子频道名
+func PostChannel(postID uint) string {
+	return channelPostPrefix + strconv.Itoa(int(postID))
+}
+
+// BoardChannel 版块频道名
+func BoardChannel(boardID uint) string {
+	return channelBoardPrefix + strconv.Itoa(int(boardID))
+}
+
+// validateChannel 校验频道名格式，帖子频道要求帖子存在且已发布；返回帖子频道对应的帖子，版块频道返回 nil
+func validateChannel(channel string) (*models.Post, error) {
+	var idStr string
+	switch {
+	case strings.HasPrefix(channel, channelPostPrefix):
+		idStr = strings.TrimPrefix(channel, channelPostPrefix)
+	case strings.HasPrefix(channel, channelBoardPrefix):
+		idStr = strings.TrimPrefix(channel, channelBoardPrefix)
+	default:
+		return nil, fmt.Errorf("未知的频道: %s", channel)
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的频道: %s", channel)
+	}
+	if !isPostChannel(channel) {
+		return nil, nil
+	}
+	post, err := services.GetPostByID(uint(id))
+	if err != nil || post.Status != models.PostStatusPublished {
+		return nil, fmt.Errorf("帖子不存在: %s", channel)
+	}
+	return &post, nil
+}
+
+// isPostChannel 判断是否为帖子频道（只有帖子频道有在线状态和输入提示）
+func isPostChannel(channel string) bool {
+	return strings.HasPrefix(channel, channelPostPrefix)
+}
@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestValidateChannel(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.Post{}); err != nil {
+		t.Fatal(err)
+	}
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = prev
+		sqlDB.Close()
+	})
+
+	published := models.Post{Content: "公开", UserID: 1, IsAnonymous: true}
+	draft := models.Post{Content: "草稿", UserID: 1}
+	db.Create(&published)
+	db.Create(&draft)
+	db.Model(&published).Update("status", models.PostStatusPublished)
+	db.Model(&draft).Update("status", models.PostStatusDraft)
+
+	post, err := validateChannel(PostChannel(published.ID))
+	if err != nil || post == nil || !post.IsAnonymous {
+		t.Fatalf("已发布帖子的频道应可订阅: %+v, %v", post, err)
+	}
+	if post, err := validateChannel(BoardChannel(0)); err != nil || post != nil {
+		t.Fatalf("版块频道应可订阅: %+v, %v", post, err)
+	}
+	for _, channel := range []string{PostChannel(draft.ID), PostChannel(9999), "post:abc", "user:1"} {
+		if _, err := validateChannel(channel); err == nil {
+			t.Errorf("频道 %s 应被拒绝", channel)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/redis"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Redis 键名定义
+const (
+	gatewayChannel = "ws:channel"   // 网关消息跨实例分发频道：pub/sub
+	presenceKey    = "ws:presence:" // 帖子频道在线用户：hash类型（field为用户ID，value为连接数）
+	presenceExpire = 24 * time.Hour
+)
+
+// publish 通过 Redis 将消息分发给所有实例（包括本实例）
+func publish(env envelope) {
+	payload, _ := json.Marshal(env)
+	if err := redis.RedisClient.Publish(context.Background(), gatewayChannel, payload).Err(); err != nil {
+		logger.GetLogger().Errorf("发布网关消息失败: channel=%s, err=%v", env.Channel, err)
+	}
+}
+
+// broadcastPresence 广播用户进入或离开帖子频道，匿名帖子不带用户ID
+func broadcastPresence(channel string, userID uint, status string, anonymous bool) {
+	data := map[string]interface{}{"status": status}
+	if !anonymous {
+		data["user_id"] = userID
+	}
+	publish(envelope{
+		Channel:       channel,
+		ExcludeUserID: userID,
+		Payload: encode(OutboundMessage{
+			Type:    MessageTypePresence,
+			Channel: channel,
+			Data:    data,
+		}),
+	})
+}
+
+// joinPresence 记录用户进入帖子频道，返回当前在线用户列表
+func joinPresence(channel string, userID uint) []uint {
+	ctx := context.Background()
+	key := presenceKey + channel
+	redis.RedisClient.HIncrBy(ctx, key, strconv.Itoa(int(userID)), 1)
+	redis.RedisClient.Expire(ctx, key, presenceExpire)
+
+	fields, err := redis.RedisClient.HKeys(ctx, key).Result()
+	if err != nil {
+		logger.GetLogger().Errorf("获取频道在线用户失败: channel=%s, err=%v", channel, err)
+		return nil
+	}
+	online := make([]uint, 0, len(fields))
+	for _, field := range fields {
+		if id, err := strconv.ParseUint(field, 10, 64); err == nil {
+			online = append(online, uint(id))
+		}
+	}
+	return online
+}
+
+// leavePresence 记录用户离开帖子频道，同一用户的连接全部离开后才移除
+func leavePresence(channel string, userID uint) {
+	ctx := context.Background()
+	key := presenceKey + channel
+	field := strconv.Itoa(int(userID))
+	if count, err := redis.RedisClient.HIncrBy(ctx, key, field, -1).Result(); err == nil && count <= 0 {
+		redis.RedisClient.HDel(ctx, key, field)
+	}
+}
+
+// handleRealtimeEvent 将业务实时事件转换为网关消息，事件已由 services 跨实例分发，这里只投递本实例
+func handleRealtimeEvent(evt models.RealtimeEvent) {
+	msg := OutboundMessage{Type: evt.Type, Data: evt.Data}
+	// 定向事件（通知、私信等）直接投递给目标用户
+	if evt.UserID != 0 {
+		defaultHub.deliver(envelope{UserID: evt.UserID, Payload: encode(msg)})
+		return
+	}
+	switch evt.Type {
+	case models.EventTypeLikeCount:
+		msg.Channel = PostChannel(evt.PostID)
+		defaultHub.deliver(envelope{Channel: msg.Channel, Payload: encode(msg)})
+	case models.EventTypeNewPost:
+		// 新帖子推送到全站频道，属于版块的帖子同时推送到对应版块频道
+		msg.Channel = BoardChannel(0)
+		defaultHub.deliver(envelope{Channel: msg.Channel, Payload: encode(msg)})
+
+		var post models.PostResponse
+		if err := json.Unmarshal(evt.Data, &post); err == nil && post.BoardID != 0 {
+			msg.Channel = BoardChannel(post.BoardID)
+			defaultHub.deliver(envelope{Channel: msg.Channel, Payload: encode(msg)})
+		}
+	}
+}
+
+// Init 注册业务事件监听，需在 services.StartEventSubscriber 之前调用
+func Init() {
+	services.AddEventListener(handleRealtimeEvent)
+}
+
+// StartSubscriber 订阅网关频道并投递给本实例的连接，需在启动时以协程运行
+func StartSubscriber() {
+	ctx := context.Background()
+	for {
+		pubsub := redis.RedisClient.Subscribe(ctx, gatewayChannel)
+		for msg := range pubsub.Channel() {
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				logger.GetLogger().Errorf("解析网关消息失败: %v", err)
+				continue
+			}
+			defaultHub.deliver(env)
+		}
+		pubsub.Close()
+		logger.GetLogger().Error("网关消息订阅中断，1秒后重新订阅")
+		time.Sleep(time.Second)
+	}
+}
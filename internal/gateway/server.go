@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/websocket"
+)
+
+var errOriginNotAllowed = errors.New("origin not allowed")
+
+// CheckOrigin 校验发起 WebSocket 连接的页面来源：同源或在 realtime.allowedOrigins 中的来源才允许，
+// 防止其他站点的页面借用户的浏览器建立连接。非浏览器客户端不发送 Origin，不受限制
+func CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range config.LoadedConfig.Realtime.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Serve 完成 WebSocket 握手并处理连接直到断开，调用方需先完成鉴权和连接数检查
+func Serve(w http.ResponseWriter, r *http.Request, userID uint) {
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !CheckOrigin(r) {
+				return errOriginNotAllowed
+			}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			client := newClient(defaultHub, conn, userID)
+			defaultHub.register(client)
+			logger.GetLogger().Infof("WebSocket连接建立: user_id=%d", userID)
+
+			go client.writeLoop()
+			client.readLoop()
+
+			client.close()
+			client.cleanup()
+			logger.GetLogger().Infof("WebSocket连接断开: user_id=%d", userID)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
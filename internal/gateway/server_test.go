@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"CMS/config"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	prev := config.LoadedConfig
+	config.LoadedConfig = &config.Config{}
+	config.LoadedConfig.Realtime.AllowedOrigins = []string{"https://cms.example.com/"}
+	t.Cleanup(func() { config.LoadedConfig = prev })
+
+	cases := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},                       // 非浏览器客户端
+		{"http://api.example.com", true}, // 同源
+		{"https://cms.example.com", true},
+		{"HTTPS://CMS.EXAMPLE.COM", true},
+		{"https://evil.example.com", false},
+		{"https://cms.example.com.evil.com", false},
+		{"http://cms.example.com", false}, // 协议不同
+		{"null", false},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "http://api.example.com/api/ws", nil)
+		if c.origin != "" {
+			r.Header.Set("Origin", c.origin)
+		}
+		if got := CheckOrigin(r); got != c.want {
+			t.Errorf("CheckOrigin(%q) = %v, want %v", c.origin, got, c.want)
+		}
+	}
+}
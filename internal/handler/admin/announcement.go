@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAnnouncementData struct {
+	Content         string     `json:"content" binding:"required"`
+	BoardID         uint       `json:"board_id"`         // 0表示全站公告（同时发送通知）
+	PinnedUntil     *time.Time `json:"pinned_until"`     // 置顶截止时间（RFC3339），为空不置顶
+	MustAcknowledge bool       `json:"must_acknowledge"` // 是否要求用户确认已读
+}
+
+type PinAnnouncementData struct {
+	PostID      uint       `json:"post_id" binding:"required"`
+	PinnedUntil *time.Time `json:"pinned_until"` // 为空表示取消置顶
+}
+
+// CreateAnnouncement 管理员发布公告
+// POST /api/admin/announcement
+func CreateAnnouncement(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data CreateAnnouncementData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("发布公告参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	post, serviceErr := services.CreateAnnouncement(userID, data.Content, data.BoardID, data.PinnedUntil, data.MustAcknowledge)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("发布公告失败: admin_user_id=%d, board_id=%d, error=%v", userID, data.BoardID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员发布公告成功: admin_user_id=%d, post_id=%d, board_id=%d", userID, post.ID, data.BoardID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post": post.ToResponse(),
+	})
+}
+
+// PinAnnouncement 管理员设置或取消公告置顶
+// PUT /api/admin/announcement/pin
+func PinAnnouncement(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data PinAnnouncementData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("设置公告置顶参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.SetAnnouncementPin(data.PostID, data.PinnedUntil); serviceErr != nil {
+		logger.GetLogger().Errorf("设置公告置顶失败: admin_user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员设置公告置顶成功: admin_user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// GetAnnouncementUnread 管理员查看尚未确认公告的用户
+// GET /api/admin/announcement/unread?post_id=1&page=1&page_size=20
+func GetAnnouncementUnread(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Query("post_id"), 10, 64)
+	if err != nil || postID == 0 {
+		logger.GetLogger().Errorf("查看公告未读用户参数错误: post_id=%s", c.Query("post_id"))
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的post_id参数"})
+		c.Abort()
+		return
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	list, unreadCount, readCount, serviceErr := services.GetAnnouncementUnreadUsers(uint(postID), page, pageSize)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("查看公告未读用户失败: post_id=%d, error=%v", postID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_list":    list,
+		"unread_count": unreadCount,
+		"read_count":   readCount,
+		"page":         page,
+		"page_size":    pageSize,
+	})
+}
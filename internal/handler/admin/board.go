@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateBoardData struct {
+	Name           string `json:"name" binding:"required"`
+	Description    string `json:"description"`
+	AllowAnonymous bool   `json:"allow_anonymous"` // 是否允许匿名发帖
+}
+
+type SetBoardAnonymousData struct {
+	BoardID        uint `json:"board_id" binding:"required"`
+	AllowAnonymous bool `json:"allow_anonymous"`
+}
+
+// CreateBoard 管理员创建版块
+// POST /api/admin/board
+func CreateBoard(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data CreateBoardData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("创建版块参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	board, serviceErr := services.CreateBoard(data.Name, data.Description, data.AllowAnonymous)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建版块失败: admin_user_id=%d, name=%s, error=%v", userID, data.Name, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员创建版块成功: admin_user_id=%d, board_id=%d", userID, board.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"board": board,
+	})
+}
+
+// SetBoardAnonymous 设置版块是否允许匿名发帖
+// PUT /api/admin/board/anonymous
+func SetBoardAnonymous(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data SetBoardAnonymousData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("设置版块匿名参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.SetBoardAnonymous(data.BoardID, data.AllowAnonymous); serviceErr != nil {
+		logger.GetLogger().Errorf("设置版块匿名失败: admin_user_id=%d, board_id=%d, error=%v", userID, data.BoardID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员设置版块匿名成功: admin_user_id=%d, board_id=%d, allow=%v", userID, data.BoardID, data.AllowAnonymous)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClearLockoutData struct {
+	Scope string `json:"scope" binding:"required"` // user 或 ip
+	Value string `json:"value" binding:"required"` // 账号或 IP
+}
+
+// GetLoginLockouts 查看当前因登录失败过多而被锁定的账号和 IP
+// GET /api/admin/login/lockout
+func GetLoginLockouts(c *gin.Context) {
+	lockouts, err := services.GetLoginLockouts()
+	if err != nil {
+		logger.GetLogger().Errorf("获取登录锁定列表失败: %v", err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取登录锁定列表失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"lockout_list": lockouts,
+	})
+}
+
+// ClearLoginLockout 解除账号或 IP 的登录锁定
+// DELETE /api/admin/login/lockout
+func ClearLoginLockout(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+
+	var data ClearLockoutData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("解除登录锁定参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.ClearLoginLockout(adminID, data.Scope, data.Value); serviceErr != nil {
+		logger.GetLogger().Errorf("解除登录锁定失败: admin_user_id=%d, scope=%s, value=%s, error=%v", adminID, data.Scope, data.Value, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员解除登录锁定: admin_user_id=%d, scope=%s, value=%s", adminID, data.Scope, data.Value)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
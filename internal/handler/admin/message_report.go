@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApproveMessageReportData 审批私信举报的数据结构
+type ApproveMessageReportData struct {
+	MessageID uint `json:"message_id" binding:"required"`
+	Approval  int  `json:"approval" binding:"required"` // 1代表同意（删除私信），2代表拒绝
+}
+
+// ApproveMessageReport 管理员审批被举报的私信
+// POST /api/admin/report/message
+func ApproveMessageReport(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data ApproveMessageReportData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("审批私信举报参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if data.Approval != 1 && data.Approval != 2 {
+		logger.GetLogger().Errorf("审批私信举报参数错误: 无效的approval值: %d", data.Approval)
+		c.Error(&models.ServiceError{Code: 400, Message: "审批状态参数错误"})
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.ProcessMessageReportApproval(data.MessageID, data.Approval, userID); serviceErr != nil {
+		logger.GetLogger().Errorf("审批私信举报失败: message_id=%d, approval=%d, error=%v", data.MessageID, data.Approval, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员审批私信举报成功: admin_user_id=%d, message_id=%d, approval=%d", userID, data.MessageID, data.Approval)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// GetReportedConversation 管理员查看被举报私信所在的会话（仅限举报待处理的会话，访问会记录审计日志）
+// GET /api/admin/report/conversation?message_id=1&page=1&page_size=20
+func GetReportedConversation(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	messageID, err := strconv.ParseUint(c.Query("message_id"), 10, 64)
+	if err != nil || messageID == 0 {
+		logger.GetLogger().Errorf("查看被举报会话参数错误: message_id=%s", c.Query("message_id"))
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的message_id参数"})
+		c.Abort()
+		return
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	list, total, serviceErr := services.GetReportedConversation(userID, uint(messageID), page, pageSize)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("查看被举报会话失败: admin_user_id=%d, message_id=%d, error=%v", userID, messageID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员查看被举报会话: admin_user_id=%d, message_id=%d", userID, messageID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"message_list": list,
+		"total":        total,
+		"page":         page,
+		"page_size":    pageSize,
+	})
+}
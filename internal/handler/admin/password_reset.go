@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IssuePasswordResetData struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// IssuePasswordReset 为用户签发一次性密码重置凭证，凭证只返回这一次，由管理员当面交给用户
+// POST /api/admin/user/password-reset
+func IssuePasswordReset(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+
+	var data IssuePasswordResetData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("签发重置凭证参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	token, expiresAt, serviceErr := services.IssuePasswordReset(adminID, data.UserID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("签发重置凭证失败: admin_user_id=%d, user_id=%d, error=%v", adminID, data.UserID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员签发密码重置凭证: admin_user_id=%d, user_id=%d", adminID, data.UserID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"token":      token,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
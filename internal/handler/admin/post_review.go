@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewPostData struct {
+	PostID   uint `json:"post_id" binding:"required"`
+	Approval int  `json:"approval" binding:"required"` // 1代表发布，2代表删除
+}
+
+// GetPendingPosts 获取先审后发的待审核帖子
+// GET /api/admin/post/pending?page=1&page_size=20
+func GetPendingPosts(c *gin.Context) {
+	page, pageSize := utils.GetPagination(c)
+	list, total, err := services.GetPendingPosts(page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取待审核帖子失败: %v", err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取待审核帖子失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post_list": list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// ReviewPost 审核待发布的帖子
+// POST /api/admin/post/review
+func ReviewPost(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data ReviewPostData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("审核帖子参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	if data.Approval != 1 && data.Approval != 2 {
+		c.Error(&models.ServiceError{Code: 400, Message: "审批状态参数错误"})
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.ReviewPendingPost(userID, data.PostID, data.Approval); serviceErr != nil {
+		logger.GetLogger().Errorf("审核帖子失败: admin_user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员审核帖子: admin_user_id=%d, post_id=%d, approval=%d", userID, data.PostID, data.Approval)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevealAuthorData struct {
+	PostID uint   `json:"post_id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RevealAnonymousAuthor 查看匿名帖子的真实作者，需填写理由，操作记入审计日志
+// POST /api/admin/post/reveal
+func RevealAnonymousAuthor(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data RevealAuthorData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("查看匿名作者参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	author, serviceErr := services.RevealAnonymousAuthor(userID, data.PostID, data.Reason)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("查看匿名作者失败: admin_user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员查看匿名作者: admin_user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_id":  author.ID,
+		"username": author.Username,
+		"name":     author.Name,
+	})
+}
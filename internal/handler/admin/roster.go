@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportRoster 批量导入学生名单（CSV 或 XLSX），dry_run=true 时只预览不写入；
+// 正式导入后返回结果文件下载ID，结果文件含新账号的初始密码，只能下载一次
+// POST /api/admin/user/import  (multipart/form-data, 字段名 file、dry_run)
+func ImportRoster(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.RosterMaxFileBytes+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.GetLogger().Errorf("导入名单参数错误: admin_user_id=%d, error=%v", adminID, err)
+		c.Error(&models.ServiceError{Code: 400, Message: "参数错误或文件过大"})
+		c.Abort()
+		return
+	}
+	if fileHeader.Size > services.RosterMaxFileBytes {
+		c.Error(&models.ServiceError{Code: 400, Message: "名单文件过大"})
+		c.Abort()
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(&models.ServiceError{Code: 400, Message: "读取名单文件失败"})
+		c.Abort()
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(&models.ServiceError{Code: 400, Message: "读取名单文件失败"})
+		c.Abort()
+		return
+	}
+
+	rows, invalid, serviceErr := services.ParseRoster(fileHeader.Filename, data)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("解析名单失败: admin_user_id=%d, file=%s, error=%v", adminID, fileHeader.Filename, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+	result, serviceErr := services.ImportRoster(adminID, rows, invalid, dryRun, services.RosterDefaultBatch)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("导入名单失败: admin_user_id=%d, error=%v", adminID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	response := gin.H{"result": result}
+	if !dryRun {
+		fileID, err := services.SaveRosterResultFile(result)
+		if err != nil {
+			// 结果文件保存失败时初始密码无法找回，需要管理员为这些账号签发密码重置凭证
+			logger.GetLogger().Errorf("保存名单导入结果文件失败: admin_user_id=%d, error=%v", adminID, err)
+		} else {
+			response["result_file_id"] = fileID
+		}
+	}
+
+	logger.GetLogger().Infof("管理员导入名单: admin_user_id=%d, dry_run=%t, total=%d, created=%d, updated=%d, failed=%d",
+		adminID, dryRun, result.Total, result.Created, result.Updated, result.Failed)
+	utils.JsonSuccessWithCode(c, 200, response)
+}
+
+// DownloadRosterResult 下载名单导入结果文件（CSV），下载一次后失效
+// GET /api/admin/user/import/result?id=xxx
+func DownloadRosterResult(c *gin.Context) {
+	data, ok := services.TakeRosterResultFile(c.Query("id"))
+	if !ok {
+		c.Error(&models.ServiceError{Code: 404, Message: "结果文件不存在或已下载"})
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员下载名单导入结果: admin_user_id=%d", middleware.GetUserIDFromContext(c))
+	c.Header("Content-Disposition", `attachment; filename="roster-import-result.csv"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateServiceAccountData struct {
+	Username string `json:"username" binding:"required"`
+	Name     string `json:"name"`
+	UserType int    `json:"user_type"` // 1-学生（默认）, 2-管理员
+}
+
+type CreateServiceAccountTokenData struct {
+	UserID        uint     `json:"user_id" binding:"required"`
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"` // 有效期（天），0 表示永不过期
+}
+
+// CreateServiceAccount 创建服务账号
+// POST /api/admin/service-account
+func CreateServiceAccount(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+
+	var data CreateServiceAccountData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("创建服务账号参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	if data.UserType == 0 {
+		data.UserType = models.StudentRole
+	}
+
+	account, serviceErr := services.CreateServiceAccount(adminID, data.Username, data.Name, data.UserType)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建服务账号失败: admin_user_id=%d, username=%s, error=%v", adminID, data.Username, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员创建服务账号: admin_user_id=%d, user_id=%d", adminID, account.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"service_account": account,
+	})
+}
+
+// GetServiceAccounts 获取服务账号列表
+// GET /api/admin/service-account
+func GetServiceAccounts(c *gin.Context) {
+	accounts, err := services.GetServiceAccounts()
+	if err != nil {
+		logger.GetLogger().Errorf("获取服务账号列表失败: error=%v", err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取服务账号列表失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"service_account_list": accounts,
+	})
+}
+
+// CreateServiceAccountToken 为服务账号创建访问令牌，令牌明文只返回这一次
+// POST /api/admin/service-account/token
+func CreateServiceAccountToken(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+
+	var data CreateServiceAccountTokenData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("创建服务账号令牌参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	if _, serviceErr := services.GetServiceAccount(data.UserID); serviceErr != nil {
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	raw, token, serviceErr := services.CreateAPIToken(adminID, data.UserID, data.Name, data.Scopes, data.ExpiresInDays)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建服务账号令牌失败: admin_user_id=%d, user_id=%d, error=%v", adminID, data.UserID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员创建服务账号令牌: admin_user_id=%d, user_id=%d, token_id=%d", adminID, data.UserID, token.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"api_token": token.ToResponse(),
+		"token":     raw,
+	})
+}
+
+// GetServiceAccountTokens 获取服务账号的访问令牌列表
+// GET /api/admin/service-account/token?user_id=xxx
+func GetServiceAccountTokens(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的user_id参数"})
+		c.Abort()
+		return
+	}
+	if _, serviceErr := services.GetServiceAccount(uint(userID)); serviceErr != nil {
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	list, err := services.GetAPITokens(uint(userID))
+	if err != nil {
+		logger.GetLogger().Errorf("获取服务账号令牌失败: user_id=%d, error=%v", userID, err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取访问令牌列表失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"api_token_list": list,
+	})
+}
+
+// RevokeServiceAccountToken 撤销服务账号的访问令牌
+// DELETE /api/admin/service-account/token?user_id=xxx&token_id=xxx
+func RevokeServiceAccountToken(c *gin.Context) {
+	adminID := middleware.GetUserIDFromContext(c)
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的user_id参数"})
+		c.Abort()
+		return
+	}
+	tokenID, err := strconv.ParseUint(c.Query("token_id"), 10, 64)
+	if err != nil || tokenID == 0 {
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的token_id参数"})
+		c.Abort()
+		return
+	}
+	if _, serviceErr := services.GetServiceAccount(uint(userID)); serviceErr != nil {
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.RevokeAPIToken(adminID, uint(userID), uint(tokenID)); serviceErr != nil {
+		logger.GetLogger().Errorf("撤销服务账号令牌失败: admin_user_id=%d, token_id=%d, error=%v", adminID, tokenID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员撤销服务账号令牌: admin_user_id=%d, user_id=%d, token_id=%d", adminID, userID, tokenID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateWebhookData struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Secret string   `json:"secret"` // 为空时自动生成
+}
+
+type UpdateWebhookData struct {
+	WebhookID uint     `json:"webhook_id" binding:"required"`
+	URL       string   `json:"url" binding:"required"`
+	Events    []string `json:"events" binding:"required"`
+	Secret    string   `json:"secret"` // 为空时保持不变
+	Enabled   bool     `json:"enabled"`
+}
+
+type ReplayDeliveryData struct {
+	DeliveryID uint `json:"delivery_id" binding:"required"`
+}
+
+// CreateWebhook 管理员创建 Webhook 订阅
+// POST /api/admin/webhook
+func CreateWebhook(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data CreateWebhookData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("创建Webhook参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	webhook, serviceErr := services.CreateWebhook(userID, data.URL, data.Events, data.Secret)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建Webhook失败: admin_user_id=%d, url=%s, error=%v", userID, data.URL, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员创建Webhook成功: admin_user_id=%d, webhook_id=%d", userID, webhook.ID)
+	// secret 仅在创建时返回一次
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"webhook": webhook.ToResponse(),
+		"secret":  webhook.Secret,
+	})
+}
+
+// GetWebhooks 管理员获取 Webhook 订阅列表
+// GET /api/admin/webhook
+func GetWebhooks(c *gin.Context) {
+	list, err := services.GetWebhooks()
+	if err != nil {
+		logger.GetLogger().Errorf("获取Webhook列表失败: error=%v", err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取Webhook列表失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"webhook_list": list,
+	})
+}
+
+// UpdateWebhook 管理员修改 Webhook 订阅
+// PUT /api/admin/webhook
+func UpdateWebhook(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data UpdateWebhookData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改Webhook参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.UpdateWebhook(data.WebhookID, data.URL, data.Events, data.Secret, data.Enabled); serviceErr != nil {
+		logger.GetLogger().Errorf("修改Webhook失败: admin_user_id=%d, webhook_id=%d, error=%v", userID, data.WebhookID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员修改Webhook成功: admin_user_id=%d, webhook_id=%d", userID, data.WebhookID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// DeleteWebhook 管理员删除 Webhook 订阅
+// DELETE /api/admin/webhook?webhook_id=1
+func DeleteWebhook(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	webhookID, err := strconv.ParseUint(c.Query("webhook_id"), 10, 64)
+	if err != nil || webhookID == 0 {
+		logger.GetLogger().Errorf("删除Webhook参数错误: webhook_id=%s", c.Query("webhook_id"))
+		c.Error(&models.ServiceError{Code: 400, Message: "无效的webhook_id参数"})
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.DeleteWebhook(uint(webhookID)); serviceErr != nil {
+		logger.GetLogger().Errorf("删除Webhook失败: admin_user_id=%d, webhook_id=%d, error=%v", userID, webhookID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员删除Webhook成功: admin_user_id=%d, webhook_id=%d", userID, webhookID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// GetDeadDeliveries 管理员查看死信投递
+// GET /api/admin/webhook/dead?page=1&page_size=20
+func GetDeadDeliveries(c *gin.Context) {
+	page, pageSize := utils.GetPagination(c)
+
+	list, total, err := services.GetDeadWebhookDeliveries(page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取死信投递失败: error=%v", err)
+		c.Error(&models.ServiceError{Code: 1001, Message: "获取死信投递失败"})
+		c.Abort()
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"delivery_list": list,
+		"total":         total,
+		"page":          page,
+		"page_size":     pageSize,
+	})
+}
+
+// ReplayDelivery 管理员重放死信投递
+// POST /api/admin/webhook/replay
+func ReplayDelivery(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+
+	var data ReplayDeliveryData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("重放投递参数错误: %v", err)
+		c.Error(err)
+		c.Abort()
+		return
+	}
+
+	if serviceErr := services.ReplayWebhookDelivery(data.DeliveryID); serviceErr != nil {
+		logger.GetLogger().Errorf("重放投递失败: admin_user_id=%d, delivery_id=%d, error=%v", userID, data.DeliveryID, serviceErr)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
+
+	logger.GetLogger().Infof("管理员重放投递成功: admin_user_id=%d, delivery_id=%d", userID, data.DeliveryID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,54 @@
+package attachment
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve 通过签名链接下载附件，无需登录，签名和有效期由服务端校验
+// GET /api/file/:id?expires=&sig=&variant=
+func Serve(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+	variant := c.Query("variant")
+
+	reader, attachment, serviceErr := services.OpenAttachment(uint(id), variant, expires, c.Query("sig"))
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("下载附件失败: attachment_id=%d, error=%v", id, serviceErr)
+		utils.JsonResponse(c, http.StatusForbidden, serviceErr.Code, serviceErr.Message, nil)
+		return
+	}
+	defer reader.Close()
+
+	contentType := attachment.ContentType
+	disposition := "attachment"
+	if variant == services.AttachmentVariantThumbnail {
+		contentType = "image/jpeg"
+	}
+	if attachment.IsImage() {
+		disposition = "inline"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=600")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		logger.GetLogger().Errorf("输出附件失败: attachment_id=%d, error=%v", id, err)
+	}
+}
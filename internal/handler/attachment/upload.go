@@ -0,0 +1,45 @@
+package attachment
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Upload 上传附件，返回附件ID供发帖或保存草稿时关联
+// POST /api/student/attachment  (multipart/form-data, 字段名 file)
+func Upload(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("上传附件失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	isAdmin, _ := services.CheckUserIsAdmin(userID)
+	// 限制整个请求体大小，预留 1MB 给表单其他部分
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.UploadLimit(isAdmin)+1<<20)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		logger.GetLogger().Errorf("上传附件参数错误: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "参数错误或文件过大")
+		return
+	}
+
+	attachment, serviceErr := services.UploadAttachment(userID, isAdmin, file)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("上传附件失败: user_id=%d, file=%s, error=%v", userID, file.Filename, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户上传附件成功: user_id=%d, attachment_id=%d, type=%s", userID, attachment.ID, attachment.ContentType)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"attachment": services.AttachmentToResponse(*attachment),
+	})
+}
@@ -32,16 +32,17 @@ func ReportPost(c *gin.Context) {
 		return
 	}
 
-	_, err = services.GetPostByID(data.PostID)
-	if err != nil {
+	post, err := services.GetPostByID(data.PostID)
+	if err != nil || post.Status != models.PostStatusPublished {
 		logger.GetLogger().Errorf("举报帖子失败: 帖子不存在 post_id=%d, error=%v", data.PostID, err)
 		utils.JsonErrorWithCode(c, 1003, "帖子不存在")
 		return
 	}
 	block := models.Block{
-		UserID:   userID,
-		TargetID: data.PostID,
-		Reason:   data.Reason,
+		UserID:       userID,
+		TargetID:     data.PostID,
+		TargetUserID: post.UserID,
+		Reason:       data.Reason,
 	}
 	err = services.CreateBlock(block)
 	if err != nil {
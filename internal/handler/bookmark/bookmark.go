@@ -0,0 +1,135 @@
+package bookmark
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkData struct {
+	PostID   uint `json:"post_id" binding:"required"`
+	FolderID uint `json:"folder_id"` // 可选，0表示未分类
+}
+
+type RemoveBookmarkData struct {
+	PostID uint `json:"post_id" binding:"required"`
+}
+
+// AddBookmark 收藏帖子
+// POST /api/student/bookmark
+func AddBookmark(c *gin.Context) {
+	var data BookmarkData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("收藏帖子参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("收藏帖子失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.AddBookmark(userID, data.PostID, data.FolderID); serviceErr != nil {
+		logger.GetLogger().Errorf("收藏帖子失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// RemoveBookmark 取消收藏
+// DELETE /api/student/bookmark
+func RemoveBookmark(c *gin.Context) {
+	var data RemoveBookmarkData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("取消收藏参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("取消收藏失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.RemoveBookmark(userID, data.PostID); serviceErr != nil {
+		logger.GetLogger().Errorf("取消收藏失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// MoveBookmark 将收藏移动到其他收藏夹
+// PUT /api/student/bookmark/move
+func MoveBookmark(c *gin.Context) {
+	var data BookmarkData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("移动收藏参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("移动收藏失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.MoveBookmark(userID, data.PostID, data.FolderID); serviceErr != nil {
+		logger.GetLogger().Errorf("移动收藏失败: user_id=%d, post_id=%d, folder_id=%d, error=%v", userID, data.PostID, data.FolderID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// GetBookmarks 分页获取收藏列表，不传 folder_id 时返回全部收藏
+// GET /api/student/bookmark?folder_id=1&page=1&page_size=20
+func GetBookmarks(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取收藏列表失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	var folderID *uint
+	if raw, ok := c.GetQuery("folder_id"); ok {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.JsonErrorWithCode(c, 1002, "无效的folder_id参数")
+			return
+		}
+		folder := uint(id)
+		folderID = &folder
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	list, total, err := services.GetBookmarks(userID, folderID, page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取收藏列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1003, "获取收藏列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"bookmark_list": list,
+		"total":         total,
+		"page":          page,
+		"page_size":     pageSize,
+	})
+}
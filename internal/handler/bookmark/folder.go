@@ -0,0 +1,126 @@
+package bookmark
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateFolderData struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type RenameFolderData struct {
+	FolderID uint   `json:"folder_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+}
+
+type DeleteFolderData struct {
+	FolderID uint `json:"folder_id" binding:"required"`
+}
+
+// GetFolders 获取收藏夹列表
+// GET /api/student/bookmark/folder
+func GetFolders(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取收藏夹失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	folders, err := services.GetBookmarkFolders(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取收藏夹失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取收藏夹失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"folder_list": folders,
+	})
+}
+
+// CreateFolder 创建收藏夹
+// POST /api/student/bookmark/folder
+func CreateFolder(c *gin.Context) {
+	var data CreateFolderData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("创建收藏夹参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("创建收藏夹失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	folder, serviceErr := services.CreateBookmarkFolder(userID, data.Name)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建收藏夹失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"folder": folder,
+	})
+}
+
+// RenameFolder 重命名收藏夹
+// PUT /api/student/bookmark/folder
+func RenameFolder(c *gin.Context) {
+	var data RenameFolderData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("重命名收藏夹参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("重命名收藏夹失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.RenameBookmarkFolder(userID, data.FolderID, data.Name); serviceErr != nil {
+		logger.GetLogger().Errorf("重命名收藏夹失败: user_id=%d, folder_id=%d, error=%v", userID, data.FolderID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// DeleteFolder 删除收藏夹，其中的收藏移到未分类
+// DELETE /api/student/bookmark/folder
+func DeleteFolder(c *gin.Context) {
+	var data DeleteFolderData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("删除收藏夹参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("删除收藏夹失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.DeleteBookmarkFolder(userID, data.FolderID); serviceErr != nil {
+		logger.GetLogger().Errorf("删除收藏夹失败: user_id=%d, folder_id=%d, error=%v", userID, data.FolderID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,144 @@
+package follow
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowData struct {
+	TargetType int  `json:"target_type"` // 0-用户, 1-版块
+	TargetID   uint `json:"target_id" binding:"required"`
+}
+
+// Follow 关注用户或版块
+// POST /api/student/follow
+func Follow(c *gin.Context) {
+	var data FollowData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("关注参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("关注失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.Follow(userID, data.TargetType, data.TargetID); serviceErr != nil {
+		logger.GetLogger().Errorf("关注失败: user_id=%d, target_type=%d, target_id=%d, error=%v", userID, data.TargetType, data.TargetID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户关注成功: user_id=%d, target_type=%d, target_id=%d", userID, data.TargetType, data.TargetID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// Unfollow 取消关注用户或版块
+// DELETE /api/student/follow
+func Unfollow(c *gin.Context) {
+	var data FollowData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("取消关注参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("取消关注失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.Unfollow(userID, data.TargetType, data.TargetID); serviceErr != nil {
+		logger.GetLogger().Errorf("取消关注失败: user_id=%d, target_type=%d, target_id=%d, error=%v", userID, data.TargetType, data.TargetID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户取消关注成功: user_id=%d, target_type=%d, target_id=%d", userID, data.TargetType, data.TargetID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// queryUserID 读取 user_id 查询参数，未提供时为当前用户
+func queryUserID(c *gin.Context) uint {
+	if id, err := strconv.ParseUint(c.Query("user_id"), 10, 64); err == nil && id != 0 {
+		return uint(id)
+	}
+	return middleware.GetUserIDFromContext(c)
+}
+
+// GetFollowers 获取粉丝列表
+// GET /api/student/follow/followers?user_id=1&page=1&page_size=20
+func GetFollowers(c *gin.Context) {
+	userID := queryUserID(c)
+	page, pageSize := utils.GetPagination(c)
+
+	list, total, err := services.GetFollowers(userID, page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取粉丝列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "获取粉丝列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_list": list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetFollowing 获取关注的用户列表和版块列表
+// GET /api/student/follow/following?user_id=1&page=1&page_size=20
+func GetFollowing(c *gin.Context) {
+	userID := queryUserID(c)
+	page, pageSize := utils.GetPagination(c)
+
+	list, total, err := services.GetFollowingUsers(userID, page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取关注列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "获取关注列表失败")
+		return
+	}
+	boards, err := services.GetFollowingBoards(userID)
+	if err != nil {
+		utils.JsonErrorWithCode(c, 1001, "获取关注列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_list":  list,
+		"board_list": boards,
+		"total":      total,
+		"page":       page,
+		"page_size":  pageSize,
+	})
+}
+
+// GetFollowCounts 获取粉丝数和关注数
+// GET /api/student/follow/count?user_id=1
+func GetFollowCounts(c *gin.Context) {
+	userID := queryUserID(c)
+	followers, followingUsers, followingBoards := services.GetFollowCounts(userID)
+
+	data := gin.H{
+		"follower_count":        followers,
+		"following_count":       followingUsers,
+		"following_board_count": followingBoards,
+	}
+	if current := middleware.GetUserIDFromContext(c); current != userID {
+		data["is_following"] = services.IsFollowing(current, models.FollowTargetUser, userID)
+	}
+	utils.JsonSuccessWithCode(c, 200, data)
+}
@@ -0,0 +1,71 @@
+package message
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConversations 获取当前用户的会话列表
+// GET /api/student/conversation?page=1&page_size=20
+func GetConversations(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取会话列表失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	list, total, err := services.GetConversations(userID, page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取会话列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取会话列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"conversation_list": list,
+		"total":             total,
+		"page":              page,
+		"page_size":         pageSize,
+	})
+}
+
+// GetMessages 分页获取会话的消息记录
+// GET /api/student/conversation/message?conversation_id=1&page=1&page_size=20
+func GetMessages(c *gin.Context) {
+	conversationID, err := strconv.ParseUint(c.Query("conversation_id"), 10, 64)
+	if err != nil || conversationID == 0 {
+		logger.GetLogger().Errorf("获取私信记录参数错误: conversation_id=%s", c.Query("conversation_id"))
+		utils.JsonErrorWithCode(c, 1001, "无效的conversation_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取私信记录失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	list, total, peerLastRead, serviceErr := services.GetMessages(uint(conversationID), userID, page, pageSize)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取私信记录失败: user_id=%d, conversation_id=%d, error=%v", userID, conversationID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"message_list":   list,
+		"peer_last_read": peerLastRead,
+		"total":          total,
+		"page":           page,
+		"page_size":      pageSize,
+	})
+}
@@ -0,0 +1,40 @@
+package message
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MarkReadData struct {
+	ConversationID uint `json:"conversation_id" binding:"required"`
+}
+
+// MarkRead 将会话标记为已读，并向对方发送已读回执
+// PUT /api/student/conversation/read
+func MarkRead(c *gin.Context) {
+	var data MarkReadData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("标记会话已读参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("标记会话已读失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.MarkConversationRead(data.ConversationID, userID); serviceErr != nil {
+		logger.GetLogger().Errorf("标记会话已读失败: user_id=%d, conversation_id=%d, error=%v", userID, data.ConversationID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,42 @@
+package message
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportMessageData struct {
+	MessageID uint   `json:"message_id" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// ReportMessage 举报私信，进入管理员举报审核队列
+// POST /api/student/report-message
+func ReportMessage(c *gin.Context) {
+	var data ReportMessageData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("举报私信参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("举报私信失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.ReportMessage(userID, data.MessageID, data.Reason); serviceErr != nil {
+		logger.GetLogger().Errorf("举报私信失败: user_id=%d, message_id=%d, error=%v", userID, data.MessageID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户举报私信成功: user_id=%d, message_id=%d", userID, data.MessageID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,45 @@
+package message
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SendMessageData struct {
+	ReceiverID uint   `json:"receiver_id" binding:"required"`
+	Content    string `json:"content" binding:"required"`
+}
+
+// SendMessage 发送私信
+// POST /api/student/message
+func SendMessage(c *gin.Context) {
+	var data SendMessageData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("发送私信参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("发送私信失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	message, serviceErr := services.SendMessage(userID, data.ReceiverID, data.Content)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("发送私信失败: sender_id=%d, receiver_id=%d, error=%v", userID, data.ReceiverID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户发送私信成功: sender_id=%d, receiver_id=%d, message_id=%d", userID, data.ReceiverID, message.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"message": message,
+	})
+}
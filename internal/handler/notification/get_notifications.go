@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications 分页获取当前用户的通知列表
+// GET /api/student/notification?page=1&page_size=20&unread=true
+func GetNotifications(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取通知列表失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	page, pageSize := utils.GetPagination(c)
+	unreadOnly := c.Query("unread") == "true" || c.Query("unread") == "1"
+
+	list, total, err := services.GetNotifications(userID, unreadOnly, page, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取通知列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取通知列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"notification_list": list,
+		"total":             total,
+		"page":              page,
+		"page_size":         pageSize,
+	})
+}
+
+// GetUnreadCount 获取当前用户的未读通知数
+// GET /api/student/notification/unread-count
+func GetUnreadCount(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取未读通知数失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	count, err := services.GetUnreadNotificationCount(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取未读通知数失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取未读通知数失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"unread_count": count,
+	})
+}
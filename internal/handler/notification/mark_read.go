@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MarkReadData struct {
+	NotificationID uint `json:"notification_id" binding:"required"`
+}
+
+// MarkRead 将单条通知标记为已读
+// PUT /api/student/notification/read
+func MarkRead(c *gin.Context) {
+	var data MarkReadData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("标记通知已读参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("标记通知已读失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.MarkNotificationRead(userID, data.NotificationID); serviceErr != nil {
+		logger.GetLogger().Errorf("标记通知已读失败: user_id=%d, notification_id=%d, error=%v", userID, data.NotificationID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// MarkAllRead 将当前用户的所有通知标记为已读
+// PUT /api/student/notification/read-all
+func MarkAllRead(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("全部标记已读失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	if err := services.MarkAllNotificationsRead(userID); err != nil {
+		logger.GetLogger().Errorf("全部标记已读失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "操作失败")
+		return
+	}
+
+	logger.GetLogger().Infof("用户全部标记已读成功: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
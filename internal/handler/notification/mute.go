@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MuteData struct {
+	Type  string `json:"type" binding:"required"` // like / moderation / announcement
+	Muted bool   `json:"muted"`
+}
+
+// GetMuteSettings 获取当前用户屏蔽的通知类型
+// GET /api/student/notification/mute
+func GetMuteSettings(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取通知设置失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	types, err := services.GetMutedNotificationTypes(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取通知设置失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取通知设置失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"muted_types": types,
+	})
+}
+
+// SetMute 屏蔽或取消屏蔽某类通知
+// PUT /api/student/notification/mute
+func SetMute(c *gin.Context) {
+	var data MuteData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改通知设置参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("修改通知设置失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.SetNotificationMuted(userID, data.Type, data.Muted); serviceErr != nil {
+		logger.GetLogger().Errorf("修改通知设置失败: user_id=%d, type=%s, error=%v", userID, data.Type, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户修改通知设置成功: user_id=%d, type=%s, muted=%t", userID, data.Type, data.Muted)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,41 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AcknowledgeData struct {
+	PostID uint `json:"post_id" binding:"required"`
+}
+
+// AcknowledgeAnnouncement 用户确认已读公告
+// POST /api/student/announcement/ack
+func AcknowledgeAnnouncement(c *gin.Context) {
+	var data AcknowledgeData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("确认公告参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("确认公告失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.AcknowledgeAnnouncement(data.PostID, userID); serviceErr != nil {
+		logger.GetLogger().Errorf("确认公告失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户确认公告成功: user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
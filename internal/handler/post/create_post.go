@@ -2,9 +2,11 @@ package post
 
 import (
 	"CMS/internal/logger"
+	"CMS/internal/middleware"
 	"CMS/internal/models"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
+	"errors"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +14,12 @@ import (
 
 type CreatePostData struct {
 	Content string `json:"content" binding:"required"`
-	UserID  uint   `json:"user_id" binding:"required"`
+	BoardID uint   `json:"board_id"` // 可选，所属版块
+
+	AttachmentIDs []uint            `json:"attachment_ids"` // 可选，已上传的附件ID
+	Poll          *models.PollInput `json:"poll"`           // 可选，附带的投票
+	Anonymous     bool              `json:"anonymous"`      // 可选，匿名发布（版块需允许匿名）
+	IsQuestion    bool              `json:"is_question"`    // 可选，以提问形式发布
 }
 
 func CreatePost(c *gin.Context) {
@@ -24,19 +31,44 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
-	logger.GetLogger().Infof("用户尝试发布帖子: user_id=%d", data.UserID)
+	// 作者取自登录凭证，不接受请求体中的用户ID，否则可冒用他人身份发帖
+	userID := middleware.GetUserIDFromContext(c)
+	logger.GetLogger().Infof("用户尝试发布帖子: user_id=%d", userID)
 
-	err = services.CreatePost(models.Post{
-		Content:  data.Content,
-		UserID:   data.UserID,
-		PostTime: time.Now(),
-	})
+	if !services.BoardExists(data.BoardID) {
+		logger.GetLogger().Errorf("发布帖子失败，版块不存在: user_id=%d, board_id=%d", userID, data.BoardID)
+		utils.JsonErrorWithCode(c, 1003, "版块不存在")
+		return
+	}
+
+	postType := models.PostTypeNormal
+	if data.IsQuestion {
+		postType = models.PostTypeQuestion
+	}
+	newPost := &models.Post{
+		Content:     data.Content,
+		BoardID:     data.BoardID,
+		PostTime:    time.Now(),
+		IsAnonymous: data.Anonymous,
+		PostType:    postType,
+	}
+	err = services.CreatePost(userID, newPost, data.AttachmentIDs, data.Poll)
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		logger.GetLogger().Errorf("创建帖子失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
 	if err != nil {
-		logger.GetLogger().Errorf("创建帖子失败: user_id=%d, error=%v", data.UserID, err)
+		logger.GetLogger().Errorf("创建帖子失败: user_id=%d, error=%v", userID, err)
 		utils.JsonErrorWithCode(c, 1002, "创建失败")
 		return
 	}
 
-	logger.GetLogger().Infof("用户发布帖子成功: user_id=%d", data.UserID)
-	utils.JsonSuccessWithCode(c, 200, nil)
+	logger.GetLogger().Infof("用户发布帖子成功: user_id=%d, post_id=%d, status=%d", userID, newPost.ID, newPost.Status)
+	// status 为3表示需等待管理员审核后才会公开
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post_id": newPost.ID,
+		"status":  newPost.Status,
+	})
 }
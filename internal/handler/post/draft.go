@@ -0,0 +1,164 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SaveDraftData struct {
+	Content     string     `json:"content" binding:"required"`
+	BoardID     uint       `json:"board_id"`
+	ScheduledAt *time.Time `json:"scheduled_at"` // 定时发布时间（RFC3339），为空则保存为草稿
+
+	AttachmentIDs []uint `json:"attachment_ids"` // 可选，已上传的附件ID
+}
+
+type UpdateDraftData struct {
+	PostID      uint       `json:"post_id" binding:"required"`
+	Content     string     `json:"content" binding:"required"`
+	BoardID     uint       `json:"board_id"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+type PublishDraftData struct {
+	PostID uint `json:"post_id" binding:"required"`
+}
+
+// SaveDraft 保存草稿或定时发布帖子
+// POST /api/student/draft
+func SaveDraft(c *gin.Context) {
+	var data SaveDraftData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("保存草稿参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("保存草稿失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	post, serviceErr := services.SaveDraft(userID, data.Content, data.BoardID, data.ScheduledAt, data.AttachmentIDs)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("保存草稿失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户保存草稿成功: user_id=%d, post_id=%d, status=%d", userID, post.ID, post.Status)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post": post.ToResponse(),
+	})
+}
+
+// GetDrafts 获取当前用户的草稿和定时发布帖子
+// GET /api/student/draft
+func GetDrafts(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取草稿失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	list, err := services.GetDrafts(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取草稿失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"draft_list": list,
+	})
+}
+
+// UpdateDraft 修改草稿或定时发布帖子
+// PUT /api/student/draft
+func UpdateDraft(c *gin.Context) {
+	var data UpdateDraftData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改草稿参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("修改草稿失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.UpdateDraft(data.PostID, userID, data.Content, data.BoardID, data.ScheduledAt); serviceErr != nil {
+		logger.GetLogger().Errorf("修改草稿失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户修改草稿成功: user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// DeleteDraft 删除草稿或定时发布帖子
+// DELETE /api/student/draft?post_id=1
+func DeleteDraft(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Query("post_id"), 10, 64)
+	if err != nil || postID == 0 {
+		logger.GetLogger().Errorf("删除草稿参数错误: post_id=%s", c.Query("post_id"))
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("删除草稿失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.DeleteDraft(uint(postID), userID); serviceErr != nil {
+		logger.GetLogger().Errorf("删除草稿失败: user_id=%d, post_id=%d, error=%v", userID, postID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户删除草稿成功: user_id=%d, post_id=%d", userID, postID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// PublishDraft 立即发布草稿或定时发布帖子
+// POST /api/student/draft/publish
+func PublishDraft(c *gin.Context) {
+	var data PublishDraftData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("发布草稿参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("发布草稿失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.PublishDraft(data.PostID, userID); serviceErr != nil {
+		logger.GetLogger().Errorf("发布草稿失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户发布草稿成功: user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -1,9 +1,11 @@
 package post
 
 import (
+	"CMS/internal/middleware"
 	"CMS/internal/models"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,8 +14,20 @@ type GetAllPostsResponse struct {
 	Posts []models.Post `json:"posts"`
 }
 
+// GetAllPosts 获取帖子列表，可通过 board_id 参数筛选版块
 func GetAllPosts(c *gin.Context) {
-	postlist, err := services.GetAllPostsWithFormat()
+	var boardID uint
+	if boardIDStr := c.Query("board_id"); boardIDStr != "" {
+		id, err := strconv.ParseUint(boardIDStr, 10, 64)
+		if err != nil {
+			utils.JsonErrorWithCode(c, 1002, "无效的board_id参数")
+			return
+		}
+		boardID = uint(id)
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	postlist, err := services.GetAllPostsWithFormat(userID, boardID)
 	if err != nil {
 		utils.JsonErrorWithCode(c, 1001, "获取失败")
 		return
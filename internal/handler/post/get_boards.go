@@ -0,0 +1,23 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBoards 获取版块列表
+// GET /api/student/board
+func GetBoards(c *gin.Context) {
+	boards, err := services.GetBoards()
+	if err != nil {
+		logger.GetLogger().Errorf("获取版块列表失败: error=%v", err)
+		utils.JsonErrorWithCode(c, 1001, "获取失败")
+		return
+	}
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"board_list": boards,
+	})
+}
@@ -0,0 +1,37 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFeed 获取关注的用户和版块的动态，按发布时间倒序，使用游标分页
+// GET /api/student/feed?before=1723600000000_123&page_size=20（before 为上一页返回的 next_cursor）
+func GetFeed(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取关注动态失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	before := services.ParseFeedCursor(c.Query("before"))
+	_, pageSize := utils.GetPagination(c)
+
+	list, nextCursor, err := services.GetFeed(userID, before, pageSize)
+	if err != nil {
+		logger.GetLogger().Errorf("获取关注动态失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取关注动态失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post_list":   list,
+		"next_cursor": nextCursor, // 为空表示没有更多数据
+		"page_size":   pageSize,
+	})
+}
@@ -0,0 +1,77 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type VoteData struct {
+	PostID    uint   `json:"post_id" binding:"required"`
+	OptionIDs []uint `json:"option_ids" binding:"required"`
+}
+
+// Vote 参与帖子投票，单选投票 option_ids 只能有一个
+// POST /api/student/poll/vote
+func Vote(c *gin.Context) {
+	var data VoteData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("投票参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("投票失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.Vote(userID, data.PostID, data.OptionIDs); serviceErr != nil {
+		logger.GetLogger().Errorf("投票失败: user_id=%d, post_id=%d, error=%v", userID, data.PostID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	result, err := services.GetPollResult(data.PostID, userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取投票结果失败: post_id=%d, error=%v", data.PostID, err)
+	}
+	logger.GetLogger().Infof("用户投票成功: user_id=%d, post_id=%d", userID, data.PostID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"poll": result,
+	})
+}
+
+// GetPollResult 获取帖子的投票结果
+// GET /api/student/poll?post_id=1
+func GetPollResult(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Query("post_id"), 10, 64)
+	if err != nil || postID == 0 {
+		utils.JsonErrorWithCode(c, 1001, "无效的post_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	result, err := services.GetPollResult(uint(postID), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.JsonErrorWithCode(c, 1002, "该帖子没有投票")
+		return
+	}
+	if err != nil {
+		logger.GetLogger().Errorf("获取投票结果失败: post_id=%d, error=%v", postID, err)
+		utils.JsonErrorWithCode(c, 1003, "获取投票结果失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"poll": result,
+	})
+}
@@ -0,0 +1,145 @@
+package post
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAnswerData struct {
+	QuestionID    uint   `json:"question_id" binding:"required"`
+	Content       string `json:"content" binding:"required"`
+	AttachmentIDs []uint `json:"attachment_ids"`
+}
+
+type AcceptAnswerData struct {
+	QuestionID uint `json:"question_id" binding:"required"`
+	AnswerID   uint `json:"answer_id" binding:"required"`
+}
+
+// GetQuestions 获取提问列表
+// GET /api/student/question?board_id=1&filter=unanswered&page=1&page_size=20
+// filter 可选 unanswered（未回答）、answered（已回答）、accepted（已采纳）
+func GetQuestions(c *gin.Context) {
+	var boardID uint
+	if raw := c.Query("board_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			utils.JsonErrorWithCode(c, 1001, "无效的board_id参数")
+			return
+		}
+		boardID = uint(id)
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	page, pageSize := utils.GetPagination(c)
+	list, total, serviceErr := services.GetQuestions(userID, boardID, c.Query("filter"), page, pageSize)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取提问列表失败: board_id=%d, error=%v", boardID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post_list": list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetAnswers 获取提问的回答列表，被采纳的回答排在最前
+// GET /api/student/question/answer?question_id=1&sort=votes&page=1&page_size=20
+// sort 可选 votes（按点赞数）、time（按时间，默认）
+func GetAnswers(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Query("question_id"), 10, 64)
+	if err != nil || questionID == 0 {
+		utils.JsonErrorWithCode(c, 1001, "无效的question_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	page, pageSize := utils.GetPagination(c)
+	list, total, serviceErr := services.GetAnswers(userID, uint(questionID), c.Query("sort") == "votes", page, pageSize)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取回答列表失败: question_id=%d, error=%v", questionID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post_list": list,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// CreateAnswer 回答提问
+// POST /api/student/question/answer
+func CreateAnswer(c *gin.Context) {
+	var data CreateAnswerData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("回答提问参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("回答提问失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	answer, err := services.CreateAnswer(userID, data.QuestionID, data.Content, data.AttachmentIDs)
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		logger.GetLogger().Errorf("回答提问失败: user_id=%d, question_id=%d, error=%v", userID, data.QuestionID, err)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+	if err != nil {
+		logger.GetLogger().Errorf("回答提问失败: user_id=%d, question_id=%d, error=%v", userID, data.QuestionID, err)
+		utils.JsonErrorWithCode(c, 1003, "回答失败")
+		return
+	}
+
+	logger.GetLogger().Infof("用户回答提问成功: user_id=%d, question_id=%d, answer_id=%d", userID, data.QuestionID, answer.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"post": services.FormatPost(*answer, userID),
+	})
+}
+
+// AcceptAnswer 采纳回答，提问者或教师可操作
+// PUT /api/student/question/accept
+func AcceptAnswer(c *gin.Context) {
+	var data AcceptAnswerData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("采纳回答参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("采纳回答失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.AcceptAnswer(userID, data.QuestionID, data.AnswerID); serviceErr != nil {
+		logger.GetLogger().Errorf("采纳回答失败: user_id=%d, question_id=%d, answer_id=%d, error=%v", userID, data.QuestionID, data.AnswerID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("采纳回答成功: user_id=%d, question_id=%d, answer_id=%d", userID, data.QuestionID, data.AnswerID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -6,13 +6,15 @@ import (
 	"CMS/internal/models"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UpdatePostData struct {
-	PostID  uint   `json:"post_id" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	PostID  uint              `json:"post_id" binding:"required"`
+	Content string            `json:"content" binding:"required"`
+	Poll    *models.PollInput `json:"poll"` // 可选，修改投票选项，已有人投票时不允许修改
 }
 
 func UpdatePost(c *gin.Context) {
@@ -51,7 +53,14 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
-	err = services.UpdatePostByID(data.PostID, data.Content)
+	err = services.UpdatePostByID(data.PostID, data.Content, data.Poll)
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		logger.GetLogger().Errorf("修改帖子失败: post_id=%d, error=%v", data.PostID, err)
+		c.Error(serviceErr)
+		c.Abort()
+		return
+	}
 	if err != nil {
 		logger.GetLogger().Errorf("修改帖子失败: post_id=%d, error=%v", data.PostID, err)
 		c.Error(&models.ServiceError{Code: 1005, Message: "修改失败"})
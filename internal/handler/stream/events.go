@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const retryMillis = 3000 // 建议客户端断线后的重连间隔
+
+// StreamEvents 通过 SSE 推送通知、新帖子以及已订阅帖子的点赞数变化
+// GET /api/student/events?post_ids=1,2,3
+// 断线重连时客户端携带 Last-Event-ID 头部（或 last_event_id 参数），服务端补发期间错过的事件
+func StreamEvents(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("建立事件流失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	ok, err := services.AcquireStreamSlot(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("建立事件流失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "建立连接失败")
+		return
+	}
+	if !ok {
+		logger.GetLogger().Errorf("建立事件流失败，连接数超过上限: user_id=%d", userID)
+		utils.JsonErrorWithCode(c, 1003, "连接数超过上限")
+		return
+	}
+	defer services.ReleaseStreamSlot(userID)
+
+	// 先注册订阅再补发历史事件，避免补发期间产生的事件丢失
+	sub := services.SubscribeEvents(userID, parsePostIDs(c.Query("post_ids")))
+	defer services.UnsubscribeEvents(sub)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	logger.GetLogger().Infof("用户建立事件流: user_id=%d", userID)
+
+	var lastSentID int64
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	if lastID, err := strconv.ParseInt(lastEventID, 10, 64); err == nil && lastID > 0 {
+		lastSentID = lastID
+		missed, err := services.GetEventsSince(lastID)
+		if err != nil {
+			logger.GetLogger().Errorf("获取补发事件失败: user_id=%d, last_event_id=%d, error=%v", userID, lastID, err)
+		}
+		for _, evt := range missed {
+			if sub.Match(evt) {
+				renderEvent(c, evt)
+				lastSentID = evt.ID
+			}
+		}
+	}
+	c.Render(-1, sse.Event{Event: "ready", Retry: retryMillis, Data: gin.H{"last_event_id": lastSentID}})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(time.Duration(config.LoadedConfig.Realtime.HeartbeatSeconds) * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case evt := <-sub.Events:
+			if evt.ID > lastSentID {
+				renderEvent(c, evt)
+				lastSentID = evt.ID
+			}
+			return true
+		case <-heartbeat.C:
+			services.RefreshStreamSlot(userID)
+			c.SSEvent("heartbeat", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+
+	logger.GetLogger().Infof("用户事件流断开: user_id=%d", userID)
+}
+
+func renderEvent(c *gin.Context, evt models.RealtimeEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(evt.ID, 10),
+		Event: evt.Type,
+		Data:  evt.Data,
+	})
+}
+
+// parsePostIDs 解析逗号分隔的帖子ID列表，忽略非法值
+func parsePostIDs(raw string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
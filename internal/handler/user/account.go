@@ -0,0 +1,106 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteAccountData struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code"` // 已启用两步验证时必填：验证码或恢复码
+}
+
+// RequestDataExport 申请导出个人数据，文件由后台异步生成
+// POST /api/student/me/export
+func RequestDataExport(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	export, serviceErr := services.RequestDataExport(userID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("申请导出个人数据失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户申请导出个人数据: user_id=%d, export_id=%d", userID, export.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"export": export,
+	})
+}
+
+// GetDataExport 查看最近一次导出的状态，生成完成后返回限时下载链接
+// GET /api/student/me/export
+func GetDataExport(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	export, serviceErr := services.GetLatestDataExport(userID)
+	if serviceErr != nil {
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"export": export,
+	})
+}
+
+// DownloadDataExport 下载自己的导出文件，需登录且链接签名和有效期校验通过
+// GET /api/student/me/export/:id?expires=&sig=
+func DownloadDataExport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	reader, export, serviceErr := services.OpenDataExport(userID, uint(id), expires, c.Query("sig"))
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("下载导出文件失败: user_id=%d, export_id=%d, error=%v", userID, id, serviceErr)
+		utils.JsonResponse(c, http.StatusForbidden, serviceErr.Code, serviceErr.Message, nil)
+		return
+	}
+	defer reader.Close()
+
+	filename := fmt.Sprintf("personal-data-%s.zip", export.CreatedAt.Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Content-Length", strconv.FormatInt(export.Size, 10))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		logger.GetLogger().Errorf("输出导出文件失败: export_id=%d, error=%v", id, err)
+	}
+}
+
+// DeleteAccount 注销自己的账号，需要再次输入密码；成功后所有设备退出登录
+// DELETE /api/student/me
+func DeleteAccount(c *gin.Context) {
+	var data DeleteAccountData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.DeleteAccount(userID, data.Password, data.Code); serviceErr != nil {
+		logger.GetLogger().Errorf("注销账号失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
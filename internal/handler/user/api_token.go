@@ -0,0 +1,87 @@
+package user
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateAPITokenData struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"` // 有效期（天），0 表示永不过期
+}
+
+// CreateAPIToken 创建个人访问令牌，令牌明文只返回这一次
+// POST /api/student/api-token
+func CreateAPIToken(c *gin.Context) {
+	var data CreateAPITokenData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	// 强制管理员两步验证时，审核权限的令牌只能由通过两步验证的会话创建
+	for _, scope := range data.Scopes {
+		if scope == models.ScopeReportsReview && config.LoadedConfig.TwoFactor.RequiredForAdmin && !middleware.GetMFAFromContext(c) {
+			utils.JsonErrorWithCode(c, 403, "管理员需启用两步验证并通过验证后重新登录")
+			return
+		}
+	}
+
+	raw, token, serviceErr := services.CreateAPIToken(userID, userID, data.Name, data.Scopes, data.ExpiresInDays)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("创建访问令牌失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户创建访问令牌: user_id=%d, token_id=%d", userID, token.ID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"api_token": token.ToResponse(),
+		"token":     raw,
+	})
+}
+
+// GetAPITokens 获取自己的访问令牌列表
+// GET /api/student/api-token
+func GetAPITokens(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	list, err := services.GetAPITokens(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取访问令牌列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "获取访问令牌列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"api_token_list": list,
+	})
+}
+
+// RevokeAPIToken 撤销自己的访问令牌
+// DELETE /api/student/api-token?token_id=xxx
+func RevokeAPIToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Query("token_id"), 10, 64)
+	if err != nil || tokenID == 0 {
+		utils.JsonErrorWithCode(c, 1001, "无效的token_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.RevokeAPIToken(userID, userID, uint(tokenID)); serviceErr != nil {
+		logger.GetLogger().Errorf("撤销访问令牌失败: user_id=%d, token_id=%d, error=%v", userID, tokenID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户撤销访问令牌: user_id=%d, token_id=%d", userID, tokenID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
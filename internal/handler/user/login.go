@@ -2,6 +2,7 @@ package user
 
 import (
 	"CMS/internal/logger"
+	"CMS/internal/models"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
 	"errors"
@@ -23,24 +24,23 @@ func Login(c *gin.Context) {
 		utils.JsonErrorWithCode(c, 1001, "参数错误")
 		return
 	}
-	logger.GetLogger().Infof("用户尝试登录: %s", loginData.Username)
+	ip := c.ClientIP()
+	logger.GetLogger().Infof("用户尝试登录: %s, ip=%s", loginData.Username, ip)
+
+	// 账号或 IP 失败次数过多时拒绝登录
+	if serviceErr := services.CheckLoginAllowed(loginData.Username, ip); serviceErr != nil {
+		logger.GetLogger().Errorf("登录被限制 username=%s, ip=%s, error=%v", loginData.Username, ip, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
 
 	user, err := services.CheckLogin(loginData.Username, loginData.Password)
 	if err != nil {
-		// 区分用户不存在和密码错误
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.GetLogger().Errorf("用户不存在 username=%s", loginData.Username)
-			utils.JsonErrorWithCode(c, 200506, "用户不存在")
-			return
-		}
-
-		// 检查是否是密码错误
-		// 先获取用户信息确认用户存在
-		user, userErr := services.GetUserByUsername(loginData.Username)
-		if userErr == nil && user != nil {
-			// 用户存在但密码错误
-			logger.GetLogger().Errorf("用户密码错误 username=%s", loginData.Username)
-			utils.JsonErrorWithCode(c, 200507, "密码错误")
+		// 用户不存在和密码错误返回相同的提示，避免据此探测账号是否存在
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, services.ErrInvalidPassword) {
+			logger.GetLogger().Errorf("用户名或密码错误 username=%s, ip=%s", loginData.Username, ip)
+			services.RecordLoginFailure(loginData.Username, ip)
+			utils.JsonErrorWithCode(c, 200507, "账号或密码错误")
 			return
 		}
 
@@ -49,9 +49,51 @@ func Login(c *gin.Context) {
 		utils.JsonErrorWithCode(c, 1002, "登录失败")
 		return
 	}
-	token, err := utils.GenerateToken(user.ID)
+	// 导入名单生成的初始密码只能用来设置新密码，修改成功后才签发 token
+	if user.MustChangePassword {
+		passwordToken, serviceErr := services.BeginPasswordChangeLogin(user)
+		if serviceErr != nil {
+			logger.GetLogger().Errorf("用户需修改初始密码但无法继续登录 user_id=%d, error=%v", user.ID, serviceErr)
+			utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+			return
+		}
+		logger.GetLogger().Infof("用户身份校验通过，等待修改初始密码 user_id=%d", user.ID)
+		utils.JsonSuccessWithCode(c, 200, gin.H{
+			"user_id":                  user.ID,
+			"password_change_required": true,
+			"password_token":           passwordToken,
+		})
+		return
+	}
+	// 已启用两步验证时密码正确还不算登录成功，失败计数在验证码通过后才清除
+	if !user.TOTPEnabled {
+		services.ClearLoginFailures(loginData.Username)
+	}
+
+	respondLoginSuccess(c, user)
+}
+
+// respondLoginSuccess 身份校验通过后签发 token；已启用两步验证时先返回中间凭证，提交验证码后才签发正式 token
+func respondLoginSuccess(c *gin.Context, user *models.User) {
+	if user.TOTPEnabled {
+		mfaToken, err := services.BeginTwoFactorLogin(user.ID)
+		if err != nil {
+			logger.GetLogger().Errorf("签发两步验证凭证失败: user_id=%d, error=%v", user.ID, err)
+			utils.JsonErrorWithCode(c, 1003, "登录失败")
+			return
+		}
+		logger.GetLogger().Infof("用户身份校验通过，等待两步验证 user_id=%d", user.ID)
+		utils.JsonSuccessWithCode(c, 200, gin.H{
+			"user_id":             user.ID,
+			"two_factor_required": true,
+			"mfa_token":           mfaToken,
+		})
+		return
+	}
+
+	token, err := services.StartSession(user.ID, false, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		logger.GetLogger().Errorf("生成token失败: username=%s, error=%v", loginData.Username, err)
+		logger.GetLogger().Errorf("生成token失败: user_id=%d, error=%v", user.ID, err)
 		utils.JsonErrorWithCode(c, 1003, "生成token失败")
 		return
 	}
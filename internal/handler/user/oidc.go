@@ -0,0 +1,48 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCLogin 获取统一身份认证的授权地址，前端跳转到该地址完成登录
+// GET /api/user/oidc/login
+func OIDCLogin(c *gin.Context) {
+	authURL, serviceErr := services.BeginOIDCLogin()
+	if serviceErr != nil {
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"authorization_url": authURL,
+	})
+}
+
+// OIDCCallback 统一身份认证回调：用授权码完成登录，返回与账号密码登录相同的数据
+// GET /api/user/oidc/callback?code=xxx&state=xxx
+func OIDCCallback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		logger.GetLogger().Errorf("统一身份认证返回错误: error=%s, description=%s", errCode, c.Query("error_description"))
+		utils.JsonErrorWithCode(c, 1305, "统一身份认证失败")
+		return
+	}
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	user, serviceErr := services.CompleteOIDCLogin(state, code)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("统一身份认证登录失败: ip=%s, error=%v", c.ClientIP(), serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	respondLoginSuccess(c, user)
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangePasswordData struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type LoginChangePasswordData struct {
+	PasswordToken string `json:"password_token" binding:"required"` // 登录时返回的修改密码凭证
+	NewPassword   string `json:"new_password" binding:"required"`
+}
+
+type ResetPasswordData struct {
+	Token       string `json:"token" binding:"required"` // 管理员签发的一次性重置凭证
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword 修改密码，成功后除当前会话外的其他已登录会话全部失效
+// PUT /api/student/password
+func ChangePassword(c *gin.Context) {
+	var data ChangePasswordData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改密码参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.ChangePassword(userID, middleware.GetSessionIDFromContext(c), data.OldPassword, data.NewPassword); serviceErr != nil {
+		logger.GetLogger().Errorf("修改密码失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户修改密码成功: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// ResetPassword 使用管理员签发的一次性凭证重置密码，无需登录
+// POST /api/user/password/reset
+func ResetPassword(c *gin.Context) {
+	var data ResetPasswordData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("重置密码参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	if serviceErr := services.ResetPasswordWithToken(data.Token, data.NewPassword); serviceErr != nil {
+		logger.GetLogger().Errorf("重置密码失败: ip=%s, error=%v", c.ClientIP(), serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户通过重置凭证修改密码成功: ip=%s", c.ClientIP())
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// LoginChangePassword 登录时被要求修改初始密码，提交新密码后继续完成登录
+// POST /api/user/login/password
+func LoginChangePassword(c *gin.Context) {
+	var data LoginChangePasswordData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改初始密码参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	ip := c.ClientIP()
+	if serviceErr := services.CheckLoginAllowed("", ip); serviceErr != nil {
+		logger.GetLogger().Errorf("登录被限制 ip=%s, error=%v", ip, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	user, serviceErr := services.CompletePasswordChangeLogin(data.PasswordToken, data.NewPassword)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("修改初始密码失败: ip=%s, error=%v", ip, serviceErr)
+		// 凭证无效计入 IP 的登录失败次数，新密码不符合要求不计
+		if serviceErr.Code == 1107 {
+			services.RecordLoginFailure("", ip)
+		}
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+	logger.GetLogger().Infof("用户修改初始密码成功: user_id=%d", user.ID)
+	if !user.TOTPEnabled {
+		services.ClearLoginFailures(user.Username)
+	}
+
+	respondLoginSuccess(c, user)
+}
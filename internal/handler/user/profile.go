@@ -0,0 +1,83 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/models"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMe 获取自己的个人资料
+// GET /api/student/me
+func GetMe(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取个人资料失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	profile, serviceErr := services.GetUserProfile(userID, userID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取个人资料失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"profile": profile,
+	})
+}
+
+// UpdateMe 修改个人资料，只修改请求中出现的字段
+// PUT /api/student/me
+func UpdateMe(c *gin.Context) {
+	var data models.ProfileInput
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("修改个人资料参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("修改个人资料失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.UpdateProfile(userID, data); serviceErr != nil {
+		logger.GetLogger().Errorf("修改个人资料失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户修改个人资料成功: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// GetUserProfile 查看用户主页，对方设置了可见范围时只返回基本信息
+// GET /api/student/users/:id
+func GetUserProfile(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || targetID == 0 {
+		utils.JsonErrorWithCode(c, 1001, "无效的用户ID")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	profile, serviceErr := services.GetUserProfile(uint(targetID), userID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取用户主页失败: user_id=%d, target_id=%d, error=%v", userID, targetID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"profile": profile,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"CMS/internal/models"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
-	"regexp"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +12,7 @@ import (
 type RegData struct {
 	Username string `json:"username" binding:"required"`  // 学号或工号，只能是数字
 	Name     string `json:"name" binding:"required"`      // 姓名
-	Password string `json:"password" binding:"required"`  // 密码，8-16位
+	Password string `json:"password" binding:"required"`  // 密码，需满足密码强度要求
 	UserType int    `json:"user_type" binding:"required"` // 1学生，2管理员
 }
 
@@ -25,24 +24,17 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// 校验用户名只由数字组成
-	if !regexp.MustCompile(`^\d+$`).MatchString(req.Username) {
-		logger.GetLogger().Errorf("注册失败，账号必须为数字: %s", req.Username)
-		utils.JsonErrorWithCode(c, 1002, "账号必须为数字")
+	// 校验账号、姓名和用户类型
+	if serviceErr := services.ValidateNewUser(req.Username, req.Name, req.UserType); serviceErr != nil {
+		logger.GetLogger().Errorf("注册失败: username=%s, user_type=%d, error=%v", req.Username, req.UserType, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
 		return
 	}
 
-	// 校验密码长度
-	if len(req.Password) < 8 || len(req.Password) > 16 {
-		logger.GetLogger().Errorf("注册失败，密码长度不符合要求: %s", req.Username)
-		utils.JsonErrorWithCode(c, 1003, "密码长度需为8-16位")
-		return
-	}
-
-	// 校验用户类型
-	if req.UserType != 1 && req.UserType != 2 {
-		logger.GetLogger().Errorf("注册失败，用户类型错误: username=%s, user_type=%d", req.Username, req.UserType)
-		utils.JsonErrorWithCode(c, 1004, "用户类型错误")
+	// 校验密码强度
+	if serviceErr := services.ValidatePasswordStrength(req.Username, req.Password); serviceErr != nil {
+		logger.GetLogger().Errorf("注册失败，密码强度不符合要求: %s", req.Username)
+		utils.JsonErrorWithCode(c, 1003, serviceErr.Message)
 		return
 	}
 
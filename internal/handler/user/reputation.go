@@ -0,0 +1,36 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReputationRank 获取声望排行榜及当前用户的名次
+// GET /api/student/reputation/rank?limit=20
+func GetReputationRank(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	list, err := services.GetReputationRank(limit)
+	if err != nil {
+		logger.GetLogger().Errorf("获取声望排行榜失败: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "获取排行榜失败")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	reputation := 0
+	if user, err := services.GetUserByID(userID); err == nil {
+		reputation = user.Reputation
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"rank_list":  list,
+		"my_rank":    services.GetUserReputationRank(userID),
+		"reputation": reputation,
+	})
+}
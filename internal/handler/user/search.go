@@ -0,0 +1,27 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchUsers 按学号或姓名前缀搜索用户，供 @提及 自动补全使用
+// GET /api/student/user/search?q=2023&limit=10
+func SearchUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	list, err := services.SearchUsersByPrefix(c.Query("q"), limit)
+	if err != nil {
+		logger.GetLogger().Errorf("搜索用户失败: q=%s, error=%v", c.Query("q"), err)
+		utils.JsonErrorWithCode(c, 1001, "搜索失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_list": list,
+	})
+}
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions 查看自己已登录的设备
+// GET /api/student/me/sessions
+func GetSessions(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	list, err := services.GetSessions(userID, middleware.GetSessionIDFromContext(c))
+	if err != nil {
+		logger.GetLogger().Errorf("获取会话列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "获取会话列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"session_list": list,
+	})
+}
+
+// RevokeSession 退出指定设备
+// DELETE /api/student/me/sessions?session_id=xxx
+func RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Query("session_id"), 10, 64)
+	if err != nil || sessionID == 0 {
+		utils.JsonErrorWithCode(c, 1001, "无效的session_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.RevokeSession(userID, uint(sessionID)); serviceErr != nil {
+		logger.GetLogger().Errorf("撤销会话失败: user_id=%d, session_id=%d, error=%v", userID, sessionID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户撤销会话: user_id=%d, session_id=%d", userID, sessionID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// RevokeOtherSessions 退出除当前设备外的所有设备
+// DELETE /api/student/me/sessions/others
+func RevokeOtherSessions(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	count, serviceErr := services.RevokeOtherSessions(userID, middleware.GetSessionIDFromContext(c))
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("退出其他设备失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户退出其他设备: user_id=%d, count=%d", userID, count)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"revoked": count,
+	})
+}
+
+// Logout 退出当前设备
+// POST /api/student/logout
+func Logout(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.RevokeSessionBySessionID(userID, middleware.GetSessionIDFromContext(c)); serviceErr != nil {
+		logger.GetLogger().Errorf("退出登录失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户退出登录: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// RefreshToken 为当前会话签发新的 token 并延长有效期
+// POST /api/student/token/refresh
+func RefreshToken(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	token, serviceErr := services.RefreshSession(userID, middleware.GetSessionIDFromContext(c),
+		middleware.GetMFAFromContext(c), c.Request.UserAgent(), c.ClientIP())
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("刷新token失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"token": token,
+	})
+}
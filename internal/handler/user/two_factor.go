@@ -0,0 +1,178 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorCodeData struct {
+	Code string `json:"code" binding:"required"` // 验证器生成的6位验证码，或恢复码
+}
+
+type DisableTwoFactorData struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginData struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // 密码校验通过后返回的中间凭证
+	Code     string `json:"code" binding:"required"`
+}
+
+// GetTwoFactorStatus 查看两步验证状态
+// GET /api/student/2fa
+func GetTwoFactorStatus(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	enabled, remaining, serviceErr := services.GetTwoFactorStatus(userID)
+	if serviceErr != nil {
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"enabled":                  enabled,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// SetupTwoFactor 获取两步验证密钥和二维码链接
+// POST /api/student/2fa/setup
+func SetupTwoFactor(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	setup, serviceErr := services.SetupTwoFactor(userID)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("获取两步验证密钥失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"setup": setup,
+	})
+}
+
+// EnableTwoFactor 用验证码确认并启用两步验证，返回恢复码和通过两步验证的新 token
+// POST /api/student/2fa/enable
+func EnableTwoFactor(c *gin.Context) {
+	var data TwoFactorCodeData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	codes, serviceErr := services.EnableTwoFactor(userID, data.Code)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("启用两步验证失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	// 当前会话升级为已通过两步验证
+	token, refreshErr := services.RefreshSession(userID, middleware.GetSessionIDFromContext(c), true, c.Request.UserAgent(), c.ClientIP())
+	if refreshErr != nil {
+		logger.GetLogger().Errorf("启用两步验证后生成token失败: user_id=%d, error=%v", userID, refreshErr)
+	}
+
+	logger.GetLogger().Infof("用户启用两步验证: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"recovery_codes": codes,
+		"token":          token,
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+// POST /api/student/2fa/disable
+func DisableTwoFactor(c *gin.Context) {
+	var data DisableTwoFactorData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if serviceErr := services.DisableTwoFactor(userID, data.Password, data.Code); serviceErr != nil {
+		logger.GetLogger().Errorf("关闭两步验证失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户关闭两步验证: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// POST /api/student/2fa/recovery-codes
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var data TwoFactorCodeData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	codes, serviceErr := services.RegenerateRecoveryCodes(userID, data.Code)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("重新生成恢复码失败: user_id=%d, error=%v", userID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户重新生成恢复码: user_id=%d", userID)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+// LoginTwoFactor 两步登录的第二步：提交验证码或恢复码，换取正式 token
+// POST /api/user/login/2fa
+func LoginTwoFactor(c *gin.Context) {
+	var data TwoFactorLoginData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	ip := c.ClientIP()
+	pending, serviceErr := services.GetTwoFactorLoginUser(data.MFAToken)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("两步验证凭证无效: ip=%s", ip)
+		services.RecordLoginFailure("", ip)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+	// 按账号限制，否则可以反复输入密码换取新凭证绕过账号锁定，继续尝试验证码
+	if serviceErr := services.CheckLoginAllowed(pending.Username, ip); serviceErr != nil {
+		logger.GetLogger().Errorf("登录被限制 username=%s, ip=%s, error=%v", pending.Username, ip, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	user, serviceErr := services.CompleteTwoFactorLogin(data.MFAToken, data.Code)
+	if serviceErr != nil {
+		logger.GetLogger().Errorf("两步验证失败: username=%s, ip=%s, error=%v", pending.Username, ip, serviceErr)
+		// 验证码错误同时计入账号和 IP 的登录失败次数
+		services.RecordLoginFailure(pending.Username, ip)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+	services.ClearLoginFailures(user.Username)
+
+	token, err := services.StartSession(user.ID, true, c.Request.UserAgent(), ip)
+	if err != nil {
+		logger.GetLogger().Errorf("生成token失败: user_id=%d, error=%v", user.ID, err)
+		utils.JsonErrorWithCode(c, 1003, "生成token失败")
+		return
+	}
+
+	logger.GetLogger().Infof("用户登录成功 user_id=%d, username=%s, user_type=%d, mfa=true", user.ID, user.Username, user.UserType)
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"user_id":   user.ID,
+		"user_type": user.UserType,
+		"token":     token,
+	})
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BlockUserData struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// GetBlockedUsers 获取当前用户的屏蔽列表
+// GET /api/student/user-block
+func GetBlockedUsers(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("获取屏蔽列表失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1001, "用户认证失败")
+		return
+	}
+
+	list, err := services.GetBlockedUsers(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取屏蔽列表失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1002, "获取屏蔽列表失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"block_list": list,
+	})
+}
+
+// BlockUser 屏蔽用户
+// POST /api/student/user-block
+func BlockUser(c *gin.Context) {
+	var data BlockUserData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		logger.GetLogger().Errorf("屏蔽用户参数错误: %v", err)
+		utils.JsonErrorWithCode(c, 1001, "参数错误")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("屏蔽用户失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if serviceErr := services.BlockUser(userID, data.UserID); serviceErr != nil {
+		logger.GetLogger().Errorf("屏蔽用户失败: user_id=%d, target_id=%d, error=%v", userID, data.UserID, serviceErr)
+		utils.JsonErrorWithCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+
+	logger.GetLogger().Infof("用户屏蔽成功: user_id=%d, target_id=%d", userID, data.UserID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
+
+// UnblockUser 取消屏蔽用户
+// DELETE /api/student/user-block?user_id=1
+func UnblockUser(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Query("user_id"), 10, 64)
+	if err != nil || targetID == 0 {
+		logger.GetLogger().Errorf("取消屏蔽参数错误: user_id=%s", c.Query("user_id"))
+		utils.JsonErrorWithCode(c, 1001, "无效的user_id参数")
+		return
+	}
+
+	userID := middleware.GetUserIDFromContext(c)
+	if userID == 0 {
+		logger.GetLogger().Error("取消屏蔽失败: 无法获取用户ID")
+		utils.JsonErrorWithCode(c, 1002, "用户认证失败")
+		return
+	}
+
+	if err := services.UnblockUser(userID, uint(targetID)); err != nil {
+		logger.GetLogger().Errorf("取消屏蔽失败: user_id=%d, target_id=%d, error=%v", userID, targetID, err)
+		utils.JsonErrorWithCode(c, 1003, "取消屏蔽失败")
+		return
+	}
+
+	logger.GetLogger().Infof("用户取消屏蔽成功: user_id=%d, target_id=%d", userID, targetID)
+	utils.JsonSuccessWithCode(c, 200, nil)
+}
@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"CMS/internal/gateway"
+	"CMS/internal/logger"
+	"CMS/internal/middleware"
+	"CMS/internal/services"
+	"CMS/pkg/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueTicket 签发 WebSocket 连接票据，票据短期有效且只能使用一次
+// POST /api/student/ws/ticket
+func IssueTicket(c *gin.Context) {
+	userID := middleware.GetUserIDFromContext(c)
+	ticket, err := services.IssueStreamTicket(userID, middleware.GetSessionIDFromContext(c))
+	if err != nil {
+		logger.GetLogger().Errorf("签发WebSocket连接票据失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "签发连接票据失败")
+		return
+	}
+
+	utils.JsonSuccessWithCode(c, 200, gin.H{
+		"ticket": ticket,
+	})
+}
+
+// authenticate 校验连接凭证：浏览器使用 ticket 查询参数（先调用 IssueTicket 换取），
+// 其他客户端可直接在 Authorization 头部携带与 JWTAuthMiddleware 相同的 JWT
+func authenticate(c *gin.Context) (uint, int, string) {
+	if ticket := c.Query("ticket"); ticket != "" {
+		userID, err := services.RedeemStreamTicket(ticket)
+		if err != nil {
+			return 0, 500, "校验连接票据失败"
+		}
+		if userID == 0 {
+			return 0, 401, "连接票据无效或已过期"
+		}
+		return userID, 0, ""
+	}
+
+	tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if tokenString == "" {
+		return 0, 401, "缺少连接票据"
+	}
+	claims, err := middleware.ParseToken(tokenString)
+	if err != nil {
+		return 0, 401, "无效的token"
+	}
+	valid, err := services.ValidateSession(claims.ID, claims.UserID)
+	if err != nil {
+		return 0, 500, "校验登录状态失败"
+	}
+	if !valid {
+		return 0, 401, "登录已失效，请重新登录"
+	}
+	return claims.UserID, 0, ""
+}
+
+// Connect 建立 WebSocket 连接
+// GET /api/ws?ticket=
+func Connect(c *gin.Context) {
+	// 先校验来源再消耗票据，避免跨站请求作废用户的票据
+	if !gateway.CheckOrigin(c.Request) {
+		logger.GetLogger().Errorf("拒绝WebSocket连接，来源不在允许列表中: origin=%s", c.GetHeader("Origin"))
+		utils.JsonResponse(c, http.StatusForbidden, 403, "不允许的来源", nil)
+		return
+	}
+
+	userID, code, message := authenticate(c)
+	if userID == 0 {
+		utils.JsonErrorWithCode(c, code, message)
+		return
+	}
+
+	ok, err := services.AcquireStreamSlot(userID)
+	if err != nil {
+		logger.GetLogger().Errorf("建立WebSocket连接失败: user_id=%d, error=%v", userID, err)
+		utils.JsonErrorWithCode(c, 1001, "建立连接失败")
+		return
+	}
+	if !ok {
+		logger.GetLogger().Errorf("建立WebSocket连接失败，连接数超过上限: user_id=%d", userID)
+		utils.JsonErrorWithCode(c, 1002, "连接数超过上限")
+		return
+	}
+	defer services.ReleaseStreamSlot(userID)
+
+	gateway.Serve(c.Writer, c.Request, userID)
+}
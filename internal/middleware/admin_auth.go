@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"CMS/config"
 	"CMS/internal/services"
 	"CMS/pkg/utils"
 
@@ -24,6 +25,14 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 强制两步验证时，只接受通过两步验证登录签发的 token；
+		// 访问令牌只能由通过两步验证的会话创建，且已在鉴权时限定了可调用的接口
+		if config.LoadedConfig.TwoFactor.RequiredForAdmin && !GetMFAFromContext(c) && GetAuthMethodFromContext(c) != AuthMethodAPIToken {
+			utils.JsonErrorWithCode(c, 403, "管理员需启用两步验证并通过验证后重新登录")
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
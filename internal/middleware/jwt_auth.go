@@ -1,39 +1,37 @@
 package middleware
 
 import (
+	"CMS/config"
 	"CMS/internal/models"
-	"CMS/internal/pkg/database"
+	"CMS/internal/services"
 	"CMS/pkg/utils"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("cms_secret_key")
-
+// Claims 会话 token 的声明，与 utils.GenerateSessionToken 签发的内容一致；
+// 用户角色以数据库为准，不写入 token
 type Claims struct {
-	UserID   uint `json:"user_id"`
-	UserType int  `json:"user_type"`
+	UserID uint `json:"user_id"`
+	MFA    bool `json:"mfa,omitempty"` // 登录时是否通过了两步验证
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT 生成JWT token
-func GenerateJWT(user *models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID:   user.ID,
-		UserType: user.UserType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   user.Username,
-		},
+// ParseToken 使用配置的 JWT 密钥解析并校验会话 token
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(config.LoadedConfig.JWT.SecretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
 }
 
 // JWTAuthMiddleware JWT鉴权中间件
@@ -53,30 +51,38 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		// 个人访问令牌按前缀区分，单独校验
+		if strings.HasPrefix(tokenString, models.APITokenPrefix) {
+			authenticateAPIToken(c, tokenString)
+			return
+		}
 
-		if err != nil || !token.Valid {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
 			utils.JsonErrorWithCode(c, 401, "无效的token")
 			c.Abort()
 			return
 		}
 
-		// 检查用户是否存在
-		var user models.User
-		result := database.DB.First(&user, claims.UserID)
-		if result.Error != nil {
-			utils.JsonErrorWithCode(c, 401, "用户不存在")
+		// 通过 Redis 校验会话是否有效（退出登录、被撤销或账号被删除时会话失效）
+		valid, err := services.ValidateSession(claims.ID, claims.UserID)
+		if err != nil {
+			utils.JsonErrorWithCode(c, 500, "校验登录状态失败")
+			c.Abort()
+			return
+		}
+		if !valid {
+			utils.JsonErrorWithCode(c, 401, "登录已失效，请重新登录")
 			c.Abort()
 			return
 		}
 
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
-		c.Set("user_type", claims.UserType)
 		c.Set("username", claims.Subject)
+		c.Set("mfa", claims.MFA)
+		c.Set("session_id", claims.ID)
+		c.Set("auth_method", AuthMethodJWT)
 		c.Next()
 	}
 }
@@ -90,11 +96,12 @@ func GetUserIDFromContext(c *gin.Context) uint {
 	return userID.(uint)
 }
 
-// GetUserTypeFromContext 从上下文中获取用户类型
-func GetUserTypeFromContext(c *gin.Context) int {
-	userType, exists := c.Get("user_type")
-	if !exists {
-		return 0
-	}
-	return userType.(int)
+// GetSessionIDFromContext 从上下文中获取当前会话ID，访问令牌鉴权时为空
+func GetSessionIDFromContext(c *gin.Context) string {
+	return c.GetString("session_id")
+}
+
+// GetMFAFromContext 从上下文中获取本次登录是否通过了两步验证
+func GetMFAFromContext(c *gin.Context) bool {
+	return c.GetBool("mfa")
 }
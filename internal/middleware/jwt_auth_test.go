@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"CMS/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}) string {
+	t.Helper()
+	claims := Claims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestParseTokenUsesConfiguredSecret(t *testing.T) {
+	prev := config.LoadedConfig
+	config.LoadedConfig = &config.Config{}
+	config.LoadedConfig.JWT.SecretKey = "configured-secret"
+	t.Cleanup(func() { config.LoadedConfig = prev })
+
+	claims, err := ParseToken(signTestToken(t, jwt.SigningMethodHS256, []byte("configured-secret")))
+	if err != nil || claims.UserID != 7 || claims.ID != "session-1" {
+		t.Fatalf("配置密钥签发的 token 应校验通过: %+v, %v", claims, err)
+	}
+	if _, err := ParseToken(signTestToken(t, jwt.SigningMethodHS256, []byte("cms_secret_key"))); err == nil {
+		t.Fatal("默认密钥签发的 token 应被拒绝")
+	}
+	if _, err := ParseToken(signTestToken(t, jwt.SigningMethodHS512, []byte("configured-secret"))); err == nil {
+		t.Fatal("非 HS256 签名的 token 应被拒绝")
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APITokenPrefix 个人访问令牌的固定前缀，用于与 JWT 区分
+const APITokenPrefix = "cms_pat_"
+
+// 个人访问令牌权限范围
+const (
+	ScopePostsRead     = "posts:read"     // 浏览帖子、问答、投票等
+	ScopePostsWrite    = "posts:write"    // 发帖、改帖、删帖、点赞、投票、回答等
+	ScopeReportsReview = "reports:review" // 审批举报和待审核帖子（仅管理员）
+)
+
+// APITokenScopes 所有可申请的权限范围
+var APITokenScopes = []string{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeReportsReview,
+}
+
+// APIToken 个人访问令牌，只保存令牌的哈希
+type APIToken struct {
+	ID         uint
+	UserID     uint       `gorm:"index"`
+	Name       string     `gorm:"size:100;not null"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex"` // 令牌的 SHA-256 哈希（十六进制）
+	Hint       string     `gorm:"size:16"`             // 令牌末尾几位，便于用户辨认
+	Scopes     string     `gorm:"size:255"`            // 逗号分隔的权限范围
+	CreatedBy  uint       // 创建人ID，管理员为服务账号创建时与 UserID 不同
+	ExpiresAt  *time.Time // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time // 最近使用时间
+	LastUsedIP string     `gorm:"size:64"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+// HasScope 判断令牌是否包含该权限范围
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired 判断令牌是否已过期
+func (t APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
+type APITokenResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t APIToken) ToResponse() APITokenResponse {
+	return APITokenResponse{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		Name:       t.Name,
+		Hint:       t.Hint,
+		Scopes:     strings.Split(t.Scopes, ","),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		LastUsedIP: t.LastUsedIP,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Attachment 帖子附件，PostID 为0表示已上传但尚未关联帖子
+type Attachment struct {
+	ID           uint
+	UserID       uint   `gorm:"index"`
+	PostID       uint   `gorm:"index"`
+	FileName     string `gorm:"size:255"` // 原始文件名
+	ContentType  string `gorm:"size:100"` // 服务端嗅探得到的类型
+	Size         int64
+	StorageKey   string    `gorm:"size:255"`
+	ThumbnailKey string    `gorm:"size:255"` // 图片缩略图，非图片或无法解码时为空
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// IsImage 判断附件是否为图片
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+type AttachmentResponse struct {
+	ID           uint   `json:"id"`
+	FileName     string `json:"file_name"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	IsImage      bool   `json:"is_image"`
+	URL          string `json:"url"`                     // 带签名的限时访问链接
+	ThumbnailURL string `json:"thumbnail_url,omitempty"` // 带签名的限时缩略图链接
+}
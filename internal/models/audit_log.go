@@ -1,12 +1,47 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 审计日志操作者类型
+const (
+	AuditActorUser           = "user"            // 用户或管理员本人操作
+	AuditActorSystem         = "system"          // 系统自动操作（AdminID 为 0）
+	AuditActorServiceAccount = "service_account" // 服务账号通过访问令牌操作
+)
 
 type AuditLog struct {
 	ID        uint
 	AdminID   uint
+	ActorType string `gorm:"size:20;index"` // 操作者类型，见 AuditActor* 常量，写入时自动标注
 	Action    string
 	TargetID  uint
 	Detail    string    `gorm:"type:json"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
+
+// BeforeCreate 按操作者自动标注类型，服务账号的操作在审计日志中单独区分
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ActorType != "" {
+		return nil
+	}
+	if a.AdminID == 0 {
+		a.ActorType = AuditActorSystem
+		return nil
+	}
+	var isServiceAccount bool
+	err := tx.Session(&gorm.Session{NewDB: true}).Model(&User{}).
+		Select("is_service_account").Where("id = ?", a.AdminID).Scan(&isServiceAccount).Error
+	if err != nil {
+		return err
+	}
+	if isServiceAccount {
+		a.ActorType = AuditActorServiceAccount
+	} else {
+		a.ActorType = AuditActorUser
+	}
+	return nil
+}
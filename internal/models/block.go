@@ -2,13 +2,21 @@ package models
 
 import "time"
 
+// 举报对象类型
+const (
+	BlockTargetPost    = 0 // 帖子
+	BlockTargetMessage = 1 // 私信
+)
+
 type Block struct {
-	ID        uint
-	UserID    uint
-	TargetID  uint
-	Reason    string    `gorm:"type:text"`
-	Status    int       `gorm:"default:0"` // 0-待审核, 1-已通过, 2-已拒绝
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID           uint
+	UserID       uint
+	TargetID     uint
+	TargetType   int       `gorm:"default:0"` // 0-帖子, 1-私信
+	TargetUserID uint      `gorm:"index"`     // 被举报内容的作者，帖子删除后用于重算声望
+	Reason       string    `gorm:"type:text"`
+	Status       int       `gorm:"default:0"` // 0-待审核, 1-已通过, 2-已拒绝
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
 }
 
 type BlockResponse struct {
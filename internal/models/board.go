@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Board 版块（如课程、社团），帖子的 BoardID 为0时表示不属于任何版块
+type Board struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"uniqueIndex;not null;size:50" json:"name"`
+	Description    string    `gorm:"size:255" json:"description"`
+	AllowAnonymous bool      `gorm:"default:false" json:"allow_anonymous"` // 是否允许匿名发帖
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"-"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// BookmarkFolder 用户自定义的收藏夹
+type BookmarkFolder struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_bookmark_folder_name" json:"-"`
+	Name      string    `gorm:"size:50;uniqueIndex:idx_bookmark_folder_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Bookmark 收藏的帖子，仅自己可见；FolderID 为0表示未分类
+type Bookmark struct {
+	ID        uint
+	UserID    uint      `gorm:"uniqueIndex:idx_bookmark_user_post;index:idx_bookmark_user_folder"`
+	PostID    uint      `gorm:"uniqueIndex:idx_bookmark_user_post"`
+	FolderID  uint      `gorm:"default:0;index:idx_bookmark_user_folder"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// BookmarkResponse 收藏列表项，帖子被删除后 Post 为空、Deleted 为 true
+type BookmarkResponse struct {
+	ID       uint          `json:"id"`
+	PostID   uint          `json:"post_id"`
+	FolderID uint          `json:"folder_id"`
+	Time     string        `json:"time"`
+	Deleted  bool          `json:"deleted"`
+	Post     *PostResponse `json:"post,omitempty"`
+}
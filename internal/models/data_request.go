@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// 个人数据导出状态
+const (
+	DataExportPending    = 0 // 排队中
+	DataExportProcessing = 1 // 生成中
+	DataExportDone       = 2 // 已生成，可下载
+	DataExportFailed     = 3 // 生成失败
+)
+
+// 注销账号时已发布内容的处理方式
+const (
+	DeletedContentAnonymize = "anonymize" // 转为匿名帖子保留，作者显示为已注销用户
+	DeletedContentDelete    = "delete"    // 连同点赞记录一并删除
+)
+
+// DataExport 个人数据导出任务，由后台任务异步生成 ZIP 文件
+type DataExport struct {
+	ID         uint
+	UserID     uint       `gorm:"index"`
+	Status     int        `gorm:"default:0;index"` // 见 DataExport* 常量
+	StorageKey string     `gorm:"size:255"`        // 生成的 ZIP 文件在存储中的 key
+	Size       int64      // 文件大小（字节）
+	Error      string     `gorm:"size:255"` // 生成失败的原因
+	ExpiresAt  *time.Time // 文件过期时间，生成完成后设置，过期后文件和记录一并删除
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+type DataExportResponse struct {
+	ID          uint   `json:"id"`
+	Status      int    `json:"status"`
+	Size        int64  `json:"size,omitempty"`
+	Error       string `json:"error,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"` // 带签名的限时下载链接，仅生成完成后返回
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ExportProfile 导出文件 profile.json 的内容
+type ExportProfile struct {
+	ID                uint   `json:"id"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	UserType          int    `json:"user_type"`
+	Reputation        int    `json:"reputation"`
+	Bio               string `json:"bio"`
+	ClassName         string `json:"class_name"`
+	Department        string `json:"department"`
+	ProfileVisibility int    `json:"profile_visibility"`
+	TwoFactorEnabled  bool   `json:"two_factor_enabled"`
+	OIDCLinked        bool   `json:"oidc_linked"` // 是否已绑定统一身份认证账号
+	ExportedAt        string `json:"exported_at"`
+}
+
+// ExportPost 导出文件 posts.json 的条目，包含匿名帖子、草稿和待审核帖子
+type ExportPost struct {
+	ID          uint   `json:"id"`
+	Content     string `json:"content"`
+	BoardID     uint   `json:"board_id"`
+	PostType    int    `json:"post_type"`
+	ParentID    uint   `json:"parent_id,omitempty"`
+	Status      int    `json:"status"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	Pseudonym   string `json:"pseudonym,omitempty"`
+	Time        string `json:"time"`
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+}
+
+// ExportLike 导出文件 likes.json 的条目
+type ExportLike struct {
+	PostID uint `json:"post_id"`
+}
+
+// ExportReport 导出文件 reports.json 的条目，只包含用户自己提交的举报
+type ExportReport struct {
+	ID         uint   `json:"id"`
+	TargetType int    `json:"target_type"` // 0-帖子, 1-私信
+	TargetID   uint   `json:"target_id"`
+	Reason     string `json:"reason"`
+	Status     int    `json:"status"` // 0-待审核, 1-已通过, 2-已拒绝
+	Time       string `json:"time"`
+}
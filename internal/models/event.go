@@ -0,0 +1,21 @@
+package models
+
+import "encoding/json"
+
+// 实时事件类型
+const (
+	EventTypeNotification = "notification" // 新通知（定向推送）
+	EventTypeNewPost      = "new_post"     // 新帖子（广播）
+	EventTypeLikeCount    = "like_count"   // 帖子点赞数变化（按订阅推送）
+	EventTypeMessage      = "message"      // 新私信（定向推送）
+	EventTypeMessageRead  = "message_read" // 私信已读回执（定向推送）
+)
+
+// RealtimeEvent 通过 Redis pub/sub 在各实例间分发的实时事件
+type RealtimeEvent struct {
+	ID     int64           `json:"id"`
+	Type   string          `json:"type"`
+	UserID uint            `json:"user_id,omitempty"` // 定向推送的用户，0表示不限用户
+	PostID uint            `json:"post_id,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// 关注对象类型
+const (
+	FollowTargetUser  = 0 // 关注用户
+	FollowTargetBoard = 1 // 关注版块
+)
+
+// Follow 关注关系
+type Follow struct {
+	ID         uint
+	UserID     uint      `gorm:"uniqueIndex:idx_follow_target;index"` // 关注者
+	TargetType int       `gorm:"uniqueIndex:idx_follow_target;index:idx_follow_reverse"`
+	TargetID   uint      `gorm:"uniqueIndex:idx_follow_target;index:idx_follow_reverse"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// FollowUserItem 粉丝/关注列表中的用户
+type FollowUserItem struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Time     string `json:"time"` // 关注时间
+}
@@ -0,0 +1,15 @@
+package models
+
+// 登录锁定对象
+const (
+	LoginLockScopeUser = "user" // 按账号锁定
+	LoginLockScopeIP   = "ip"   // 按 IP 锁定
+)
+
+// LoginLockout 当前生效的登录锁定
+type LoginLockout struct {
+	Scope     string `json:"scope"`      // user 或 ip
+	Value     string `json:"value"`      // 被锁定的账号或 IP
+	LockedAt  string `json:"locked_at"`  // 锁定时间
+	ExpiresIn int64  `json:"expires_in"` // 剩余锁定秒数
+}
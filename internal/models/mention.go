@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Mention 帖子中的 @提及记录
+type Mention struct {
+	ID        uint
+	PostID    uint      `gorm:"uniqueIndex:idx_mention_post_user"`
+	UserID    uint      `gorm:"uniqueIndex:idx_mention_post_user;index"` // 被提及的用户
+	AuthorID  uint      // 帖子作者
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// UserSuggestion 提及自动补全的候选用户
+type UserSuggestion struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Conversation 一对一会话，UserAID 始终小于 UserBID，保证两人之间只有一个会话
+type Conversation struct {
+	ID            uint
+	UserAID       uint `gorm:"uniqueIndex:idx_conversation_users"`
+	UserBID       uint `gorm:"uniqueIndex:idx_conversation_users;index"`
+	UserALastRead uint // UserA 已读到的消息ID
+	UserBLastRead uint // UserB 已读到的消息ID
+	LastMessageAt time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+// Peer 返回会话中另一方的用户ID
+func (c Conversation) Peer(userID uint) uint {
+	if c.UserAID == userID {
+		return c.UserBID
+	}
+	return c.UserAID
+}
+
+// HasMember 判断用户是否为会话成员
+func (c Conversation) HasMember(userID uint) bool {
+	return c.UserAID == userID || c.UserBID == userID
+}
+
+// LastReadBy 返回用户已读到的消息ID
+func (c Conversation) LastReadBy(userID uint) uint {
+	if c.UserAID == userID {
+		return c.UserALastRead
+	}
+	return c.UserBLastRead
+}
+
+type Message struct {
+	ID             uint
+	ConversationID uint `gorm:"index"`
+	SenderID       uint
+	Content        string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+// UserBlock 用户屏蔽关系：UserID 屏蔽了 BlockedUserID
+type UserBlock struct {
+	ID            uint
+	UserID        uint      `gorm:"uniqueIndex:idx_user_block"`
+	BlockedUserID uint      `gorm:"uniqueIndex:idx_user_block;index"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+type MessageResponse struct {
+	ID             uint   `json:"id"`
+	ConversationID uint   `json:"conversation_id"`
+	SenderID       uint   `json:"sender_id"`
+	Content        string `json:"content"`
+	Time           string `json:"time"`
+}
+
+func (m Message) ToResponse() MessageResponse {
+	return MessageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Content:        m.Content,
+		Time:           m.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+	}
+}
+
+type ConversationResponse struct {
+	ID           uint             `json:"id"`
+	PeerID       uint             `json:"peer_id"`
+	PeerName     string           `json:"peer_name"`
+	LastMessage  *MessageResponse `json:"last_message"`
+	UnreadCount  int64            `json:"unread_count"`
+	PeerLastRead uint             `json:"peer_last_read"` // 对方已读到的消息ID（已读回执）
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// 通知类型
+const (
+	NotificationTypeLike         = "like"         // 帖子被点赞（聚合）
+	NotificationTypeModeration   = "moderation"   // 举报/帖子审核结果
+	NotificationTypeAnnouncement = "announcement" // 管理员公告
+	NotificationTypeMention      = "mention"      // 在帖子中被@提及
+	NotificationTypeAnswer       = "answer"       // 提问收到回答/回答被采纳
+)
+
+// NotificationTypes 所有可被屏蔽的通知类型
+var NotificationTypes = []string{
+	NotificationTypeLike,
+	NotificationTypeModeration,
+	NotificationTypeAnnouncement,
+	NotificationTypeMention,
+	NotificationTypeAnswer,
+}
+
+type Notification struct {
+	ID         uint
+	UserID     uint      `gorm:"index:idx_notification_user_read"` // 接收者
+	Type       string    `gorm:"size:32"`
+	TargetID   uint      // 关联对象ID（如帖子ID）
+	Content    string    `gorm:"type:text"`
+	ActorCount int       `gorm:"default:1"` // 聚合通知的触发人数
+	IsRead     bool      `gorm:"default:false;index:idx_notification_user_read"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// NotificationMute 用户屏蔽的通知类型，存在记录即表示屏蔽
+type NotificationMute struct {
+	ID     uint
+	UserID uint   `gorm:"uniqueIndex:idx_mute_user_type"`
+	Type   string `gorm:"size:32;uniqueIndex:idx_mute_user_type"`
+}
+
+type NotificationResponse struct {
+	ID         uint   `json:"id"`
+	Type       string `json:"type"`
+	TargetID   uint   `json:"target_id"`
+	Content    string `json:"content"`
+	ActorCount int    `json:"actor_count"`
+	IsRead     bool   `json:"is_read"`
+	Time       string `json:"time"`
+}
+
+func (n Notification) ToResponse() NotificationResponse {
+	return NotificationResponse{
+		ID:         n.ID,
+		Type:       n.Type,
+		TargetID:   n.TargetID,
+		Content:    n.Content,
+		ActorCount: n.ActorCount,
+		IsRead:     n.IsRead,
+		Time:       n.UpdatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+	}
+}
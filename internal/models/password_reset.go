@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken 管理员为用户签发的一次性密码重置凭证，只保存凭证的哈希
+type PasswordResetToken struct {
+	ID        uint
+	UserID    uint       `gorm:"index"`
+	TokenHash string     `gorm:"size:64;uniqueIndex"` // 凭证的 SHA-256 哈希（十六进制）
+	CreatedBy uint       // 签发的管理员ID
+	ExpiresAt time.Time  // 过期时间
+	UsedAt    *time.Time // 使用时间，为空表示尚未使用
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
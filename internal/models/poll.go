@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Poll 帖子附带的投票
+type Poll struct {
+	ID          uint
+	PostID      uint       `gorm:"uniqueIndex"`
+	MultiChoice bool       `gorm:"default:false"` // 是否允许多选
+	Anonymous   bool       `gorm:"default:false"` // 匿名投票时不公开投票人
+	ClosesAt    *time.Time // 截止时间，为空表示不截止
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+// IsClosed 判断投票是否已截止
+func (p Poll) IsClosed() bool {
+	return p.ClosesAt != nil && !p.ClosesAt.After(time.Now())
+}
+
+// PollOption 投票选项，VoteCount 为持久化的票数
+type PollOption struct {
+	ID        uint
+	PollID    uint   `gorm:"index"`
+	Text      string `gorm:"size:100"`
+	Position  int
+	VoteCount int `gorm:"default:0"`
+}
+
+// PollVote 投票记录，多选时每个选项一条
+type PollVote struct {
+	ID        uint
+	PollID    uint      `gorm:"uniqueIndex:idx_poll_vote"`
+	UserID    uint      `gorm:"uniqueIndex:idx_poll_vote"`
+	OptionID  uint      `gorm:"uniqueIndex:idx_poll_vote"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// PollInput 创建或修改投票的参数
+type PollInput struct {
+	Options     []string   `json:"options"`
+	MultiChoice bool       `json:"multi_choice"`
+	Anonymous   bool       `json:"anonymous"`
+	ClosesAt    *time.Time `json:"closes_at"` // RFC3339，可选
+}
+
+type PollOptionResponse struct {
+	ID     uint             `json:"id"`
+	Text   string           `json:"text"`
+	Votes  int              `json:"votes"`
+	Voters []UserSuggestion `json:"voters,omitempty"` // 非匿名投票时返回投票人
+}
+
+type PollResponse struct {
+	ID          uint                 `json:"id"`
+	MultiChoice bool                 `json:"multi_choice"`
+	Anonymous   bool                 `json:"anonymous"`
+	ClosesAt    string               `json:"closes_at,omitempty"`
+	Closed      bool                 `json:"closed"`
+	TotalVoters int64                `json:"total_voters"`
+	Options     []PollOptionResponse `json:"options"`
+	MyVotes     []uint               `json:"my_votes"` // 当前用户投票的选项ID
+}
@@ -4,27 +4,109 @@ import (
 	"time"
 )
 
+// 帖子状态
+const (
+	PostStatusPublished = 0 // 已发布
+	PostStatusDraft     = 1 // 草稿
+	PostStatusScheduled = 2 // 定时发布
+	PostStatusPending   = 3 // 待审核（先审后发）
+)
+
+// 帖子类型
+const (
+	PostTypeNormal   = 0 // 普通帖子
+	PostTypeQuestion = 1 // 问答版块的提问
+	PostTypeAnswer   = 2 // 对提问的回答，ParentID 为提问的帖子ID
+)
+
 type Post struct {
-	ID       uint
-	Content  string `gorm:"type:text"`
-	UserID   uint
-	PostTime time.Time
+	ID               uint
+	Content          string `gorm:"type:text"` // Markdown 原文
+	ContentHTML      string `gorm:"type:text"` // 服务端渲染并清洗后的 HTML
+	UserID           uint
+	BoardID          uint `gorm:"index;default:0"` // 所属版块，0表示全站
+	PostTime         time.Time
+	IsAnnouncement   bool       `gorm:"default:false"` // 管理员公告
+	PinnedUntil      *time.Time // 公告置顶截止时间，为空表示不置顶
+	MustAcknowledge  bool       `gorm:"default:false"`                            // 公告是否要求用户确认已读
+	Status           int        `gorm:"default:0;index:idx_post_status_schedule"` // 0-已发布, 1-草稿, 2-定时发布
+	ScheduledAt      *time.Time `gorm:"index:idx_post_status_schedule"`           // 定时发布时间
+	IsAnonymous      bool       `gorm:"default:false"`                            // 匿名帖子，UserID 仅用于审核，不对外返回
+	Pseudonym        string     `gorm:"size:32"`                                  // 匿名帖子的作者化名，同一帖子内固定
+	PostType         int        `gorm:"default:0;index"`                          // 0-普通, 1-提问, 2-回答
+	ParentID         uint       `gorm:"index"`                                    // 回答所属的提问
+	AcceptedAnswerID uint       `gorm:"default:0"`                                // 提问被采纳的回答ID
+	AnswerCount      int        `gorm:"default:0"`                                // 提问的回答数
 }
 
 type PostResponse struct {
-	ID      uint   `json:"id"`
-	Content string `json:"content"`
-	UserID  uint   `json:"user_id"`
-	Time    string `json:"time"`
-	Likes   int    `json:"likes"`
+	ID               uint   `json:"id"`
+	Content          string `json:"content"`
+	ContentHTML      string `json:"content_html"`
+	UserID           uint   `json:"user_id"`
+	BoardID          uint   `json:"board_id"`
+	Time             string `json:"time"`
+	Likes            int    `json:"likes"`
+	IsAnnouncement   bool   `json:"is_announcement"`
+	IsPinned         bool   `json:"is_pinned"`
+	MustAcknowledge  bool   `json:"must_acknowledge"`
+	Acknowledged     bool   `json:"acknowledged"`  // 当前用户是否已确认公告
+	IsBookmarked     bool   `json:"is_bookmarked"` // 当前用户是否已收藏
+	IsAnonymous      bool   `json:"is_anonymous"`
+	Pseudonym        string `json:"pseudonym,omitempty"` // 匿名帖子的作者化名
+	IsOwn            bool   `json:"is_own"`              // 是否为当前用户发布（匿名帖子据此判断能否编辑）
+	PostType         int    `json:"post_type"`
+	ParentID         uint   `json:"parent_id,omitempty"`
+	AcceptedAnswerID uint   `json:"accepted_answer_id,omitempty"`
+	AnswerCount      int    `json:"answer_count"`
+	Status           int    `json:"status"`
+	ScheduledAt      string `json:"scheduled_at,omitempty"`
+
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
+	Poll        *PollResponse        `json:"poll,omitempty"`
 }
 
+// IsPinned 判断公告当前是否处于置顶状态
+func (p Post) IsPinned() bool {
+	return p.IsAnnouncement && p.PinnedUntil != nil && p.PinnedUntil.After(time.Now())
+}
+
+// ToResponse 转换为响应结构，匿名帖子不返回作者ID
 func (p Post) ToResponse() PostResponse {
+	var scheduledAt string
+	if p.ScheduledAt != nil {
+		scheduledAt = p.ScheduledAt.Format("2006-01-02T15:04:05.000-07:00")
+	}
+	userID := p.UserID
+	if p.IsAnonymous {
+		userID = 0
+	}
 	return PostResponse{
-		ID:      p.ID,
-		Content: p.Content,
-		UserID:  p.UserID,
-		Time:    p.PostTime.Format("2006-01-02T15:04:05.000-07:00"),
-		Likes:   0,
+		ID:               p.ID,
+		Content:          p.Content,
+		ContentHTML:      p.ContentHTML,
+		UserID:           userID,
+		BoardID:          p.BoardID,
+		Time:             p.PostTime.Format("2006-01-02T15:04:05.000-07:00"),
+		Likes:            0,
+		IsAnnouncement:   p.IsAnnouncement,
+		IsPinned:         p.IsPinned(),
+		MustAcknowledge:  p.MustAcknowledge,
+		Status:           p.Status,
+		ScheduledAt:      scheduledAt,
+		IsAnonymous:      p.IsAnonymous,
+		Pseudonym:        p.Pseudonym,
+		PostType:         p.PostType,
+		ParentID:         p.ParentID,
+		AcceptedAnswerID: p.AcceptedAnswerID,
+		AnswerCount:      p.AnswerCount,
 	}
 }
+
+// AnnouncementRead 用户对公告的已读确认
+type AnnouncementRead struct {
+	ID        uint
+	PostID    uint      `gorm:"uniqueIndex:idx_announcement_read"`
+	UserID    uint      `gorm:"uniqueIndex:idx_announcement_read"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// 个人主页可见范围
+const (
+	ProfileVisibilityPublic    = 0 // 所有人可见
+	ProfileVisibilityFollowers = 1 // 仅粉丝可见
+	ProfileVisibilityPrivate   = 2 // 仅自己可见
+)
+
+// ProfileInput 修改个人资料的参数，字段为 nil 表示不修改
+type ProfileInput struct {
+	Bio               *string `json:"bio"`
+	AvatarID          *uint   `json:"avatar_id"` // 通过附件上传接口上传的图片ID，0表示移除头像
+	ClassName         *string `json:"class_name"`
+	Department        *string `json:"department"`
+	ProfileVisibility *int    `json:"profile_visibility"`
+}
+
+// UserProfile 用户主页信息；Restricted 为 true 时简介、班级、院系和近期帖子对当前用户不可见
+type UserProfile struct {
+	ID                 uint           `json:"id"`
+	Username           string         `json:"username"`
+	Name               string         `json:"name"`
+	UserType           int            `json:"user_type"`
+	Reputation         int            `json:"reputation"`
+	AvatarURL          string         `json:"avatar_url,omitempty"`
+	AvatarThumbnailURL string         `json:"avatar_thumbnail_url,omitempty"`
+	Bio                string         `json:"bio,omitempty"`
+	ClassName          string         `json:"class_name,omitempty"`
+	Department         string         `json:"department,omitempty"`
+	ProfileVisibility  *int           `json:"profile_visibility,omitempty"` // 仅查看自己的资料时返回
+	PostCount          int64          `json:"post_count"`
+	LikesReceived      int64          `json:"likes_received"`
+	Followers          int64          `json:"followers"`
+	Following          int64          `json:"following"`
+	IsFollowing        bool           `json:"is_following"`
+	Restricted         bool           `json:"restricted"`
+	RecentPosts        []PostResponse `json:"recent_posts,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// 名单导入每行的处理结果
+const (
+	RosterActionCreate    = "create"    // 新建账号
+	RosterActionUpdate    = "update"    // 更新已有账号的姓名、班级或角色
+	RosterActionUnchanged = "unchanged" // 已有账号且信息一致
+	RosterActionError     = "error"     // 校验或写入失败
+)
+
+// RosterRow 名单中的一行，Row 为表格中的行号（从1开始，含表头）
+type RosterRow struct {
+	Row       int
+	Username  string
+	Name      string
+	ClassName string
+	UserType  int
+
+	HasClassName bool // 名单包含班级列；不包含时不修改已有账号的班级
+	HasUserType  bool // 名单包含角色列；不包含时不修改已有账号的角色
+}
+
+type RosterResultItem struct {
+	Row             int    `json:"row"`
+	Username        string `json:"username"`
+	Name            string `json:"name"`
+	ClassName       string `json:"class_name"`
+	UserType        int    `json:"user_type"`
+	Action          string `json:"action"`
+	InitialPassword string `json:"-"` // 新建账号的初始密码，只写入结果文件
+	Error           string `json:"error,omitempty"`
+}
+
+type RosterImportResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Total     int                `json:"total"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Items     []RosterResultItem `json:"items"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// UserSession 登录会话，每次登录创建一条；有效会话同时缓存在 Redis 中供鉴权中间件校验
+type UserSession struct {
+	ID         uint
+	UserID     uint       `gorm:"index"`
+	SessionID  string     `gorm:"size:32;uniqueIndex"` // 会话ID，写入 JWT 的 jti
+	UserAgent  string     `gorm:"size:255"`
+	IP         string     `gorm:"size:64"`
+	MFA        bool       `gorm:"default:false"` // 是否通过两步验证
+	ExpiresAt  time.Time  // 当前 token 的过期时间，刷新 token 时延长
+	LastSeenAt time.Time  // 最近活跃时间（刷新 token 或查看会话列表时从 Redis 同步）
+	RevokedAt  *time.Time // 撤销时间，为空表示有效
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为当前请求所用的会话
+}
+
+func (s UserSession) ToResponse(currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.SessionID == currentSessionID,
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RecoveryCode 两步验证恢复码，丢失验证器时代替验证码登录，每个只能使用一次；只保存哈希
+type RecoveryCode struct {
+	ID        uint
+	UserID    uint       `gorm:"index"`
+	CodeHash  string     `gorm:"size:64"`
+	UsedAt    *time.Time // 使用时间，为空表示尚未使用
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// TwoFactorSetup 开启两步验证时返回给前端的密钥和二维码链接
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// 链接，前端渲染为二维码
+}
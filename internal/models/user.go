@@ -1,6 +1,10 @@
 package models
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 const (
 	StudentRole = 1 // 学生用户
@@ -8,14 +12,48 @@ const (
 )
 
 type User struct {
-	ID       uint   `gorm:"primaryKey" json:"id"`
-	Username string `gorm:"uniqueIndex;not null;size:20" json:"username"` // 学号作为用户名
-	Password string `gorm:"not null" json:"-"`                            // 密码不返回给前端
-	Name     string `gorm:"size:50" json:"name"`                          // 用户姓名
-	UserType int    `gorm:"default:1" json:"user_type"`                   // 用户类型: 1-学生, 2-管理员
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	Username   string `gorm:"uniqueIndex;not null;size:20" json:"username"` // 学号作为用户名
+	Password   string `gorm:"not null" json:"-"`                            // 密码不返回给前端
+	Name       string `gorm:"size:50" json:"name"`                          // 用户姓名
+	UserType   int    `gorm:"default:1" json:"user_type"`                   // 用户类型: 1-学生, 2-管理员
+	Reputation int    `gorm:"default:0" json:"reputation"`                  // 声望
+
+	Bio               string `gorm:"size:500" json:"bio"`                 // 个人简介
+	AvatarID          uint   `json:"avatar_id"`                           // 头像附件ID，0表示未设置
+	ClassName         string `gorm:"size:50" json:"class_name"`           // 班级
+	Department        string `gorm:"size:100" json:"department"`          // 院系
+	ProfileVisibility int    `gorm:"default:0" json:"profile_visibility"` // 主页可见范围，见 ProfileVisibility* 常量
+
+	TOTPSecret   string `gorm:"size:64" json:"-"`       // 两步验证密钥（Base32）
+	TOTPEnabled  bool   `gorm:"default:false" json:"-"` // 是否已启用两步验证
+	TOTPLastStep int64  `json:"-"`                      // 最近一次验证通过的时间步，用于防止验证码重放
+
+	OIDCSubject *string `gorm:"column:oidc_subject;size:255;uniqueIndex" json:"-"` // 统一身份认证账号的 sub，未绑定时为 NULL
+
+	IsServiceAccount bool `gorm:"default:false" json:"is_service_account"` // 是否为服务账号（只能通过访问令牌调用接口，不能登录）
+
+	MustChangePassword bool       `gorm:"default:false" json:"-"` // 登录后必须先修改密码（导入名单生成的初始密码），修改密码后清除
+	PasswordExpiresAt  *time.Time `json:"-"`                      // 当前密码的失效时间，只用于初始密码，为空表示不过期
+
+	ClosedAt *time.Time `gorm:"index" json:"-"` // 注销时间，注销后账号信息已匿名化，不能登录，也不再出现在搜索和主页中
+}
+
+// IsClosed 账号是否已注销
+func (u *User) IsClosed() bool {
+	return u.ClosedAt != nil
 }
 
 func (u *User) CheckPasswordHash(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// ReputationRankItem 声望排行榜条目
+type ReputationRankItem struct {
+	Rank       int64  `json:"rank"`
+	UserID     uint   `json:"user_id"`
+	Username   string `json:"username"`
+	Name       string `json:"name"`
+	Reputation int    `json:"reputation"`
+}
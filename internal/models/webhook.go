@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook 事件类型
+const (
+	WebhookEventPostCreated   = "post.created"
+	WebhookEventPostDeleted   = "post.deleted"
+	WebhookEventPostLiked     = "post.liked"
+	WebhookEventPostUnliked   = "post.unliked"
+	WebhookEventReportDecided = "report.decided"
+)
+
+// WebhookEvents 所有可订阅的事件类型
+var WebhookEvents = []string{
+	WebhookEventPostCreated,
+	WebhookEventPostDeleted,
+	WebhookEventPostLiked,
+	WebhookEventPostUnliked,
+	WebhookEventReportDecided,
+}
+
+// 投递状态
+const (
+	WebhookDeliveryPending = 0 // 待投递/等待重试
+	WebhookDeliverySuccess = 1 // 投递成功
+	WebhookDeliveryDead    = 2 // 超过最大重试次数，进入死信
+)
+
+type Webhook struct {
+	ID        uint
+	URL       string    `gorm:"size:500;not null"`
+	Events    string    `gorm:"size:255"` // 逗号分隔的事件类型
+	Secret    string    `gorm:"size:128;not null"`
+	Enabled   bool      `gorm:"default:true"`
+	CreatedBy uint      // 创建的管理员ID
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Subscribes 判断是否订阅了该事件
+func (w Webhook) Subscribes(event string) bool {
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             uint
+	WebhookID      uint      `gorm:"index"`
+	Event          string    `gorm:"size:64"`
+	Payload        string    `gorm:"type:text"`
+	Status         int       `gorm:"default:0;index:idx_delivery_status_next"`
+	Attempts       int       `gorm:"default:0"`
+	NextAttemptAt  time.Time `gorm:"index:idx_delivery_status_next"`
+	LastStatusCode int
+	LastError      string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+type WebhookResponse struct {
+	ID        uint     `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Enabled   bool     `json:"enabled"`
+	CreatedAt string   `json:"created_at"`
+}
+
+func (w Webhook) ToResponse() WebhookResponse {
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    strings.Split(w.Events, ","),
+		Enabled:   w.Enabled,
+		CreatedAt: w.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint   `json:"id"`
+	WebhookID      uint   `json:"webhook_id"`
+	Event          string `json:"event"`
+	Payload        string `json:"payload"`
+	Status         int    `json:"status"`
+	Attempts       int    `json:"attempts"`
+	LastStatusCode int    `json:"last_status_code"`
+	LastError      string `json:"last_error"`
+	CreatedAt      string `json:"created_at"`
+}
+
+func (d WebhookDelivery) ToResponse() WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+	}
+}
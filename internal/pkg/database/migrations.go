@@ -13,5 +13,27 @@ func autoMigrate(db *gorm.DB) error {
 		&models.Block{},
 		&models.Like{},
 		&models.AuditLog{},
+		&models.Notification{},
+		&models.NotificationMute{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Conversation{},
+		&models.Message{},
+		&models.UserBlock{},
+		&models.Board{},
+		&models.AnnouncementRead{},
+		&models.Attachment{},
+		&models.Mention{},
+		&models.Follow{},
+		&models.BookmarkFolder{},
+		&models.Bookmark{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+		&models.APIToken{},
+		&models.UserSession{},
+		&models.DataExport{},
 	)
 }
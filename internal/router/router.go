@@ -2,9 +2,16 @@ package router
 
 import (
 	"CMS/internal/handler/admin"
+	"CMS/internal/handler/attachment"
 	"CMS/internal/handler/block"
+	"CMS/internal/handler/bookmark"
+	"CMS/internal/handler/follow"
+	"CMS/internal/handler/message"
+	"CMS/internal/handler/notification"
 	"CMS/internal/handler/post"
+	"CMS/internal/handler/stream"
 	"CMS/internal/handler/user"
+	"CMS/internal/handler/ws"
 	"CMS/internal/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -19,8 +26,15 @@ func Init(r *gin.Engine) {
 	// 公开路由
 	public := r.Group(pre)
 	{
-		public.POST("/user/reg", user.Register) // 用户注册
-		public.POST("/user/login", user.Login)  // 用户登录
+		public.POST("/user/reg", user.Register)                       // 用户注册
+		public.POST("/user/login", user.Login)                        // 用户登录
+		public.POST("/user/login/2fa", user.LoginTwoFactor)           // 两步登录：提交验证码
+		public.POST("/user/login/password", user.LoginChangePassword) // 登录时修改初始密码
+		public.GET("/user/oidc/login", user.OIDCLogin)                // 统一身份认证登录
+		public.GET("/user/oidc/callback", user.OIDCCallback)          // 统一身份认证回调
+		public.POST("/user/password/reset", user.ResetPassword)       // 使用重置凭证重置密码
+		public.GET("/ws", ws.Connect)                                 // WebSocket 连接（handler 内校验连接票据和来源）
+		public.GET("/file/:id", attachment.Serve)                     // 下载附件（签名链接校验）
 	}
 
 	// 需要身份验证的基础路由组
@@ -30,22 +44,121 @@ func Init(r *gin.Engine) {
 		// 学生路由
 		student := auth.Group("/student")
 		{
-			student.GET("/post", post.GetAllPosts)           // 获取所有帖子
-			student.POST("/post", post.CreatePost)           // 发布帖子
-			student.DELETE("/post", post.DeletePost)         // 删除帖子
-			student.POST("/report-post", block.ReportPost)   // 举报帖子
-			student.PUT("/post", post.UpdatePost)            // 修改帖子
-			student.GET("/likes", post.GetPostLikes)         // 获取帖子点赞数
-			student.GET("/report-post", block.GetReportList) // 查看举报审批
-			student.POST("/likes", post.LikePost)            // 点赞帖子
+			student.GET("/post", post.GetAllPosts)                          // 获取所有帖子
+			student.POST("/post", post.CreatePost)                          // 发布帖子
+			student.DELETE("/post", post.DeletePost)                        // 删除帖子
+			student.POST("/report-post", block.ReportPost)                  // 举报帖子
+			student.PUT("/post", post.UpdatePost)                           // 修改帖子
+			student.GET("/likes", post.GetPostLikes)                        // 获取帖子点赞数
+			student.GET("/report-post", block.GetReportList)                // 查看举报审批
+			student.POST("/likes", post.LikePost)                           // 点赞帖子
+			student.GET("/board", post.GetBoards)                           // 获取版块列表
+			student.POST("/announcement/ack", post.AcknowledgeAnnouncement) // 确认已读公告
+			student.GET("/draft", post.GetDrafts)                           // 获取草稿和定时帖子
+			student.POST("/draft", post.SaveDraft)                          // 保存草稿/定时发布
+			student.PUT("/draft", post.UpdateDraft)                         // 修改草稿
+			student.DELETE("/draft", post.DeleteDraft)                      // 删除草稿
+			student.POST("/draft/publish", post.PublishDraft)               // 立即发布草稿
+			student.POST("/attachment", attachment.Upload)                  // 上传附件
+			student.GET("/poll", post.GetPollResult)                        // 获取投票结果
+			student.POST("/poll/vote", post.Vote)                           // 参与投票
+			student.GET("/question", post.GetQuestions)                     // 获取提问列表
+			student.GET("/question/answer", post.GetAnswers)                // 获取提问的回答
+			student.POST("/question/answer", post.CreateAnswer)             // 回答提问
+			student.PUT("/question/accept", post.AcceptAnswer)              // 采纳回答
+
+			student.GET("/notification", notification.GetNotifications)            // 获取通知列表
+			student.GET("/notification/unread-count", notification.GetUnreadCount) // 获取未读通知数
+			student.PUT("/notification/read", notification.MarkRead)               // 标记通知已读
+			student.PUT("/notification/read-all", notification.MarkAllRead)        // 全部标记已读
+			student.GET("/notification/mute", notification.GetMuteSettings)        // 获取通知屏蔽设置
+			student.PUT("/notification/mute", notification.SetMute)                // 修改通知屏蔽设置
+
+			student.GET("/events", stream.StreamEvents) // SSE 实时事件流
+			student.POST("/ws/ticket", ws.IssueTicket)  // 申请 WebSocket 连接票据
+
+			student.POST("/message", message.SendMessage)                     // 发送私信
+			student.GET("/conversation", message.GetConversations)            // 获取会话列表
+			student.GET("/conversation/message", message.GetMessages)         // 获取会话消息记录
+			student.PUT("/conversation/read", message.MarkRead)               // 标记会话已读
+			student.POST("/report-message", message.ReportMessage)            // 举报私信
+			student.GET("/user-block", user.GetBlockedUsers)                  // 获取屏蔽列表
+			student.POST("/user-block", user.BlockUser)                       // 屏蔽用户
+			student.DELETE("/user-block", user.UnblockUser)                   // 取消屏蔽用户
+			student.GET("/user/search", user.SearchUsers)                     // 搜索用户（@提及自动补全）
+			student.GET("/reputation/rank", user.GetReputationRank)           // 声望排行榜
+			student.GET("/me", user.GetMe)                                    // 获取个人资料
+			student.PUT("/me", user.UpdateMe)                                 // 修改个人资料
+			student.DELETE("/me", user.DeleteAccount)                         // 注销账号
+			student.POST("/me/export", user.RequestDataExport)                // 申请导出个人数据
+			student.GET("/me/export", user.GetDataExport)                     // 查看个人数据导出状态
+			student.GET("/me/export/:id", user.DownloadDataExport)            // 下载自己的导出文件（同时校验签名链接）
+			student.GET("/me/sessions", user.GetSessions)                     // 查看已登录设备
+			student.DELETE("/me/sessions", user.RevokeSession)                // 退出指定设备
+			student.DELETE("/me/sessions/others", user.RevokeOtherSessions)   // 退出其他所有设备
+			student.POST("/logout", user.Logout)                              // 退出当前设备
+			student.POST("/token/refresh", user.RefreshToken)                 // 刷新token
+			student.PUT("/password", user.ChangePassword)                     // 修改密码
+			student.GET("/2fa", user.GetTwoFactorStatus)                      // 查看两步验证状态
+			student.POST("/2fa/setup", user.SetupTwoFactor)                   // 获取两步验证密钥
+			student.POST("/2fa/enable", user.EnableTwoFactor)                 // 启用两步验证
+			student.POST("/2fa/disable", user.DisableTwoFactor)               // 关闭两步验证
+			student.POST("/2fa/recovery-codes", user.RegenerateRecoveryCodes) // 重新生成恢复码
+			student.GET("/api-token", user.GetAPITokens)                      // 获取访问令牌列表
+			student.POST("/api-token", user.CreateAPIToken)                   // 创建访问令牌
+			student.DELETE("/api-token", user.RevokeAPIToken)                 // 撤销访问令牌
+			student.GET("/users/:id", user.GetUserProfile)                    // 查看用户主页
+
+			student.GET("/feed", post.GetFeed)                    // 获取关注动态
+			student.POST("/follow", follow.Follow)                // 关注用户/版块
+			student.DELETE("/follow", follow.Unfollow)            // 取消关注
+			student.GET("/follow/followers", follow.GetFollowers) // 获取粉丝列表
+			student.GET("/follow/following", follow.GetFollowing) // 获取关注列表
+			student.GET("/follow/count", follow.GetFollowCounts)  // 获取粉丝数和关注数
+
+			student.GET("/bookmark", bookmark.GetBookmarks)           // 获取收藏列表
+			student.POST("/bookmark", bookmark.AddBookmark)           // 收藏帖子
+			student.DELETE("/bookmark", bookmark.RemoveBookmark)      // 取消收藏
+			student.PUT("/bookmark/move", bookmark.MoveBookmark)      // 移动收藏到其他收藏夹
+			student.GET("/bookmark/folder", bookmark.GetFolders)      // 获取收藏夹列表
+			student.POST("/bookmark/folder", bookmark.CreateFolder)   // 创建收藏夹
+			student.PUT("/bookmark/folder", bookmark.RenameFolder)    // 重命名收藏夹
+			student.DELETE("/bookmark/folder", bookmark.DeleteFolder) // 删除收藏夹
 		}
 
 		// 管理员路由 - 需要额外的管理员权限验证
 		adminGroup := auth.Group("/admin")
 		adminGroup.Use(middleware.AdminAuthMiddleware())
 		{
-			adminGroup.GET("/report", admin.GetPendingReports) // 获取待审批举报
-			adminGroup.POST("/report", admin.ApproveReport)    // 审批举报
+			adminGroup.GET("/report", admin.GetPendingReports)                           // 获取待审批举报
+			adminGroup.POST("/report", admin.ApproveReport)                              // 审批举报
+			adminGroup.POST("/report/message", admin.ApproveMessageReport)               // 审批私信举报
+			adminGroup.GET("/report/conversation", admin.GetReportedConversation)        // 查看被举报会话（审计）
+			adminGroup.POST("/announcement", admin.CreateAnnouncement)                   // 发布公告
+			adminGroup.PUT("/announcement/pin", admin.PinAnnouncement)                   // 设置公告置顶
+			adminGroup.GET("/announcement/unread", admin.GetAnnouncementUnread)          // 查看公告未读用户
+			adminGroup.POST("/board", admin.CreateBoard)                                 // 创建版块
+			adminGroup.PUT("/board/anonymous", admin.SetBoardAnonymous)                  // 设置版块是否允许匿名
+			adminGroup.POST("/post/reveal", admin.RevealAnonymousAuthor)                 // 查看匿名帖子作者（审计）
+			adminGroup.GET("/post/pending", admin.GetPendingPosts)                       // 获取待审核帖子（先审后发）
+			adminGroup.POST("/post/review", admin.ReviewPost)                            // 审核帖子
+			adminGroup.POST("/user/password-reset", admin.IssuePasswordReset)            // 签发密码重置凭证
+			adminGroup.POST("/user/import", admin.ImportRoster)                          // 批量导入名单
+			adminGroup.GET("/user/import/result", admin.DownloadRosterResult)            // 下载名单导入结果
+			adminGroup.GET("/login/lockout", admin.GetLoginLockouts)                     // 查看登录锁定
+			adminGroup.DELETE("/login/lockout", admin.ClearLoginLockout)                 // 解除登录锁定
+			adminGroup.GET("/service-account", admin.GetServiceAccounts)                 // 获取服务账号列表
+			adminGroup.POST("/service-account", admin.CreateServiceAccount)              // 创建服务账号
+			adminGroup.GET("/service-account/token", admin.GetServiceAccountTokens)      // 获取服务账号令牌
+			adminGroup.POST("/service-account/token", admin.CreateServiceAccountToken)   // 为服务账号创建令牌
+			adminGroup.DELETE("/service-account/token", admin.RevokeServiceAccountToken) // 撤销服务账号令牌
+
+			adminGroup.GET("/webhook", admin.GetWebhooks)            // 获取Webhook列表
+			adminGroup.POST("/webhook", admin.CreateWebhook)         // 创建Webhook
+			adminGroup.PUT("/webhook", admin.UpdateWebhook)          // 修改Webhook
+			adminGroup.DELETE("/webhook", admin.DeleteWebhook)       // 删除Webhook
+			adminGroup.GET("/webhook/dead", admin.GetDeadDeliveries) // 查看死信投递
+			adminGroup.POST("/webhook/replay", admin.ReplayDelivery) // 重放死信投递
 		}
 	}
 }
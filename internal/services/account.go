@@ -0,0 +1,293 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	closedAccountPrefix  = "deleted_" // 注销后用户名的前缀，加上12位随机十六进制正好等于用户名长度上限
+	closedPostPseudonym  = "已注销用户"    // 注销后匿名化保留的帖子显示的作者化名
+	auditPseudonymLength = 12
+)
+
+// newClosedUsername 随机生成注销账号的化名，与学号无关，无法由学号反查；
+// 同一账号在账号记录和所有审计记录中使用同一个化名
+func newClosedUsername() (string, error) {
+	suffix, err := randomHex(auditPseudonymLength / 2)
+	if err != nil {
+		return "", err
+	}
+	return closedAccountPrefix + suffix, nil
+}
+
+// pseudonymizeAuditLogs 将与该账号相关（操作人或操作对象为该账号）的审计记录中出现的学号替换为化名；
+// 只替换完整的 JSON 字符串值，避免误改数字字段。姓名可能重名，不做替换以免改动其他用户的记录。
+// 审计记录本身需要保留，不删除
+func pseudonymizeAuditLogs(tx *gorm.DB, user *models.User, pseudonym string) error {
+	quoted := `"` + user.Username + `"`
+	var logs []models.AuditLog
+	err := tx.Where("(target_id = ? OR admin_id = ?) AND detail LIKE ?", user.ID, user.ID, "%"+quoted+"%").
+		Find(&logs).Error
+	if err != nil {
+		return err
+	}
+	for _, entry := range logs {
+		detail := strings.ReplaceAll(entry.Detail, quoted, `"`+pseudonym+`"`)
+		if detail == entry.Detail {
+			continue
+		}
+		if err := tx.Model(&models.AuditLog{}).Where("id = ?", entry.ID).Update("detail", detail).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountDeletion 注销过程中需要在事务提交后处理的数据
+type accountDeletion struct {
+	likedPostIDs   []uint        // 用户点赞过且未被删除的帖子，需要修正点赞数和作者声望
+	removedPosts   []models.Post // 被删除的帖子
+	postIDs        []uint        // 用户发布的全部帖子，需要从粉丝时间线中移除
+	followerIDs    []uint
+	anonymizedPost int64
+}
+
+// DeleteAccount 注销账号：校验密码（已启用两步验证时还需验证码）后，删除点赞、关注、收藏、通知等个人数据，
+// 已发布的帖子按配置匿名化保留或删除，草稿和未发布的帖子一律删除；账号记录保留但清除身份信息，
+// 相关审计记录中的学号替换为化名。管理员和服务账号不能自助注销
+func DeleteAccount(userID uint, password, code string) *models.ServiceError {
+	user, err := GetUserByID(userID)
+	if err != nil || user.IsClosed() {
+		return &models.ServiceError{Code: 1501, Message: "用户不存在"}
+	}
+	if user.UserType == models.AdminRole || user.IsServiceAccount {
+		return &models.ServiceError{Code: 1506, Message: "管理员和服务账号不能自助注销，请联系其他管理员处理"}
+	}
+	if !user.CheckPasswordHash(password) {
+		return &models.ServiceError{Code: 1507, Message: "密码错误"}
+	}
+	if user.TOTPEnabled {
+		if serviceErr := verifySecondFactor(user, code); serviceErr != nil {
+			return serviceErr
+		}
+	}
+
+	policy := config.LoadedConfig.Privacy.DeletedContentPolicy
+	if policy != models.DeletedContentDelete {
+		policy = models.DeletedContentAnonymize
+	}
+	random, err := randomHex(passwordResetTokenBytes)
+	if err != nil {
+		return &models.ServiceError{Code: 1508, Message: "注销失败"}
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return &models.ServiceError{Code: 1508, Message: "注销失败"}
+	}
+	pseudonym, err := newClosedUsername()
+	if err != nil {
+		return &models.ServiceError{Code: 1508, Message: "注销失败"}
+	}
+
+	var d accountDeletion
+	d.followerIDs, _ = getFollowerIDs(userID)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var posts []models.Post
+		if err := tx.Where("user_id = ?", userID).Find(&posts).Error; err != nil {
+			return err
+		}
+		var anonymizeIDs, removeIDs []uint
+		removed := make(map[uint]bool)
+		for _, post := range posts {
+			d.postIDs = append(d.postIDs, post.ID)
+			// 草稿、定时和待审核的帖子从未公开，直接删除
+			if post.Status == models.PostStatusPublished && policy == models.DeletedContentAnonymize {
+				anonymizeIDs = append(anonymizeIDs, post.ID)
+				continue
+			}
+			removeIDs = append(removeIDs, post.ID)
+			removed[post.ID] = true
+			d.removedPosts = append(d.removedPosts, post)
+		}
+
+		if len(anonymizeIDs) > 0 {
+			result := tx.Model(&models.Post{}).Where("id IN ?", anonymizeIDs).Updates(map[string]interface{}{
+				"is_anonymous": true,
+				"pseudonym":    closedPostPseudonym,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			d.anonymizedPost = result.RowsAffected
+		}
+		if len(removeIDs) > 0 {
+			if err := tx.Where("post_id IN ?", removeIDs).Delete(&models.Like{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", removeIDs).Delete(&models.Post{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var likedPostIDs []uint
+		if err := tx.Model(&models.Like{}).Where("user_id = ?", userID).Pluck("post_id", &likedPostIDs).Error; err != nil {
+			return err
+		}
+		for _, postID := range likedPostIDs {
+			if !removed[postID] {
+				d.likedPostIDs = append(d.likedPostIDs, postID)
+			}
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Like{}).Error; err != nil {
+			return err
+		}
+
+		// 关注、屏蔽关系双向删除；投票记录保留以免改变投票结果，私信和举报记录由对方和管理员保留
+		cleanups := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&models.Follow{}, "user_id = ? OR (target_type = ? AND target_id = ?)", []interface{}{userID, models.FollowTargetUser, userID}},
+			{&models.UserBlock{}, "user_id = ? OR blocked_user_id = ?", []interface{}{userID, userID}},
+			{&models.Bookmark{}, "user_id = ?", []interface{}{userID}},
+			{&models.BookmarkFolder{}, "user_id = ?", []interface{}{userID}},
+			{&models.Notification{}, "user_id = ?", []interface{}{userID}},
+			{&models.NotificationMute{}, "user_id = ?", []interface{}{userID}},
+			{&models.Mention{}, "user_id = ?", []interface{}{userID}},
+			{&models.AnnouncementRead{}, "user_id = ?", []interface{}{userID}},
+			{&models.APIToken{}, "user_id = ?", []interface{}{userID}},
+			{&models.RecoveryCode{}, "user_id = ?", []interface{}{userID}},
+			{&models.PasswordResetToken{}, "user_id = ?", []interface{}{userID}},
+		}
+		for _, c := range cleanups {
+			if err := tx.Where(c.query, c.args...).Delete(c.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// 账号记录被帖子、私信和审计记录引用，保留记录但清除身份信息；随机密码使其无法再登录，
+		// 头像附件解除引用后由孤立附件清理任务删除
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":           pseudonym,
+			"password":           string(hashed),
+			"name":               "",
+			"bio":                "",
+			"avatar_id":          0,
+			"class_name":         "",
+			"department":         "",
+			"profile_visibility": models.ProfileVisibilityPublic,
+			"totp_secret":        "",
+			"totp_enabled":       false,
+			"totp_last_step":     0,
+			"oidc_subject":       nil,
+			"closed_at":          time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := pseudonymizeAuditLogs(tx, user, pseudonym); err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  userID,
+			Action:   "delete_account",
+			TargetID: userID,
+			Detail: fmt.Sprintf(`{"pseudonym": %q, "policy": %q, "posts_anonymized": %d, "posts_deleted": %d, "likes_deleted": %d}`,
+				pseudonym, policy, d.anonymizedPost, len(d.removedPosts), len(likedPostIDs)),
+		}).Error
+	})
+	if err != nil {
+		return &models.ServiceError{Code: 1508, Message: "注销失败: " + err.Error()}
+	}
+
+	RevokeAllSessions(userID)
+	finishAccountDeletion(userID, d)
+	deleteUserDataExports(userID)
+	logger.GetLogger().Infof("用户注销账号: user_id=%d, pseudonym=%s, policy=%s, posts_anonymized=%d, posts_deleted=%d",
+		userID, pseudonym, policy, d.anonymizedPost, len(d.removedPosts))
+	return nil
+}
+
+// finishAccountDeletion 事务提交后修正 Redis 中的点赞数、点赞排行和声望排行，并清理用户相关的缓存；
+// 失败只记录日志，点赞数缓存会在下次同步或过期后按数据库重建
+func finishAccountDeletion(userID uint, d accountDeletion) {
+	ctx := context.Background()
+	uid := strconv.Itoa(int(userID))
+
+	_, err := redis.RedisClient.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, postID := range d.likedPostIDs {
+			postIDStr := strconv.Itoa(int(postID))
+			// 删除点赞数缓存而不是减1，缓存不存在时 DECR 会写入负数
+			pipe.Del(ctx, postLikesKey+postIDStr)
+			pipe.ZIncrBy(ctx, likesRankKey, -1, postIDStr)
+			pipe.SAdd(ctx, updatedPostsKey, postIDStr)
+		}
+		for _, post := range d.removedPosts {
+			postIDStr := strconv.Itoa(int(post.ID))
+			pipe.Del(ctx, postLikesKey+postIDStr)
+			pipe.ZRem(ctx, likesRankKey, postIDStr)
+		}
+		if len(d.likedPostIDs) > 0 {
+			pipe.Expire(ctx, updatedPostsKey, 60*time.Minute)
+		}
+		pipe.Del(ctx, userLikesKey+uid, timelineKeyOf(userID), unreadCountKey+uid)
+		return nil
+	})
+	if err != nil {
+		logger.GetLogger().Errorf("注销账号后修正点赞缓存失败: user_id=%d, err=%v", userID, err)
+	}
+
+	// 撤回点赞给帖子作者带来的声望，被删除的帖子撤回被采纳回答的声望
+	for _, postID := range d.likedPostIDs {
+		onLikeToggled(postID, userID, false)
+	}
+	for _, post := range d.removedPosts {
+		if post.Status != models.PostStatusPublished {
+			continue
+		}
+		onAnswerDeleted(post)
+		onPostRemoved(post, 0, false)
+	}
+
+	// 声望清零并移出排行榜，需在上面的声望调整之后执行
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("reputation", 0).Error; err != nil {
+		logger.GetLogger().Errorf("注销账号后清除声望失败: user_id=%d, err=%v", userID, err)
+	}
+	if err := redis.RedisClient.ZRem(ctx, reputationRankKey, uid).Err(); err != nil {
+		logger.GetLogger().Errorf("注销账号后移出声望排行榜失败: user_id=%d, err=%v", userID, err)
+	}
+
+	// 从原粉丝的时间线中移除该用户的帖子
+	if len(d.postIDs) > 0 && len(d.followerIDs) > 0 {
+		members := make([]interface{}, 0, len(d.postIDs))
+		for _, id := range d.postIDs {
+			members = append(members, id)
+		}
+		for start := 0; start < len(d.followerIDs); start += fanoutBatchSize {
+			end := min(start+fanoutBatchSize, len(d.followerIDs))
+			pipe := redis.RedisClient.Pipeline()
+			for _, followerID := range d.followerIDs[start:end] {
+				pipe.ZRem(ctx, timelineKeyOf(followerID), members...)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				logger.GetLogger().Errorf("注销账号后清理粉丝时间线失败: user_id=%d, err=%v", userID, err)
+				break
+			}
+		}
+	}
+}
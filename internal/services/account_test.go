@@ -0,0 +1,47 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizeAuditLogsOnlyTouchesOwnRecords(t *testing.T) {
+	setupTestConfig(t)
+	setupTestDB(t, &models.User{}, &models.AuditLog{})
+
+	user := models.User{ID: 7, Username: "2023001", Name: "张三"}
+	logs := []models.AuditLog{
+		{AdminID: 1, Action: "reset_password", TargetID: 7, Detail: `{"username": "2023001", "name": "张三"}`},
+		{AdminID: 7, Action: "create_api_token", TargetID: 30, Detail: `{"by": "2023001"}`},
+		// 其他账号的记录，即使同名或包含相同字符串也不修改
+		{AdminID: 1, Action: "reset_password", TargetID: 8, Detail: `{"username": "2023002", "name": "张三"}`},
+		{AdminID: 1, Action: "import_users", TargetID: 0, Detail: `{"note": "2023001"}`},
+	}
+	for i := range logs {
+		database.DB.Create(&logs[i])
+	}
+
+	pseudonym, err := newClosedUsername()
+	if err != nil || !strings.HasPrefix(pseudonym, closedAccountPrefix) || len(pseudonym) != len(closedAccountPrefix)+auditPseudonymLength {
+		t.Fatalf("化名格式错误: %q, %v", pseudonym, err)
+	}
+	if err := pseudonymizeAuditLogs(database.DB, &user, pseudonym); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`{"username": "` + pseudonym + `", "name": "张三"}`,
+		`{"by": "` + pseudonym + `"}`,
+		logs[2].Detail,
+		logs[3].Detail,
+	}
+	for i, entry := range logs {
+		var got models.AuditLog
+		database.DB.First(&got, entry.ID)
+		if got.Detail != want[i] {
+			t.Errorf("第%d条审计记录: got %s, want %s", i+1, got.Detail, want[i])
+		}
+	}
+}
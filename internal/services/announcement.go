@@ -0,0 +1,126 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/markdown"
+	"time"
+)
+
+// CreateAnnouncement 管理员发布公告帖子，全站公告同时给所有用户发送通知
+func CreateAnnouncement(adminID uint, content string, boardID uint, pinnedUntil *time.Time, mustAcknowledge bool) (*models.Post, *models.ServiceError) {
+	if !BoardExists(boardID) {
+		return nil, &models.ServiceError{Code: 1001, Message: "版块不存在"}
+	}
+	if pinnedUntil != nil && !pinnedUntil.After(time.Now()) {
+		return nil, &models.ServiceError{Code: 1002, Message: "置顶截止时间必须晚于当前时间"}
+	}
+
+	post := models.Post{
+		Content:         content,
+		ContentHTML:     markdown.Render(content),
+		UserID:          adminID,
+		BoardID:         boardID,
+		PostTime:        time.Now(),
+		IsAnnouncement:  true,
+		PinnedUntil:     pinnedUntil,
+		MustAcknowledge: mustAcknowledge,
+	}
+	if err := database.DB.Create(&post).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "发布公告失败: " + err.Error()}
+	}
+
+	onPostPublished(post)
+
+	if boardID == 0 {
+		if count, serviceErr := SendAnnouncement(content, post.ID); serviceErr != nil {
+			logger.GetLogger().Errorf("发送公告通知失败: post_id=%d, sent=%d, error=%v", post.ID, count, serviceErr)
+		}
+	}
+	return &post, nil
+}
+
+// getAnnouncement 获取公告帖子
+func getAnnouncement(postID uint) (*models.Post, *models.ServiceError) {
+	post, err := GetPostByID(postID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1004, Message: "公告不存在"}
+	}
+	if !post.IsAnnouncement {
+		return nil, &models.ServiceError{Code: 1005, Message: "该帖子不是公告"}
+	}
+	return &post, nil
+}
+
+// SetAnnouncementPin 设置或取消公告置顶，pinnedUntil 为空时取消置顶
+func SetAnnouncementPin(postID uint, pinnedUntil *time.Time) *models.ServiceError {
+	post, serviceErr := getAnnouncement(postID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	if pinnedUntil != nil && !pinnedUntil.After(time.Now()) {
+		return &models.ServiceError{Code: 1002, Message: "置顶截止时间必须晚于当前时间"}
+	}
+	if err := database.DB.Model(post).Update("pinned_until", pinnedUntil).Error; err != nil {
+		return &models.ServiceError{Code: 1006, Message: "设置置顶失败: " + err.Error()}
+	}
+	return nil
+}
+
+// AcknowledgeAnnouncement 用户确认已读公告，重复确认不报错
+func AcknowledgeAnnouncement(postID, userID uint) *models.ServiceError {
+	if _, serviceErr := getAnnouncement(postID); serviceErr != nil {
+		return serviceErr
+	}
+	err := database.DB.Where(models.AnnouncementRead{PostID: postID, UserID: userID}).
+		FirstOrCreate(&models.AnnouncementRead{}).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1006, Message: "确认公告失败: " + err.Error()}
+	}
+	return nil
+}
+
+// IsAnnouncementAcknowledged 判断用户是否已确认公告
+func IsAnnouncementAcknowledged(postID, userID uint) bool {
+	var count int64
+	database.DB.Model(&models.AnnouncementRead{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&count)
+	return count > 0
+}
+
+// GetAnnouncementUnreadUsers 管理员查看尚未确认公告的用户，同时返回已确认人数
+func GetAnnouncementUnreadUsers(postID uint, page, pageSize int) ([]map[string]interface{}, int64, int64, *models.ServiceError) {
+	post, serviceErr := getAnnouncement(postID)
+	if serviceErr != nil {
+		return nil, 0, 0, serviceErr
+	}
+
+	readQuery := database.DB.Model(&models.AnnouncementRead{}).Where("post_id = ?", post.ID)
+	var readCount int64
+	if err := readQuery.Count(&readCount).Error; err != nil {
+		return nil, 0, 0, &models.ServiceError{Code: 1007, Message: "统计已读人数失败: " + err.Error()}
+	}
+
+	query := database.DB.Model(&models.User{}).
+		Where("id NOT IN (?)", database.DB.Model(&models.AnnouncementRead{}).Select("user_id").Where("post_id = ?", post.ID))
+	var unreadCount int64
+	if err := query.Count(&unreadCount).Error; err != nil {
+		return nil, 0, 0, &models.ServiceError{Code: 1008, Message: "统计未读人数失败: " + err.Error()}
+	}
+
+	var users []models.User
+	err := query.Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error
+	if err != nil {
+		return nil, 0, 0, &models.ServiceError{Code: 1009, Message: "获取未读用户失败: " + err.Error()}
+	}
+
+	list := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		list = append(list, map[string]interface{}{
+			"user_id":  u.ID,
+			"username": u.Username,
+			"name":     u.Name,
+		})
+	}
+	return list, unreadCount, readCount, nil
+}
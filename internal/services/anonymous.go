@@ -0,0 +1,114 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redis 键名定义
+const (
+	anonymousRateKey    = "post:anon:rate:" // 用户匿名发帖计数：string类型，24小时过期
+	anonymousRateWindow = 24 * time.Hour
+
+	minRevealReasonLength = 5 // 揭示匿名作者时理由的最少字符数
+)
+
+// checkAnonymousAllowed 校验版块是否允许匿名发帖，并按用户限制匿名发帖频率。
+// 通过时占用一次匿名发帖名额（原子计数，避免并发请求超出限额），发帖失败时需调用 releaseAnonymousQuota 归还
+func checkAnonymousAllowed(userID, boardID uint) *models.ServiceError {
+	if boardID == 0 {
+		return &models.ServiceError{Code: 1301, Message: "匿名帖子必须发布在允许匿名的版块"}
+	}
+	var board models.Board
+	if err := database.DB.First(&board, boardID).Error; err != nil {
+		return &models.ServiceError{Code: 1302, Message: "版块不存在"}
+	}
+	if !board.AllowAnonymous {
+		return &models.ServiceError{Code: 1303, Message: "该版块不允许匿名发帖"}
+	}
+
+	ctx := context.Background()
+	key := anonymousRateKey + strconv.Itoa(int(userID))
+	count, err := redis.RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return &models.ServiceError{Code: 1304, Message: "匿名发帖失败，请稍后重试"}
+	}
+	if count == 1 {
+		redis.RedisClient.Expire(ctx, key, anonymousRateWindow)
+	}
+	if count > int64(config.LoadedConfig.Post.AnonymousPerDay) {
+		releaseAnonymousQuota(userID)
+		return &models.ServiceError{Code: 1305, Message: "匿名发帖过于频繁，请稍后再试"}
+	}
+	return nil
+}
+
+// releaseAnonymousQuota 归还 checkAnonymousAllowed 占用的匿名发帖名额
+func releaseAnonymousQuota(userID uint) {
+	redis.RedisClient.Decr(context.Background(), anonymousRateKey+strconv.Itoa(int(userID)))
+}
+
+// newPseudonym 为匿名帖子随机生成作者化名，创建时保存在帖子上；与作者无关，无法从化名反推作者
+func newPseudonym() (string, error) {
+	suffix, err := randomHex(3)
+	if err != nil {
+		return "", err
+	}
+	return "匿名用户#" + strings.ToUpper(suffix), nil
+}
+
+// RevealAnonymousAuthor 管理员查看匿名帖子的真实作者，必须填写理由并记录审计日志
+func RevealAnonymousAuthor(adminID, postID uint, reason string) (*models.User, *models.ServiceError) {
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) < minRevealReasonLength {
+		return nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("请填写至少%d个字的理由", minRevealReasonLength)}
+	}
+
+	post, err := GetPostByID(postID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "帖子不存在"}
+	}
+	if !post.IsAnonymous {
+		return nil, &models.ServiceError{Code: 1003, Message: "该帖子不是匿名帖子"}
+	}
+
+	// 先写审计日志，写入失败时不揭示作者
+	detail, _ := json.Marshal(map[string]interface{}{
+		"post_id": postID,
+		"reason":  reason,
+	})
+	auditLog := models.AuditLog{
+		AdminID:  adminID,
+		Action:   "reveal_anonymous_author",
+		TargetID: postID,
+		Detail:   string(detail),
+	}
+	if err := database.DB.Create(&auditLog).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1011, Message: "记录审计日志失败"}
+	}
+
+	author, err := GetUserByID(post.UserID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1004, Message: "作者不存在"}
+	}
+	return author, nil
+}
+
+// SetBoardAnonymous 设置版块是否允许匿名发帖
+func SetBoardAnonymous(boardID uint, allow bool) *models.ServiceError {
+	if boardID == 0 || !BoardExists(boardID) {
+		return &models.ServiceError{Code: 1001, Message: "版块不存在"}
+	}
+	if err := database.DB.Model(&models.Board{}).Where("id = ?", boardID).Update("allow_anonymous", allow).Error; err != nil {
+		return &models.ServiceError{Code: 1002, Message: "设置失败: " + err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAnonymousQuotaOnlyCountsCreatedPosts(t *testing.T) {
+	cfg := setupTestConfig(t)
+	cfg.Post.AnonymousPerDay = 2
+	mr := setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.Board{}, &models.Post{}, &models.Attachment{},
+		&models.Mention{}, &models.Webhook{}, &models.WebhookDelivery{})
+
+	author := models.User{Username: "author", Password: "x", UserType: models.StudentRole}
+	database.DB.Create(&author)
+	board := models.Board{Name: "树洞", AllowAnonymous: true}
+	database.DB.Create(&board)
+
+	newPost := func() *models.Post {
+		return &models.Post{Content: "匿名内容", BoardID: board.ID, IsAnonymous: true}
+	}
+
+	// 附件不存在导致发帖失败，不占用名额
+	for i := 0; i < 3; i++ {
+		if err := CreatePost(author.ID, newPost(), []uint{999}, nil); err == nil {
+			t.Fatal("附件不存在时应发帖失败")
+		}
+	}
+	for i := 0; i < cfg.Post.AnonymousPerDay; i++ {
+		if err := CreatePost(author.ID, newPost(), nil, nil); err != nil {
+			t.Fatalf("第%d个匿名帖子应发布成功: %v", i+1, err)
+		}
+	}
+
+	// 超出限额被拒绝的请求同样不占用名额，名额用完后持续拒绝
+	for i := 0; i < 2; i++ {
+		err := CreatePost(author.ID, newPost(), nil, nil)
+		if serviceErr, ok := err.(*models.ServiceError); !ok || serviceErr.Code != 1305 {
+			t.Fatalf("超出限额应返回1305: %v", err)
+		}
+	}
+	if got, _ := mr.Get(anonymousRateKey + strconv.Itoa(int(author.ID))); got != strconv.Itoa(cfg.Post.AnonymousPerDay) {
+		t.Fatalf("计数应等于已发布的匿名帖子数，实际 %s", got)
+	}
+	var count int64
+	database.DB.Model(&models.Post{}).Where("is_anonymous = ?", true).Count(&count)
+	if count != int64(cfg.Post.AnonymousPerDay) {
+		t.Fatalf("应只创建 %d 个匿名帖子，实际 %d", cfg.Post.AnonymousPerDay, count)
+	}
+
+	// 化名随机生成并保存在帖子上
+	var pseudonyms []string
+	database.DB.Model(&models.Post{}).Where("is_anonymous = ?", true).Pluck("pseudonym", &pseudonyms)
+	for _, p := range pseudonyms {
+		if !strings.HasPrefix(p, "匿名用户#") || len(p) != len("匿名用户#")+6 {
+			t.Fatalf("化名格式错误: %q", p)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	apiTokenBytes         = 32          // 令牌随机字节数
+	apiTokenHintLen       = 4           // 展示给用户辨认的令牌末尾字符数
+	maxAPITokensPerUser   = 20          // 每个用户最多持有的令牌数
+	maxAPITokenDays       = 365         // 令牌有效期上限（天）
+	maxAPITokenNameLength = 100         // 令牌名称最大字符数
+	apiTokenTouchInterval = time.Minute // 最近使用时间的更新间隔，避免每次请求都写库
+)
+
+// ErrAPITokenInvalid 访问令牌无效、已过期或已撤销
+var ErrAPITokenInvalid = errors.New("invalid api token")
+
+// normalizeScopes 校验并去重权限范围；reports:review 只能授予管理员
+func normalizeScopes(scopes []string, userType int) ([]string, *models.ServiceError) {
+	if len(scopes) == 0 {
+		return nil, &models.ServiceError{Code: 1001, Message: "至少选择一个权限范围"}
+	}
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		valid := false
+		for _, s := range models.APITokenScopes {
+			if s == scope {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, &models.ServiceError{Code: 1001, Message: "无效的权限范围: " + scope}
+		}
+		if scope == models.ScopeReportsReview && userType != models.AdminRole {
+			return nil, &models.ServiceError{Code: 1002, Message: "只有管理员可以申请 " + scope + " 权限"}
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	return result, nil
+}
+
+// CreateAPIToken 为 userID 创建个人访问令牌，creatorID 为操作人（管理员为服务账号创建时与 userID 不同）；
+// 令牌明文只在此时返回一次，expiresInDays 为 0 表示永不过期
+func CreateAPIToken(creatorID, userID uint, name string, scopes []string, expiresInDays int) (string, *models.APIToken, *models.ServiceError) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxAPITokenNameLength {
+		return "", nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("令牌名称不能为空且不能超过%d个字符", maxAPITokenNameLength)}
+	}
+	if expiresInDays < 0 || expiresInDays > maxAPITokenDays {
+		return "", nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("有效期需为0-%d天", maxAPITokenDays)}
+	}
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return "", nil, &models.ServiceError{Code: 1003, Message: "用户不存在"}
+	}
+	scopes, serviceErr := normalizeScopes(scopes, user.UserType)
+	if serviceErr != nil {
+		return "", nil, serviceErr
+	}
+
+	var count int64
+	database.DB.Model(&models.APIToken{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxAPITokensPerUser {
+		return "", nil, &models.ServiceError{Code: 1004, Message: fmt.Sprintf("每个账号最多创建%d个访问令牌", maxAPITokensPerUser)}
+	}
+
+	random, err := randomHex(apiTokenBytes)
+	if err != nil {
+		return "", nil, &models.ServiceError{Code: 1005, Message: "生成访问令牌失败"}
+	}
+	raw := models.APITokenPrefix + random
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: sha256Hex(raw),
+		Hint:      raw[len(raw)-apiTokenHintLen:],
+		Scopes:    strings.Join(scopes, ","),
+		CreatedBy: creatorID,
+	}
+	if expiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&token).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  creatorID,
+			Action:   "create_api_token",
+			TargetID: userID,
+			Detail:   fmt.Sprintf(`{"token_id": %d, "name": %q, "scopes": %q}`, token.ID, token.Name, token.Scopes),
+		}).Error
+	})
+	if err != nil {
+		return "", nil, &models.ServiceError{Code: 1005, Message: "创建访问令牌失败: " + err.Error()}
+	}
+	return raw, &token, nil
+}
+
+// GetAPITokens 获取用户的访问令牌列表
+func GetAPITokens(userID uint) ([]models.APITokenResponse, error) {
+	var tokens []models.APIToken
+	if err := database.DB.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	list := make([]models.APITokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, token.ToResponse())
+	}
+	return list, nil
+}
+
+// RevokeAPIToken 撤销 userID 名下的访问令牌，actorID 为操作人
+func RevokeAPIToken(actorID, userID, tokenID uint) *models.ServiceError {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.APIToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &models.ServiceError{Code: 1001, Message: "访问令牌不存在"}
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  actorID,
+			Action:   "revoke_api_token",
+			TargetID: userID,
+			Detail:   fmt.Sprintf(`{"token_id": %d}`, tokenID),
+		}).Error
+	})
+	if err != nil {
+		if serviceErr, ok := err.(*models.ServiceError); ok {
+			return serviceErr
+		}
+		return &models.ServiceError{Code: 1002, Message: "撤销访问令牌失败: " + err.Error()}
+	}
+	return nil
+}
+
+// AuthenticateAPIToken 校验访问令牌并返回所属用户，同时按间隔记录最近使用时间和 IP
+func AuthenticateAPIToken(raw, ip string) (*models.User, *models.APIToken, error) {
+	var token models.APIToken
+	if err := database.DB.Where("token_hash = ?", sha256Hex(raw)).First(&token).Error; err != nil {
+		return nil, nil, ErrAPITokenInvalid
+	}
+	now := time.Now()
+	if token.Expired(now) {
+		return nil, nil, ErrAPITokenInvalid
+	}
+	user, err := GetUserByID(token.UserID)
+	if err != nil {
+		return nil, nil, ErrAPITokenInvalid
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != ip {
+		database.DB.Model(&models.APIToken{}).Where("id = ?", token.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		})
+		token.LastUsedAt = &now
+		token.LastUsedIP = ip
+	}
+	return user, &token, nil
+}
+
+// CreateServiceAccount 管理员创建服务账号；服务账号使用随机密码且不能登录，只能通过管理员为其创建的访问令牌调用接口
+func CreateServiceAccount(adminID uint, username, name string, userType int) (*models.User, *models.ServiceError) {
+	username = strings.TrimSpace(username)
+	if username == "" || len(username) > maxUsernameLen {
+		return nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("账号不能为空且不能超过%d个字符", maxUsernameLen)}
+	}
+	if userType != models.StudentRole && userType != models.AdminRole {
+		return nil, &models.ServiceError{Code: 1001, Message: "无效的用户类型"}
+	}
+	if _, err := GetUserByUsername(username); err == nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "账号已存在"}
+	}
+
+	random, err := randomHex(passwordResetTokenBytes)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "创建服务账号失败"}
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "创建服务账号失败"}
+	}
+	account := models.User{
+		Username:         username,
+		Password:         string(hashed),
+		Name:             strings.TrimSpace(name),
+		UserType:         userType,
+		IsServiceAccount: true,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&account).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  adminID,
+			Action:   "create_service_account",
+			TargetID: account.ID,
+			Detail:   fmt.Sprintf(`{"username": %q, "user_type": %d}`, username, userType),
+		}).Error
+	})
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "创建服务账号失败: " + err.Error()}
+	}
+	return &account, nil
+}
+
+// GetServiceAccounts 获取所有服务账号
+func GetServiceAccounts() ([]models.User, error) {
+	var accounts []models.User
+	err := database.DB.Where("is_service_account = ?", true).Order("id DESC").Find(&accounts).Error
+	return accounts, err
+}
+
+// GetServiceAccount 获取服务账号，不是服务账号时返回错误
+func GetServiceAccount(userID uint) (*models.User, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil || !user.IsServiceAccount {
+		return nil, &models.ServiceError{Code: 1001, Message: "服务账号不存在"}
+	}
+	return user, nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/storage"
+	"CMS/pkg/utils"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	_ "image/png" // 注册 PNG 解码器
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AttachmentVariantOriginal  = ""      // 原文件
+	AttachmentVariantThumbnail = "thumb" // 缩略图
+
+	maxAttachmentsPerPost = 9
+	maxThumbnailPixels    = 40_000_000 // 超过该像素数的图片不生成缩略图，防止解压炸弹
+)
+
+// allowedAttachmentTypes 允许上传的文件类型（以服务端嗅探结果为准）及保存时使用的扩展名
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":                ".jpg",
+	"image/png":                 ".png",
+	"image/gif":                 ".gif",
+	"image/webp":                ".webp",
+	"application/pdf":           ".pdf",
+	"application/zip":           ".zip",
+	"text/plain; charset=utf-8": ".txt",
+}
+
+// thumbnailTypes 可以用标准库解码并生成缩略图的图片类型
+var thumbnailTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+func randomKey() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// UploadLimit 根据用户角色返回单个文件的大小上限
+func UploadLimit(isAdmin bool) int64 {
+	if isAdmin {
+		return config.LoadedConfig.Storage.AdminMaxBytes
+	}
+	return config.LoadedConfig.Storage.StudentMaxBytes
+}
+
+// UploadAttachment 保存上传的文件，图片同时生成缩略图；附件在关联帖子前处于孤立状态
+func UploadAttachment(userID uint, isAdmin bool, file *multipart.FileHeader) (*models.Attachment, *models.ServiceError) {
+	limit := UploadLimit(isAdmin)
+	if file.Size > limit {
+		return nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("文件大小不能超过%dMB", limit>>20)}
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "读取文件失败"}
+	}
+	defer f.Close()
+
+	// 根据文件内容嗅探类型，不信任客户端提供的 Content-Type 和扩展名
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, &models.ServiceError{Code: 1002, Message: "读取文件失败"}
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, &models.ServiceError{Code: 1003, Message: "不支持的文件类型: " + contentType}
+	}
+
+	key := time.Now().Format("2006/01/02/") + randomKey() + ext
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "读取文件失败"}
+	}
+	if err := storage.Store.Save(key, io.LimitReader(f, limit)); err != nil {
+		return nil, &models.ServiceError{Code: 1004, Message: "保存文件失败: " + err.Error()}
+	}
+
+	attachment := models.Attachment{
+		UserID:      userID,
+		FileName:    filepath.Base(file.Filename),
+		ContentType: contentType,
+		Size:        file.Size,
+		StorageKey:  key,
+	}
+
+	if thumbnailTypes[contentType] {
+		if thumbKey, err := saveThumbnail(f, key); err != nil {
+			logger.GetLogger().Errorf("生成缩略图失败: key=%s, err=%v", key, err)
+		} else {
+			attachment.ThumbnailKey = thumbKey
+		}
+	}
+
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		removeAttachmentFiles(attachment)
+		return nil, &models.ServiceError{Code: 1005, Message: "保存附件信息失败: " + err.Error()}
+	}
+	return &attachment, nil
+}
+
+// saveThumbnail 生成 JPEG 缩略图并保存，返回缩略图的 key
+func saveThumbnail(f multipart.File, key string) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Width*cfg.Height > maxThumbnailPixels {
+		return "", fmt.Errorf("图片尺寸过大: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	thumb := utils.MakeThumbnail(img, config.LoadedConfig.Storage.ThumbnailSize)
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80}); err != nil {
+		return "", err
+	}
+
+	thumbKey := key[:len(key)-len(filepath.Ext(key))] + "_thumb.jpg"
+	if err := storage.Store.Save(thumbKey, &buf); err != nil {
+		return "", err
+	}
+	return thumbKey, nil
+}
+
+// AttachToPost 将用户上传的孤立附件关联到帖子，需在创建帖子的事务中调用
+func AttachToPost(tx *gorm.DB, postID, userID uint, attachmentIDs []uint) *models.ServiceError {
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+	if len(attachmentIDs) > maxAttachmentsPerPost {
+		return &models.ServiceError{Code: 1006, Message: fmt.Sprintf("每个帖子最多%d个附件", maxAttachmentsPerPost)}
+	}
+	result := tx.Model(&models.Attachment{}).
+		Where("id IN ? AND user_id = ? AND post_id = 0", attachmentIDs, userID).
+		Update("post_id", postID)
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1007, Message: "关联附件失败: " + result.Error.Error()}
+	}
+	if int(result.RowsAffected) != len(attachmentIDs) {
+		return &models.ServiceError{Code: 1008, Message: "部分附件不存在或已被使用"}
+	}
+	return nil
+}
+
+// fileSigningKey 文件访问链接的签名密钥，启动时已校验为单独配置的随机密钥
+func fileSigningKey() []byte {
+	return []byte(config.LoadedConfig.Storage.SigningKey)
+}
+
+// signAttachment 计算附件访问签名
+func signAttachment(id uint, variant string, expires int64) string {
+	mac := hmac.New(sha256.New, fileSigningKey())
+	fmt.Fprintf(mac, "%d:%s:%d", id, variant, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AttachmentURL 生成带签名的限时访问链接
+func AttachmentURL(id uint, variant string) string {
+	expires := time.Now().Add(time.Duration(config.LoadedConfig.Storage.URLExpireMinutes) * time.Minute).Unix()
+	url := "/api/file/" + strconv.Itoa(int(id)) + "?expires=" + strconv.FormatInt(expires, 10) +
+		"&sig=" + signAttachment(id, variant, expires)
+	if variant != AttachmentVariantOriginal {
+		url += "&variant=" + variant
+	}
+	return url
+}
+
+// AttachmentToResponse 转换为响应结构并生成访问链接
+func AttachmentToResponse(a models.Attachment) models.AttachmentResponse {
+	resp := models.AttachmentResponse{
+		ID:          a.ID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		IsImage:     a.IsImage(),
+		URL:         AttachmentURL(a.ID, AttachmentVariantOriginal),
+	}
+	if a.ThumbnailKey != "" {
+		resp.ThumbnailURL = AttachmentURL(a.ID, AttachmentVariantThumbnail)
+	}
+	return resp
+}
+
+// GetPostAttachments 获取帖子的附件列表
+func GetPostAttachments(postID uint) []models.AttachmentResponse {
+	var attachments []models.Attachment
+	if err := database.DB.Where("post_id = ?", postID).Order("id").Find(&attachments).Error; err != nil {
+		logger.GetLogger().Errorf("获取帖子附件失败: post_id=%d, err=%v", postID, err)
+		return nil
+	}
+	responses := make([]models.AttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		responses = append(responses, AttachmentToResponse(a))
+	}
+	return responses
+}
+
+// OpenAttachment 校验签名和有效期后打开附件文件
+func OpenAttachment(id uint, variant string, expires int64, sig string) (io.ReadCloser, *models.Attachment, *models.ServiceError) {
+	if time.Now().Unix() > expires {
+		return nil, nil, &models.ServiceError{Code: 1001, Message: "链接已过期"}
+	}
+	if !hmac.Equal([]byte(sig), []byte(signAttachment(id, variant, expires))) {
+		return nil, nil, &models.ServiceError{Code: 1002, Message: "签名无效"}
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.First(&attachment, id).Error; err != nil {
+		return nil, nil, &models.ServiceError{Code: 1003, Message: "文件不存在"}
+	}
+
+	key := attachment.StorageKey
+	if variant == AttachmentVariantThumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, nil, &models.ServiceError{Code: 1003, Message: "文件不存在"}
+		}
+		key = attachment.ThumbnailKey
+	}
+	reader, err := storage.Store.Open(key)
+	if err != nil {
+		return nil, nil, &models.ServiceError{Code: 1003, Message: "文件不存在"}
+	}
+	return reader, &attachment, nil
+}
+
+func removeAttachmentFiles(a models.Attachment) {
+	if err := storage.Store.Delete(a.StorageKey); err != nil {
+		logger.GetLogger().Errorf("删除附件文件失败: key=%s, err=%v", a.StorageKey, err)
+	}
+	if a.ThumbnailKey != "" {
+		if err := storage.Store.Delete(a.ThumbnailKey); err != nil {
+			logger.GetLogger().Errorf("删除缩略图失败: key=%s, err=%v", a.ThumbnailKey, err)
+		}
+	}
+}
+
+// CleanupOrphanAttachments 清理超时未关联帖子的附件，以及所属帖子已被删除的附件；正在用作头像的图片不清理
+func CleanupOrphanAttachments() {
+	deadline := time.Now().Add(-time.Duration(config.LoadedConfig.Storage.OrphanHours) * time.Hour)
+
+	var attachments []models.Attachment
+	err := database.DB.
+		Where(database.DB.Where("post_id = 0 AND created_at < ?", deadline).
+			Or("post_id <> 0 AND post_id NOT IN (?)", database.DB.Model(&models.Post{}).Select("id"))).
+		Where("id NOT IN (?)", database.DB.Model(&models.User{}).Select("avatar_id").Where("avatar_id <> 0")).
+		Limit(500).
+		Find(&attachments).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询孤立附件失败: %v", err)
+		return
+	}
+
+	for _, a := range attachments {
+		removeAttachmentFiles(a)
+		if err := database.DB.Delete(&models.Attachment{}, a.ID).Error; err != nil {
+			logger.GetLogger().Errorf("删除孤立附件记录失败: attachment_id=%d, err=%v", a.ID, err)
+		}
+	}
+	if len(attachments) > 0 {
+		logger.GetLogger().Infof("清理孤立附件完成：共清理 %d 个", len(attachments))
+	}
+}
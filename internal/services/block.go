@@ -11,6 +11,7 @@ import (
 	"time"
 
 	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
 func CreateBlock(block models.Block) error {
@@ -30,13 +31,30 @@ func GetReportListByUserID(userID uint) ([]map[string]interface{}, error) {
 
 	var reportList []map[string]interface{}
 	for _, block := range blocks {
+		if block.TargetType == models.BlockTargetMessage {
+			item := map[string]interface{}{
+				"target_type": block.TargetType,
+				"message_id":  block.TargetID,
+				"reason":      block.Reason,
+				"status":      block.Status,
+			}
+			if message, err := GetMessageByID(block.TargetID); err != nil {
+				item["content"] = "私信已被删除"
+			} else {
+				item["content"] = message.Content
+			}
+			reportList = append(reportList, item)
+			continue
+		}
+
 		// 获取被举报的帖子内容
 		post, err := GetPostByID(block.TargetID)
 
 		item := map[string]interface{}{
-			"post_id": block.TargetID,
-			"reason":  block.Reason,
-			"status":  block.Status,
+			"target_type": block.TargetType,
+			"post_id":     block.TargetID,
+			"reason":      block.Reason,
+			"status":      block.Status,
 		}
 
 		if err != nil {
@@ -62,7 +80,7 @@ func ProcessReportApproval(postID uint, approval int, adminID uint) *models.Serv
 		}
 	}
 	var block models.Block
-	if err := tx.Where("target_id = ? AND status = 0", postID).First(&block).Error; err != nil {
+	if err := tx.Where("target_id = ? AND target_type = ? AND status = 0", postID, models.BlockTargetPost).First(&block).Error; err != nil {
 		tx.Rollback()
 		return &models.ServiceError{
 			Code:    1009, // 新增错误码：举报记录不存在或已处理
@@ -70,6 +88,9 @@ func ProcessReportApproval(postID uint, approval int, adminID uint) *models.Serv
 		}
 	}
 	// 如果审批通过（同意删除），则删除被举报的帖子
+	var authorID uint
+	var deletedPost models.Post
+	var creditedLikes int64
 	if approval == 1 {
 		// 先查询帖子，确认存在
 		var post models.Post
@@ -80,6 +101,10 @@ func ProcessReportApproval(postID uint, approval int, adminID uint) *models.Serv
 				Message: "帖子不存在: " + err.Error(),
 			}
 		}
+		authorID = post.UserID
+		deletedPost = post
+		block.TargetUserID = post.UserID
+		creditedLikes = countCreditedLikes(tx, post)
 
 		// 删除帖子
 		if err := tx.Delete(&models.Post{}, postID).Error; err != nil {
@@ -140,5 +165,93 @@ func ProcessReportApproval(postID uint, approval int, adminID uint) *models.Serv
 		}
 	}
 
+	if approval == 1 {
+		onAnswerDeleted(deletedPost)
+		onPostRemoved(deletedPost, creditedLikes, true)
+	}
+	onReportDecided(block.UserID, approval)
+
+	// 通知举报人审批结果（以及被删除帖子的作者）
+	NotifyReportResult(block.UserID, postID, authorID, approval)
+
+	EmitWebhookEvent(models.WebhookEventReportDecided, map[string]interface{}{
+		"report_id": block.ID,
+		"post_id":   postID,
+		"approval":  approval,
+		"admin_id":  adminID,
+	})
+	if approval == 1 {
+		EmitWebhookEvent(models.WebhookEventPostDeleted, map[string]interface{}{
+			"post_id": postID,
+			"reason":  "report",
+		})
+	}
+
+	return nil
+}
+
+// ProcessMessageReportApproval 处理私信举报审批，通过时删除该私信
+func ProcessMessageReportApproval(messageID uint, approval int, adminID uint) *models.ServiceError {
+	var message models.Message
+	var blocks []models.Block
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("target_id = ? AND target_type = ? AND status = 0", messageID, models.BlockTargetMessage).
+			Find(&blocks).Error; err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return &models.ServiceError{Code: 1009, Message: "未找到待审批的私信举报记录"}
+		}
+
+		if err := tx.First(&message, messageID).Error; err != nil {
+			return &models.ServiceError{Code: 1004, Message: "私信不存在: " + err.Error()}
+		}
+		if approval == 1 {
+			if err := tx.Delete(&models.Message{}, messageID).Error; err != nil {
+				return &models.ServiceError{Code: 1003, Message: "删除私信失败: " + err.Error()}
+			}
+		}
+
+		auditLog := models.AuditLog{
+			AdminID:  adminID,
+			Action:   "approve_message_report",
+			TargetID: messageID,
+			Detail:   fmt.Sprintf(`{"approval": %d, "conversation_id": %d}`, approval, message.ConversationID),
+		}
+		if err := tx.Create(&auditLog).Error; err != nil {
+			return &models.ServiceError{Code: 1011, Message: "记录审计日志失败"}
+		}
+
+		// 同一私信的所有待审批举报一并处理
+		if err := tx.Model(&models.Block{}).
+			Where("target_id = ? AND target_type = ? AND status = 0", messageID, models.BlockTargetMessage).
+			Update("status", approval).Error; err != nil {
+			return &models.ServiceError{Code: 1010, Message: "更新举报状态失败: " + err.Error()}
+		}
+		return nil
+	})
+	if err != nil {
+		if serviceErr, ok := err.(*models.ServiceError); ok {
+			return serviceErr
+		}
+		return &models.ServiceError{Code: 1005, Message: "事务提交失败: " + err.Error()}
+	}
+
+	content := "你举报的私信未通过审核"
+	if approval == 1 {
+		content = "你举报的私信已被管理员删除，感谢你的反馈"
+	}
+	for _, block := range blocks {
+		onReportDecided(block.UserID, approval)
+		if err := Notify(block.UserID, models.NotificationTypeModeration, messageID, content); err != nil {
+			logger.GetLogger().Errorf("发送私信举报结果通知失败: user_id=%d, message_id=%d, err=%v", block.UserID, messageID, err)
+		}
+	}
+
+	EmitWebhookEvent(models.WebhookEventReportDecided, map[string]interface{}{
+		"message_id": messageID,
+		"approval":   approval,
+		"admin_id":   adminID,
+	})
 	return nil
 }
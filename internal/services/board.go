@@ -0,0 +1,42 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strings"
+)
+
+// CreateBoard 创建版块
+func CreateBoard(name, description string, allowAnonymous bool) (*models.Board, *models.ServiceError) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, &models.ServiceError{Code: 1001, Message: "版块名称不能为空"}
+	}
+	var count int64
+	database.DB.Model(&models.Board{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		return nil, &models.ServiceError{Code: 1002, Message: "版块名称已存在"}
+	}
+
+	board := models.Board{Name: name, Description: description, AllowAnonymous: allowAnonymous}
+	if err := database.DB.Create(&board).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "创建版块失败: " + err.Error()}
+	}
+	return &board, nil
+}
+
+// GetBoards 获取所有版块
+func GetBoards() (boards []models.Board, err error) {
+	err = database.DB.Order("id").Find(&boards).Error
+	return
+}
+
+// BoardExists 判断版块是否存在，0表示全站，始终存在
+func BoardExists(boardID uint) bool {
+	if boardID == 0 {
+		return true
+	}
+	var count int64
+	database.DB.Model(&models.Board{}).Where("id = ?", boardID).Count(&count)
+	return count > 0
+}
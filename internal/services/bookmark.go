@@ -0,0 +1,211 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxBookmarkFolders      = 50 // 每个用户最多创建的收藏夹数
+	maxBookmarkFolderLength = 50 // 收藏夹名称最大字符数
+)
+
+// IsBookmarked 判断用户是否收藏了帖子
+func IsBookmarked(userID, postID uint) bool {
+	var count int64
+	database.DB.Model(&models.Bookmark{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Count(&count)
+	return count > 0
+}
+
+// checkOwnFolder 校验收藏夹属于该用户，folderID 为0表示未分类
+func checkOwnFolder(userID, folderID uint) *models.ServiceError {
+	if folderID == 0 {
+		return nil
+	}
+	var count int64
+	database.DB.Model(&models.BookmarkFolder{}).Where("id = ? AND user_id = ?", folderID, userID).Count(&count)
+	if count == 0 {
+		return &models.ServiceError{Code: 1101, Message: "收藏夹不存在"}
+	}
+	return nil
+}
+
+// AddBookmark 收藏帖子，已收藏时移动到指定收藏夹
+func AddBookmark(userID, postID, folderID uint) *models.ServiceError {
+	post, err := GetPostByID(postID)
+	if err != nil || post.Status != models.PostStatusPublished {
+		return &models.ServiceError{Code: 1001, Message: "帖子不存在"}
+	}
+	if serviceErr := checkOwnFolder(userID, folderID); serviceErr != nil {
+		return serviceErr
+	}
+
+	bookmark := models.Bookmark{UserID: userID, PostID: postID}
+	err = database.DB.Where(bookmark).
+		Assign(models.Bookmark{FolderID: folderID}).
+		FirstOrCreate(&bookmark).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1002, Message: "收藏失败: " + err.Error()}
+	}
+	return nil
+}
+
+// RemoveBookmark 取消收藏，帖子已被删除时同样可以移除
+func RemoveBookmark(userID, postID uint) *models.ServiceError {
+	result := database.DB.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.Bookmark{})
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1001, Message: "取消收藏失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1002, Message: "未收藏该帖子"}
+	}
+	return nil
+}
+
+// MoveBookmark 将收藏移动到其他收藏夹，folderID 为0表示移出到未分类
+func MoveBookmark(userID, postID, folderID uint) *models.ServiceError {
+	if serviceErr := checkOwnFolder(userID, folderID); serviceErr != nil {
+		return serviceErr
+	}
+	if !IsBookmarked(userID, postID) {
+		return &models.ServiceError{Code: 1002, Message: "未收藏该帖子"}
+	}
+	err := database.DB.Model(&models.Bookmark{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Update("folder_id", folderID).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1003, Message: "移动收藏失败: " + err.Error()}
+	}
+	return nil
+}
+
+// GetBookmarks 分页获取收藏列表（新收藏在前）；folderID 为 nil 时返回全部收藏。
+// 已被删除的帖子保留为墓碑项，由用户自行移除
+func GetBookmarks(userID uint, folderID *uint, page, pageSize int) ([]models.BookmarkResponse, int64, error) {
+	query := database.DB.Model(&models.Bookmark{}).Where("user_id = ?", userID)
+	if folderID != nil {
+		query = query.Where("folder_id = ?", *folderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var bookmarks []models.Bookmark
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&bookmarks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.BookmarkResponse, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		item := models.BookmarkResponse{
+			ID:       b.ID,
+			PostID:   b.PostID,
+			FolderID: b.FolderID,
+			Time:     b.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+		}
+		if post, err := GetPostByID(b.PostID); err == nil {
+			postResponse := FormatPost(post, userID)
+			item.Post = &postResponse
+		} else {
+			item.Deleted = true
+		}
+		responses = append(responses, item)
+	}
+	return responses, total, nil
+}
+
+func validateFolderName(name string) (string, *models.ServiceError) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxBookmarkFolderLength {
+		return "", &models.ServiceError{Code: 1001, Message: "收藏夹名称需为1-50个字符"}
+	}
+	return name, nil
+}
+
+// CreateBookmarkFolder 创建收藏夹
+func CreateBookmarkFolder(userID uint, name string) (*models.BookmarkFolder, *models.ServiceError) {
+	name, serviceErr := validateFolderName(name)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	var count int64
+	database.DB.Model(&models.BookmarkFolder{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxBookmarkFolders {
+		return nil, &models.ServiceError{Code: 1002, Message: "收藏夹数量已达上限"}
+	}
+	database.DB.Model(&models.BookmarkFolder{}).Where("user_id = ? AND name = ?", userID, name).Count(&count)
+	if count > 0 {
+		return nil, &models.ServiceError{Code: 1003, Message: "收藏夹名称已存在"}
+	}
+
+	folder := models.BookmarkFolder{UserID: userID, Name: name}
+	if err := database.DB.Create(&folder).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1004, Message: "创建收藏夹失败: " + err.Error()}
+	}
+	return &folder, nil
+}
+
+// RenameBookmarkFolder 重命名收藏夹
+func RenameBookmarkFolder(userID, folderID uint, name string) *models.ServiceError {
+	name, serviceErr := validateFolderName(name)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	if folderID == 0 {
+		return &models.ServiceError{Code: 1101, Message: "收藏夹不存在"}
+	}
+	if serviceErr := checkOwnFolder(userID, folderID); serviceErr != nil {
+		return serviceErr
+	}
+
+	var count int64
+	database.DB.Model(&models.BookmarkFolder{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, folderID).
+		Count(&count)
+	if count > 0 {
+		return &models.ServiceError{Code: 1003, Message: "收藏夹名称已存在"}
+	}
+
+	if err := database.DB.Model(&models.BookmarkFolder{}).Where("id = ?", folderID).Update("name", name).Error; err != nil {
+		return &models.ServiceError{Code: 1004, Message: "重命名收藏夹失败: " + err.Error()}
+	}
+	return nil
+}
+
+// DeleteBookmarkFolder 删除收藏夹，其中的收藏移到未分类
+func DeleteBookmarkFolder(userID, folderID uint) *models.ServiceError {
+	if folderID == 0 {
+		return &models.ServiceError{Code: 1101, Message: "收藏夹不存在"}
+	}
+	if serviceErr := checkOwnFolder(userID, folderID); serviceErr != nil {
+		return serviceErr
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Bookmark{}).
+			Where("user_id = ? AND folder_id = ?", userID, folderID).
+			Update("folder_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BookmarkFolder{}, folderID).Error
+	})
+	if err != nil {
+		return &models.ServiceError{Code: 1004, Message: "删除收藏夹失败: " + err.Error()}
+	}
+	return nil
+}
+
+// GetBookmarkFolders 获取用户的收藏夹列表
+func GetBookmarkFolders(userID uint) ([]models.BookmarkFolder, error) {
+	folders := make([]models.BookmarkFolder, 0)
+	err := database.DB.Where("user_id = ?", userID).Order("id").Find(&folders).Error
+	return folders, err
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"CMS/pkg/storage"
+	"archive/zip"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Redis 键名定义
+const (
+	dataExportLockKey = "lock:data:export" // 生成导出文件的任务锁，保证同一时刻只有一个实例在处理
+
+	dataExportLockTTL    = 5 * time.Minute
+	dataExportBatchSize  = 10
+	dataExportStaleAfter = 30 * time.Minute // 生成中超过该时长视为实例中断，重新排队
+	dataExportTimeFormat = "2006-01-02T15:04:05.000-07:00"
+	maxDataExportError   = 255
+)
+
+// exportFile ZIP 中的一个 JSON 文件
+type exportFile struct {
+	name string
+	data interface{}
+}
+
+// signDataExport 计算导出文件下载签名，与附件签名使用同一密钥但消息格式不同，不能互相冒用
+func signDataExport(id uint, expires int64) string {
+	mac := hmac.New(sha256.New, fileSigningKey())
+	fmt.Fprintf(mac, "export:%d:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// dataExportURL 生成带签名的限时下载链接，有效期不超过文件本身的过期时间
+func dataExportURL(export models.DataExport) string {
+	expiresAt := time.Now().Add(time.Duration(config.LoadedConfig.Storage.URLExpireMinutes) * time.Minute)
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expiresAt) {
+		expiresAt = *export.ExpiresAt
+	}
+	expires := expiresAt.Unix()
+	return "/api/student/me/export/" + strconv.Itoa(int(export.ID)) + "?expires=" + strconv.FormatInt(expires, 10) +
+		"&sig=" + signDataExport(export.ID, expires)
+}
+
+func dataExportToResponse(export models.DataExport) models.DataExportResponse {
+	resp := models.DataExportResponse{
+		ID:        export.ID,
+		Status:    export.Status,
+		Error:     export.Error,
+		CreatedAt: export.CreatedAt.Format(dataExportTimeFormat),
+	}
+	if export.Status == models.DataExportDone && export.ExpiresAt != nil {
+		resp.Size = export.Size
+		resp.ExpiresAt = export.ExpiresAt.Format(dataExportTimeFormat)
+		resp.DownloadURL = dataExportURL(export)
+	}
+	return resp
+}
+
+// RequestDataExport 申请导出个人数据，由后台任务异步生成；同一时间只能有一个进行中的任务，
+// 且两次申请之间需间隔 ExportCooldownHours（生成失败的不计入）
+func RequestDataExport(userID uint) (*models.DataExportResponse, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil || user.IsClosed() {
+		return nil, &models.ServiceError{Code: 1501, Message: "用户不存在"}
+	}
+
+	var count int64
+	database.DB.Model(&models.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, []int{models.DataExportPending, models.DataExportProcessing}).
+		Count(&count)
+	if count > 0 {
+		return nil, &models.ServiceError{Code: 1502, Message: "已有正在生成的导出任务，请稍后查看"}
+	}
+
+	cooldown := time.Duration(config.LoadedConfig.Privacy.ExportCooldownHours) * time.Hour
+	database.DB.Model(&models.DataExport{}).
+		Where("user_id = ? AND status = ? AND created_at > ?", userID, models.DataExportDone, time.Now().Add(-cooldown)).
+		Count(&count)
+	if count > 0 {
+		return nil, &models.ServiceError{
+			Code:    1503,
+			Message: fmt.Sprintf("每%d小时只能申请一次导出", config.LoadedConfig.Privacy.ExportCooldownHours),
+		}
+	}
+
+	export := models.DataExport{UserID: userID, Status: models.DataExportPending}
+	if err := database.DB.Create(&export).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1504, Message: "申请导出失败: " + err.Error()}
+	}
+	resp := dataExportToResponse(export)
+	return &resp, nil
+}
+
+// GetLatestDataExport 获取用户最近一次导出任务的状态，已生成时返回下载链接
+func GetLatestDataExport(userID uint) (*models.DataExportResponse, *models.ServiceError) {
+	var export models.DataExport
+	if err := database.DB.Where("user_id = ?", userID).Order("id DESC").First(&export).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1505, Message: "暂无导出记录"}
+	}
+	resp := dataExportToResponse(export)
+	return &resp, nil
+}
+
+// OpenDataExport 校验签名和所属用户后打开导出文件，调用方负责关闭
+func OpenDataExport(userID, id uint, expires int64, sig string) (io.ReadCloser, *models.DataExport, *models.ServiceError) {
+	if time.Now().Unix() > expires {
+		return nil, nil, &models.ServiceError{Code: 1001, Message: "链接已过期"}
+	}
+	if !hmac.Equal([]byte(sig), []byte(signDataExport(id, expires))) {
+		return nil, nil, &models.ServiceError{Code: 1002, Message: "签名无效"}
+	}
+
+	var export models.DataExport
+	// 只能下载自己的导出文件，其他用户的一律视为不存在
+	err := database.DB.Where("id = ? AND user_id = ? AND status = ?", id, userID, models.DataExportDone).First(&export).Error
+	if err != nil || export.ExpiresAt == nil || export.ExpiresAt.Before(time.Now()) {
+		return nil, nil, &models.ServiceError{Code: 1003, Message: "文件不存在"}
+	}
+	reader, err := storage.Store.Open(export.StorageKey)
+	if err != nil {
+		return nil, nil, &models.ServiceError{Code: 1003, Message: "文件不存在"}
+	}
+	return reader, &export, nil
+}
+
+// collectExportData 查询用户的个人数据：资料、发布的帖子（含匿名和草稿）、点赞、举报和通知
+func collectExportData(userID uint) ([]exportFile, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := models.ExportProfile{
+		ID:                user.ID,
+		Username:          user.Username,
+		Name:              user.Name,
+		UserType:          user.UserType,
+		Reputation:        user.Reputation,
+		Bio:               user.Bio,
+		ClassName:         user.ClassName,
+		Department:        user.Department,
+		ProfileVisibility: user.ProfileVisibility,
+		TwoFactorEnabled:  user.TOTPEnabled,
+		OIDCLinked:        user.OIDCSubject != nil,
+		ExportedAt:        time.Now().Format(dataExportTimeFormat),
+	}
+
+	var posts []models.Post
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	exportPosts := make([]models.ExportPost, 0, len(posts))
+	for _, p := range posts {
+		item := models.ExportPost{
+			ID:          p.ID,
+			Content:     p.Content,
+			BoardID:     p.BoardID,
+			PostType:    p.PostType,
+			ParentID:    p.ParentID,
+			Status:      p.Status,
+			IsAnonymous: p.IsAnonymous,
+			Pseudonym:   p.Pseudonym,
+			Time:        p.PostTime.Format(dataExportTimeFormat),
+		}
+		if p.ScheduledAt != nil {
+			item.ScheduledAt = p.ScheduledAt.Format(dataExportTimeFormat)
+		}
+		exportPosts = append(exportPosts, item)
+	}
+
+	var likedPostIDs []uint
+	if err := database.DB.Model(&models.Like{}).Where("user_id = ?", userID).Order("id").Pluck("post_id", &likedPostIDs).Error; err != nil {
+		return nil, err
+	}
+	likes := make([]models.ExportLike, 0, len(likedPostIDs))
+	for _, postID := range likedPostIDs {
+		likes = append(likes, models.ExportLike{PostID: postID})
+	}
+
+	var blocks []models.Block
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	reports := make([]models.ExportReport, 0, len(blocks))
+	for _, b := range blocks {
+		reports = append(reports, models.ExportReport{
+			ID:         b.ID,
+			TargetType: b.TargetType,
+			TargetID:   b.TargetID,
+			Reason:     b.Reason,
+			Status:     b.Status,
+			Time:       b.CreatedAt.Format(dataExportTimeFormat),
+		})
+	}
+
+	var notifications []models.Notification
+	if err := database.DB.Where("user_id = ?", userID).Order("id").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	exportNotifications := make([]models.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		exportNotifications = append(exportNotifications, n.ToResponse())
+	}
+
+	return []exportFile{
+		{name: "profile.json", data: profile},
+		{name: "posts.json", data: exportPosts},
+		{name: "likes.json", data: likes},
+		{name: "reports.json", data: reports},
+		{name: "notifications.json", data: exportNotifications},
+	}, nil
+}
+
+// buildDataExport 生成 ZIP 文件并写入存储，返回存储 key 和文件大小
+func buildDataExport(export models.DataExport) (string, int64, error) {
+	files, err := collectExportData(export.UserID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return "", 0, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return "", 0, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+
+	size := int64(buf.Len())
+	key := "exports/" + time.Now().Format("2006/01/02/") + randomKey() + ".zip"
+	if err := storage.Store.Save(key, &buf); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+// processDataExport 生成单个导出任务，失败时记录原因
+func processDataExport(export models.DataExport) {
+	err := database.DB.Model(&export).Update("status", models.DataExportProcessing).Error
+	if err != nil {
+		logger.GetLogger().Errorf("更新导出任务状态失败: export_id=%d, err=%v", export.ID, err)
+		return
+	}
+
+	key, size, err := buildDataExport(export)
+	if err != nil {
+		logger.GetLogger().Errorf("生成个人数据导出失败: export_id=%d, user_id=%d, err=%v", export.ID, export.UserID, err)
+		msg := err.Error()
+		if len(msg) > maxDataExportError {
+			msg = msg[:maxDataExportError]
+		}
+		database.DB.Model(&export).Updates(map[string]interface{}{
+			"status": models.DataExportFailed,
+			"error":  msg,
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(config.LoadedConfig.Privacy.ExportExpireHours) * time.Hour)
+	err = database.DB.Model(&export).Updates(map[string]interface{}{
+		"status":      models.DataExportDone,
+		"storage_key": key,
+		"size":        size,
+		"expires_at":  expiresAt,
+	}).Error
+	if err != nil {
+		logger.GetLogger().Errorf("更新导出任务状态失败: export_id=%d, err=%v", export.ID, err)
+		storage.Store.Delete(key)
+		return
+	}
+	logger.GetLogger().Infof("个人数据导出完成: export_id=%d, user_id=%d, size=%d", export.ID, export.UserID, size)
+}
+
+// removeDataExports 删除导出记录及其文件
+func removeDataExports(exports []models.DataExport) {
+	for _, export := range exports {
+		if export.StorageKey != "" {
+			if err := storage.Store.Delete(export.StorageKey); err != nil {
+				logger.GetLogger().Errorf("删除导出文件失败: key=%s, err=%v", export.StorageKey, err)
+				continue
+			}
+		}
+		if err := database.DB.Delete(&models.DataExport{}, export.ID).Error; err != nil {
+			logger.GetLogger().Errorf("删除导出记录失败: export_id=%d, err=%v", export.ID, err)
+		}
+	}
+}
+
+// deleteUserDataExports 删除用户的全部导出文件（注销账号时调用）
+func deleteUserDataExports(userID uint) {
+	var exports []models.DataExport
+	if err := database.DB.Where("user_id = ?", userID).Find(&exports).Error; err != nil {
+		logger.GetLogger().Errorf("查询用户导出记录失败: user_id=%d, err=%v", userID, err)
+		return
+	}
+	removeDataExports(exports)
+}
+
+// ProcessDataExports 生成排队中的导出文件并清理已过期的文件，由定时任务调用，多实例部署时通过分布式锁只由一个实例执行
+func ProcessDataExports() {
+	token, ok, err := redis.TryLock(dataExportLockKey, dataExportLockTTL)
+	if err != nil {
+		logger.GetLogger().Errorf("获取导出任务锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer redis.Unlock(dataExportLockKey, token)
+
+	now := time.Now()
+	// 生成过程中实例退出的任务重新排队
+	database.DB.Model(&models.DataExport{}).
+		Where("status = ? AND updated_at < ?", models.DataExportProcessing, now.Add(-dataExportStaleAfter)).
+		Update("status", models.DataExportPending)
+
+	var exports []models.DataExport
+	err = database.DB.Where("status = ?", models.DataExportPending).Order("id").Limit(dataExportBatchSize).Find(&exports).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询导出任务失败: %v", err)
+		return
+	}
+	for _, export := range exports {
+		processDataExport(export)
+	}
+
+	var expired []models.DataExport
+	err = database.DB.Where("status = ? AND expires_at < ?", models.DataExportDone, now).Limit(500).Find(&expired).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询过期导出文件失败: %v", err)
+		return
+	}
+	removeDataExports(expired)
+	if len(expired) > 0 {
+		logger.GetLogger().Infof("清理过期导出文件完成：共清理 %d 个", len(expired))
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/storage"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenDataExportRequiresOwner(t *testing.T) {
+	setupTestConfig(t)
+	setupTestDB(t, &models.DataExport{})
+	prev := storage.Store
+	storage.Store = storage.NewLocalStorage(t.TempDir())
+	t.Cleanup(func() { storage.Store = prev })
+
+	if err := storage.Store.Save("exports/1.zip", strings.NewReader("zip")); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(time.Hour)
+	export := models.DataExport{UserID: 1, Status: models.DataExportDone, StorageKey: "exports/1.zip", ExpiresAt: &expiresAt}
+	if err := database.DB.Create(&export).Error; err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Now().Add(time.Minute).Unix()
+	sig := signDataExport(export.ID, expires)
+
+	reader, _, serviceErr := OpenDataExport(1, export.ID, expires, sig)
+	if serviceErr != nil {
+		t.Fatalf("本人应能下载: %v", serviceErr)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "zip" {
+		t.Fatalf("文件内容错误: %q", data)
+	}
+
+	// 签名有效也不能下载其他用户的导出文件
+	if _, _, serviceErr := OpenDataExport(2, export.ID, expires, sig); serviceErr == nil || serviceErr.Code != 1003 {
+		t.Fatalf("其他用户不能下载: %v", serviceErr)
+	}
+	if _, _, serviceErr := OpenDataExport(1, export.ID, expires, sig+"0"); serviceErr == nil || serviceErr.Code != 1002 {
+		t.Fatalf("签名错误应拒绝: %v", serviceErr)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/markdown"
+	"CMS/pkg/redis"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	scheduleLockKey = "lock:post:schedule" // 定时发布任务锁，保证同一时刻只有一个实例在发布
+	scheduleLockTTL = 25 * time.Second
+)
+
+// draftStatus 根据是否设置了定时发布时间决定帖子状态
+func draftStatus(scheduledAt *time.Time) (int, *models.ServiceError) {
+	if scheduledAt == nil {
+		return models.PostStatusDraft, nil
+	}
+	if !scheduledAt.After(time.Now()) {
+		return 0, &models.ServiceError{Code: 1001, Message: "定时发布时间必须晚于当前时间"}
+	}
+	return models.PostStatusScheduled, nil
+}
+
+// getOwnDraft 获取用户自己的草稿或定时帖子
+func getOwnDraft(postID, userID uint) (*models.Post, *models.ServiceError) {
+	post, err := GetPostByID(postID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "草稿不存在"}
+	}
+	if post.UserID != userID {
+		return nil, &models.ServiceError{Code: 1003, Message: "无权限操作该草稿"}
+	}
+	if post.Status == models.PostStatusPublished {
+		return nil, &models.ServiceError{Code: 1004, Message: "帖子已发布"}
+	}
+	return &post, nil
+}
+
+// SaveDraft 保存草稿，设置了 scheduledAt 时成为定时发布帖子；附件随草稿一起关联
+func SaveDraft(userID uint, content string, boardID uint, scheduledAt *time.Time, attachmentIDs []uint) (*models.Post, *models.ServiceError) {
+	status, serviceErr := draftStatus(scheduledAt)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	if !BoardExists(boardID) {
+		return nil, &models.ServiceError{Code: 1005, Message: "版块不存在"}
+	}
+
+	post := models.Post{
+		Content:     content,
+		ContentHTML: markdown.Render(content),
+		UserID:      userID,
+		BoardID:     boardID,
+		PostTime:    time.Now(),
+		Status:      status,
+		ScheduledAt: scheduledAt,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		if serviceErr := AttachToPost(tx, post.ID, userID, attachmentIDs); serviceErr != nil {
+			return serviceErr
+		}
+		return nil
+	})
+	if errors.As(err, &serviceErr) {
+		return nil, serviceErr
+	}
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1006, Message: "保存草稿失败: " + err.Error()}
+	}
+	return &post, nil
+}
+
+// UpdateDraft 修改草稿内容、版块和定时发布时间
+func UpdateDraft(postID, userID uint, content string, boardID uint, scheduledAt *time.Time) *models.ServiceError {
+	post, serviceErr := getOwnDraft(postID, userID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	status, serviceErr := draftStatus(scheduledAt)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	if !BoardExists(boardID) {
+		return &models.ServiceError{Code: 1005, Message: "版块不存在"}
+	}
+
+	// 带上状态条件，避免与定时任务并发发布时覆盖已发布的帖子
+	result := database.DB.Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, post.Status).
+		Updates(map[string]interface{}{
+			"content":      content,
+			"content_html": markdown.Render(content),
+			"board_id":     boardID,
+			"status":       status,
+			"scheduled_at": scheduledAt,
+		})
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1006, Message: "修改草稿失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		// 内容未变化时 MySQL 同样返回0行，需要重新确认是否已被发布
+		if _, serviceErr := getOwnDraft(postID, userID); serviceErr != nil {
+			return serviceErr
+		}
+	}
+	return nil
+}
+
+// GetDrafts 获取用户的草稿和定时发布帖子
+func GetDrafts(userID uint) ([]models.PostResponse, error) {
+	var posts []models.Post
+	err := database.DB.Where("user_id = ? AND status IN ?", userID, []int{models.PostStatusDraft, models.PostStatusScheduled}).
+		Order("id DESC").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]models.PostResponse, 0, len(posts))
+	for _, p := range posts {
+		response := p.ToResponse()
+		response.Attachments = GetPostAttachments(p.ID)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// DeleteDraft 删除草稿或定时发布帖子
+func DeleteDraft(postID, userID uint) *models.ServiceError {
+	post, serviceErr := getOwnDraft(postID, userID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	result := database.DB.Where("id = ? AND status <> ?", post.ID, models.PostStatusPublished).Delete(&models.Post{})
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1006, Message: "删除草稿失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1004, Message: "帖子已发布"}
+	}
+	return nil
+}
+
+// PublishDraft 立即发布草稿或定时帖子
+func PublishDraft(postID, userID uint) *models.ServiceError {
+	post, serviceErr := getOwnDraft(postID, userID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	published, err := publishPost(*post)
+	if err != nil {
+		return &models.ServiceError{Code: 1006, Message: "发布失败: " + err.Error()}
+	}
+	if !published {
+		return &models.ServiceError{Code: 1004, Message: "帖子已发布"}
+	}
+	return nil
+}
+
+// publishPost 将帖子状态改为已发布，发布时间取实际发布时刻；通过状态条件保证只会发布一次。
+// 需要先审后发的用户改为进入待审核状态
+func publishPost(post models.Post) (bool, error) {
+	now := time.Now()
+	status := models.PostStatusPublished
+	if needsPreModeration(post.UserID) {
+		status = models.PostStatusPending
+	}
+	result := database.DB.Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, post.Status).
+		Updates(map[string]interface{}{
+			"status":    status,
+			"post_time": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if status == models.PostStatusPending {
+		return true, nil
+	}
+
+	post.Status = models.PostStatusPublished
+	post.PostTime = now
+	onPostPublished(post)
+	return true, nil
+}
+
+// PublishDuePosts 发布到期的定时帖子，由定时任务调用；多实例部署时通过 Redis 锁避免重复扫描
+func PublishDuePosts() {
+	token, ok, err := redis.TryLock(scheduleLockKey, scheduleLockTTL)
+	if err != nil {
+		logger.GetLogger().Errorf("获取定时发布锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer redis.Unlock(scheduleLockKey, token)
+
+	var posts []models.Post
+	err = database.DB.Where("status = ? AND scheduled_at <= ?", models.PostStatusScheduled, time.Now()).
+		Order("scheduled_at").
+		Find(&posts).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询到期定时帖子失败: %v", err)
+		return
+	}
+
+	for _, post := range posts {
+		published, err := publishPost(post)
+		if err != nil {
+			logger.GetLogger().Errorf("定时发布帖子失败: post_id=%d, err=%v", post.ID, err)
+			continue
+		}
+		if published {
+			logger.GetLogger().Infof("定时发布帖子成功: post_id=%d, user_id=%d", post.ID, post.UserID)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/pkg/redis"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Redis 键名定义
+const (
+	eventChannel    = "event:channel"  // 实时事件广播频道：pub/sub
+	eventSeqKey     = "event:seq"      // 全局事件ID：string类型（INCR）
+	eventHistoryKey = "event:history"  // 最近事件，用于断线补发：zset类型（score为事件ID）
+	streamConnKey   = "stream:conn:"   // 用户当前长连接数：string类型
+	streamTicketKey = "stream:ticket:" // WebSocket 连接票据：string类型（user_id:session_id），取出即删除
+	subscriberQueue = 64               // 单个订阅者的事件缓冲区大小
+
+	streamTicketBytes = 16
+)
+
+// EventSubscriber 本实例上的一个事件订阅者（对应一条长连接）
+type EventSubscriber struct {
+	UserID uint
+	Events chan models.RealtimeEvent
+
+	mu      sync.RWMutex
+	postIDs map[uint]struct{}
+}
+
+// SubscribePosts 订阅帖子的点赞数变化
+func (s *EventSubscriber) SubscribePosts(postIDs ...uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range postIDs {
+		s.postIDs[id] = struct{}{}
+	}
+}
+
+// UnsubscribePosts 取消订阅帖子的点赞数变化
+func (s *EventSubscriber) UnsubscribePosts(postIDs ...uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range postIDs {
+		delete(s.postIDs, id)
+	}
+}
+
+// Match 判断事件是否应推送给该订阅者
+func (s *EventSubscriber) Match(evt models.RealtimeEvent) bool {
+	if evt.UserID != 0 && evt.UserID != s.UserID {
+		return false
+	}
+	if evt.Type == models.EventTypeLikeCount {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		_, ok := s.postIDs[evt.PostID]
+		return ok
+	}
+	return true
+}
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = make(map[*EventSubscriber]struct{})
+
+	// eventListeners 本实例的事件监听器（如 WebSocket 网关），只在启动阶段注册
+	eventListeners []func(models.RealtimeEvent)
+)
+
+// AddEventListener 注册事件监听器，每个到达本实例的实时事件都会回调一次，需在启动阶段调用
+func AddEventListener(fn func(models.RealtimeEvent)) {
+	eventListeners = append(eventListeners, fn)
+}
+
+// SubscribeEvents 在本实例注册一个事件订阅者
+func SubscribeEvents(userID uint, postIDs []uint) *EventSubscriber {
+	sub := &EventSubscriber{
+		UserID:  userID,
+		Events:  make(chan models.RealtimeEvent, subscriberQueue),
+		postIDs: make(map[uint]struct{}),
+	}
+	sub.SubscribePosts(postIDs...)
+
+	subscribersMu.Lock()
+	subscribers[sub] = struct{}{}
+	subscribersMu.Unlock()
+	return sub
+}
+
+// UnsubscribeEvents 注销事件订阅者
+func UnsubscribeEvents(sub *EventSubscriber) {
+	subscribersMu.Lock()
+	delete(subscribers, sub)
+	subscribersMu.Unlock()
+}
+
+// dispatchEvent 将事件分发给本实例上匹配的订阅者，缓冲区已满的慢连接直接丢弃该事件
+func dispatchEvent(evt models.RealtimeEvent) {
+	for _, fn := range eventListeners {
+		fn(evt)
+	}
+
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+	for sub := range subscribers {
+		if !sub.Match(evt) {
+			continue
+		}
+		select {
+		case sub.Events <- evt:
+		default:
+			logger.GetLogger().Errorf("实时事件缓冲区已满，丢弃事件: user_id=%d, event_id=%d", sub.UserID, evt.ID)
+		}
+	}
+}
+
+// PublishEvent 发布实时事件：分配全局ID、写入补发缓存并通过 Redis 广播到所有实例
+func PublishEvent(eventType string, userID, postID uint, data interface{}) {
+	ctx := context.Background()
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		logger.GetLogger().Errorf("序列化实时事件失败: type=%s, err=%v", eventType, err)
+		return
+	}
+
+	id, err := redis.RedisClient.Incr(ctx, eventSeqKey).Result()
+	if err != nil {
+		logger.GetLogger().Errorf("生成实时事件ID失败: type=%s, err=%v", eventType, err)
+		return
+	}
+
+	evt := models.RealtimeEvent{
+		ID:     id,
+		Type:   eventType,
+		UserID: userID,
+		PostID: postID,
+		Data:   raw,
+	}
+	payload, _ := json.Marshal(evt)
+
+	historySize := int64(config.LoadedConfig.Realtime.HistorySize)
+	_, err = redis.RedisClient.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.ZAdd(ctx, eventHistoryKey, &goredis.Z{Score: float64(id), Member: payload})
+		pipe.ZRemRangeByRank(ctx, eventHistoryKey, 0, -historySize-1) // 只保留最近的事件
+		pipe.Publish(ctx, eventChannel, payload)
+		return nil
+	})
+	if err != nil {
+		logger.GetLogger().Errorf("发布实时事件失败: type=%s, event_id=%d, err=%v", eventType, id, err)
+	}
+}
+
+// GetEventsSince 获取ID大于 lastID 的缓存事件，用于 Last-Event-ID 断线重连补发
+func GetEventsSince(lastID int64) ([]models.RealtimeEvent, error) {
+	payloads, err := redis.RedisClient.ZRangeByScore(context.Background(), eventHistoryKey, &goredis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(lastID, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.RealtimeEvent, 0, len(payloads))
+	for _, payload := range payloads {
+		var evt models.RealtimeEvent
+		if err := json.Unmarshal([]byte(payload), &evt); err != nil {
+			continue
+		}
+		events = append(events, evt)
+	}
+	return events, nil
+}
+
+// StartEventSubscriber 订阅 Redis 事件频道并分发给本实例的订阅者，需在启动时以协程运行
+func StartEventSubscriber() {
+	ctx := context.Background()
+	for {
+		pubsub := redis.RedisClient.Subscribe(ctx, eventChannel)
+		for msg := range pubsub.Channel() {
+			var evt models.RealtimeEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &evt); err != nil {
+				logger.GetLogger().Errorf("解析实时事件失败: %v", err)
+				continue
+			}
+			dispatchEvent(evt)
+		}
+		pubsub.Close()
+		logger.GetLogger().Error("实时事件订阅中断，1秒后重新订阅")
+		time.Sleep(time.Second)
+	}
+}
+
+// streamConnTTL 连接计数过期时间（4个心跳周期），实例异常退出时兜底释放
+func streamConnTTL() time.Duration {
+	return time.Duration(config.LoadedConfig.Realtime.HeartbeatSeconds) * 4 * time.Second
+}
+
+// AcquireStreamSlot 占用一个用户长连接名额，超过上限时返回 false
+func AcquireStreamSlot(userID uint) (bool, error) {
+	ctx := context.Background()
+	key := streamConnKey + strconv.Itoa(int(userID))
+
+	count, err := redis.RedisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	redis.RedisClient.Expire(ctx, key, streamConnTTL())
+
+	if count > int64(config.LoadedConfig.Realtime.MaxConnsPerUser) {
+		redis.RedisClient.Decr(ctx, key)
+		return false, nil
+	}
+	return true, nil
+}
+
+// RefreshStreamSlot 心跳时续期连接计数
+func RefreshStreamSlot(userID uint) {
+	redis.RedisClient.Expire(context.Background(), streamConnKey+strconv.Itoa(int(userID)), streamConnTTL())
+}
+
+// ReleaseStreamSlot 释放一个用户长连接名额
+func ReleaseStreamSlot(userID uint) {
+	ctx := context.Background()
+	key := streamConnKey + strconv.Itoa(int(userID))
+	if count, err := redis.RedisClient.Decr(ctx, key).Result(); err == nil && count <= 0 {
+		redis.RedisClient.Del(ctx, key)
+	}
+}
+
+// IssueStreamTicket 签发短期、一次性的 WebSocket 连接票据。浏览器无法为 WebSocket 设置请求头，
+// 用票据代替登录 token 放在连接地址中，避免 token 出现在访问日志里
+func IssueStreamTicket(userID uint, sessionID string) (string, error) {
+	ticket, err := randomHex(streamTicketBytes)
+	if err != nil {
+		return "", err
+	}
+	ttl := time.Duration(config.LoadedConfig.Realtime.TicketSeconds) * time.Second
+	value := strconv.Itoa(int(userID)) + ":" + sessionID
+	if err := redis.RedisClient.Set(context.Background(), streamTicketKey+ticket, value, ttl).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// RedeemStreamTicket 取出并作废连接票据，返回签发票据的用户；票据无效、已使用，
+// 或签发票据的会话已失效时返回 0
+func RedeemStreamTicket(ticket string) (uint, error) {
+	value, err := redis.RedisClient.GetDel(context.Background(), streamTicketKey+ticket).Result()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	userIDStr, sessionID, _ := strings.Cut(value, ":")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil || userID <= 0 {
+		return 0, nil
+	}
+	// 访问令牌签发的票据没有会话ID，票据有效期很短，不再校验
+	if sessionID != "" {
+		valid, err := ValidateSession(sessionID, uint(userID))
+		if err != nil {
+			return 0, err
+		}
+		if !valid {
+			return 0, nil
+		}
+	}
+	return uint(userID), nil
+}
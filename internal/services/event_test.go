@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStreamTicketSingleUse(t *testing.T) {
+	setupTestConfig(t).Realtime.TicketSeconds = 30
+	mr := setupTestRedis(t)
+	if err := cacheSession(context.Background(), "sid-1", 7, time.Now(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	ticket, err := IssueStreamTicket(7, "sid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL(streamTicketKey + ticket); ttl <= 0 || ttl > 30*time.Second {
+		t.Fatalf("票据应短期有效: ttl=%v", ttl)
+	}
+	if userID, err := RedeemStreamTicket(ticket); err != nil || userID != 7 {
+		t.Fatalf("票据应有效: user_id=%d, err=%v", userID, err)
+	}
+	if userID, _ := RedeemStreamTicket(ticket); userID != 0 {
+		t.Fatal("票据只能使用一次")
+	}
+	if userID, _ := RedeemStreamTicket("unknown"); userID != 0 {
+		t.Fatal("未知票据应无效")
+	}
+}
+
+func TestStreamTicketExpiredOrRevoked(t *testing.T) {
+	setupTestConfig(t).Realtime.TicketSeconds = 30
+	mr := setupTestRedis(t)
+	cacheSession(context.Background(), "sid-1", 7, time.Now(), time.Now().Add(time.Hour))
+
+	expired, _ := IssueStreamTicket(7, "sid-1")
+	mr.FastForward(31 * time.Second)
+	if userID, _ := RedeemStreamTicket(expired); userID != 0 {
+		t.Fatal("过期票据应无效")
+	}
+
+	// 签发后会话被撤销（如退出登录），票据随之失效
+	revoked, _ := IssueStreamTicket(7, "sid-1")
+	mr.Del(sessionKey + "sid-1")
+	if userID, _ := RedeemStreamTicket(revoked); userID != 0 {
+		t.Fatal("会话失效后票据应无效")
+	}
+}
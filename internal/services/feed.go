@@ -0,0 +1,270 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Redis 键名定义
+const (
+	timelineKey = "timeline:" // 用户关注动态时间线：zset类型（member为帖子ID，score为发布时间毫秒）
+
+	fanoutBatchSize = 500
+)
+
+func timelineKeyOf(userID uint) string {
+	return timelineKey + strconv.Itoa(int(userID))
+}
+
+// isFanoutOnRead 粉丝数达到阈值的用户（如社团官方账号）不做写扩散，读取动态时再查询
+func isFanoutOnRead(userID uint) bool {
+	return GetFollowerCount(userID) >= int64(config.LoadedConfig.Feed.FanoutThreshold)
+}
+
+// addToTimelines 将帖子写入多个用户的时间线，并裁剪超出长度的旧条目
+func addToTimelines(userIDs []uint, posts []models.Post) error {
+	if len(userIDs) == 0 || len(posts) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	members := make([]*goredis.Z, 0, len(posts))
+	for _, p := range posts {
+		members = append(members, &goredis.Z{Score: float64(p.PostTime.UnixMilli()), Member: p.ID})
+	}
+	size := int64(config.LoadedConfig.Feed.TimelineSize)
+
+	for start := 0; start < len(userIDs); start += fanoutBatchSize {
+		end := min(start+fanoutBatchSize, len(userIDs))
+		pipe := redis.RedisClient.Pipeline()
+		for _, uid := range userIDs[start:end] {
+			key := timelineKeyOf(uid)
+			pipe.ZAdd(ctx, key, members...)
+			pipe.ZRemRangeByRank(ctx, key, 0, -size-1)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fanoutPost 帖子发布后写入粉丝的时间线（写扩散），匿名帖子不推送给粉丝以免暴露作者
+func fanoutPost(post models.Post) {
+	if post.IsAnonymous || post.PostType == models.PostTypeAnswer || isFanoutOnRead(post.UserID) {
+		return
+	}
+	followers, err := getFollowerIDs(post.UserID)
+	if err != nil {
+		logger.GetLogger().Errorf("获取粉丝列表失败: user_id=%d, err=%v", post.UserID, err)
+		return
+	}
+	if err := addToTimelines(followers, []models.Post{post}); err != nil {
+		logger.GetLogger().Errorf("写入粉丝时间线失败: post_id=%d, err=%v", post.ID, err)
+	}
+}
+
+// backfillTimeline 关注用户后将其近期帖子回填到自己的时间线
+func backfillTimeline(userID, authorID uint) {
+	var posts []models.Post
+	err := database.DB.Select("id", "post_time").
+		Where("user_id = ? AND status = ? AND is_anonymous = ? AND post_type <> ?", authorID, models.PostStatusPublished, false, models.PostTypeAnswer).
+		Order("post_time DESC").
+		Limit(config.LoadedConfig.Feed.BackfillSize).
+		Find(&posts).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询回填帖子失败: user_id=%d, author_id=%d, err=%v", userID, authorID, err)
+		return
+	}
+	if err := addToTimelines([]uint{userID}, posts); err != nil {
+		logger.GetLogger().Errorf("回填时间线失败: user_id=%d, author_id=%d, err=%v", userID, authorID, err)
+	}
+}
+
+// removeFromTimeline 取消关注后从时间线中移除该用户的帖子
+func removeFromTimeline(userID, authorID uint) {
+	var ids []uint
+	err := database.DB.Model(&models.Post{}).
+		Where("user_id = ?", authorID).
+		Order("id DESC").
+		Limit(config.LoadedConfig.Feed.TimelineSize).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return
+	}
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+	if err := redis.RedisClient.ZRem(context.Background(), timelineKeyOf(userID), members...).Err(); err != nil {
+		logger.GetLogger().Errorf("移除时间线帖子失败: user_id=%d, author_id=%d, err=%v", userID, authorID, err)
+	}
+}
+
+// fanoutOnReadAuthors 在关注的用户中找出粉丝数达到阈值、需要读扩散的用户
+func fanoutOnReadAuthors(followingIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(followingIDs) == 0 {
+		return ids, nil
+	}
+	err := database.DB.Model(&models.Follow{}).
+		Where("target_type = ? AND target_id IN ?", models.FollowTargetUser, followingIDs).
+		Group("target_id").
+		Having("COUNT(*) >= ?", config.LoadedConfig.Feed.FanoutThreshold).
+		Pluck("target_id", &ids).Error
+	return ids, err
+}
+
+// FeedCursor 关注动态的分页游标：上一页最后一条帖子的发布时间（毫秒）和ID。
+// 发布时间相同的帖子按ID倒序排列，游标同时比较两者，翻页时不会跳过同一毫秒发布的帖子
+type FeedCursor struct {
+	PostTime int64
+	PostID   uint
+}
+
+// ParseFeedCursor 解析 "发布时间_帖子ID" 格式的游标，格式错误或为空时从最新开始；
+// 兼容只有发布时间的旧游标（视为该时间之前的帖子）
+func ParseFeedCursor(raw string) FeedCursor {
+	timePart, idPart, _ := strings.Cut(raw, "_")
+	postTime, err := strconv.ParseInt(timePart, 10, 64)
+	if err != nil || postTime <= 0 {
+		return FeedCursor{}
+	}
+	id, _ := strconv.ParseUint(idPart, 10, 64)
+	return FeedCursor{PostTime: postTime, PostID: uint(id)}
+}
+
+func (c FeedCursor) String() string {
+	return strconv.FormatInt(c.PostTime, 10) + "_" + strconv.Itoa(int(c.PostID))
+}
+
+// before 判断帖子是否排在游标之后（即属于下一页）
+func (c FeedCursor) before(postTime int64, postID uint) bool {
+	return postTime < c.PostTime || (postTime == c.PostTime && postID < c.PostID)
+}
+
+// timelineBefore 从 Redis 时间线中取排在游标之后的至多 pageSize 个帖子ID：与游标同一毫秒的帖子单独取出按ID过滤，
+// 其余按分数取；返回第二个值表示时间线中可能还有更多
+func timelineBefore(userID uint, cursor FeedCursor, pageSize int) ([]string, bool, error) {
+	ctx := context.Background()
+	key := timelineKeyOf(userID)
+	score := strconv.FormatInt(cursor.PostTime, 10)
+
+	var ids []string
+	if cursor.PostID > 0 {
+		ties, err := redis.RedisClient.ZRangeByScore(ctx, key, &goredis.ZRangeBy{Min: score, Max: score}).Result()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, member := range ties {
+			if id, err := strconv.ParseUint(member, 10, 64); err == nil && uint(id) < cursor.PostID {
+				ids = append(ids, member)
+			}
+		}
+	}
+	older, err := redis.RedisClient.ZRevRangeByScore(ctx, key, &goredis.ZRangeBy{
+		Max:   "(" + score,
+		Min:   "-inf",
+		Count: int64(pageSize),
+	}).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	return append(ids, older...), len(older) == pageSize, nil
+}
+
+// GetFeed 获取关注动态：合并 Redis 时间线（写扩散）与大V用户、关注版块的帖子（读扩散），
+// 按发布时间倒序、同一时间按ID倒序；cursor 为上一页返回的游标，零值表示从最新开始。
+// 返回的游标为空表示没有更多数据
+func GetFeed(userID uint, cursor FeedCursor, pageSize int) ([]models.PostResponse, string, error) {
+	if cursor.PostTime <= 0 {
+		cursor = FeedCursor{PostTime: time.Now().Add(time.Minute).UnixMilli()}
+	}
+
+	// 写扩散部分：时间线中只存帖子ID
+	ids, more, err := timelineBefore(userID, cursor, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	var posts []models.Post
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ? AND status = ?", ids, models.PostStatusPublished).Find(&posts).Error; err != nil {
+			return nil, "", err
+		}
+	}
+
+	// 读扩散部分：大V用户和关注的版块
+	followingUsers, err := getFollowingIDs(userID, models.FollowTargetUser)
+	if err != nil {
+		return nil, "", err
+	}
+	authors, err := fanoutOnReadAuthors(followingUsers)
+	if err != nil {
+		return nil, "", err
+	}
+	boards, err := getFollowingIDs(userID, models.FollowTargetBoard)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(authors) > 0 || len(boards) > 0 {
+		// 发布时间按毫秒比较，与游标精度一致
+		cursorTime := time.UnixMilli(cursor.PostTime)
+		query := database.DB.
+			Where("status = ? AND post_type <> ?", models.PostStatusPublished, models.PostTypeAnswer).
+			Where("post_time < ? OR (post_time < ? AND id < ?)", cursorTime, cursorTime.Add(time.Millisecond), cursor.PostID)
+		switch {
+		case len(authors) > 0 && len(boards) > 0:
+			query = query.Where("(user_id IN ? AND is_anonymous = ?) OR board_id IN ?", authors, false, boards)
+		case len(authors) > 0:
+			query = query.Where("user_id IN ? AND is_anonymous = ?", authors, false)
+		default:
+			query = query.Where("board_id IN ?", boards)
+		}
+		var pulled []models.Post
+		if err := query.Order("post_time DESC").Order("id DESC").Limit(pageSize).Find(&pulled).Error; err != nil {
+			return nil, "", err
+		}
+		posts = append(posts, pulled...)
+		more = more || len(pulled) == pageSize
+	}
+
+	// 合并去重后按发布时间、ID倒序取一页
+	seen := make(map[uint]bool, len(posts))
+	merged := posts[:0]
+	for _, p := range posts {
+		if !seen[p.ID] && p.UserID != userID && cursor.before(p.PostTime.UnixMilli(), p.ID) {
+			seen[p.ID] = true
+			merged = append(merged, p)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		ti, tj := merged[i].PostTime.UnixMilli(), merged[j].PostTime.UnixMilli()
+		if ti != tj {
+			return ti > tj
+		}
+		return merged[i].ID > merged[j].ID
+	})
+	if len(merged) > pageSize {
+		merged = merged[:pageSize]
+		more = true
+	}
+
+	responses := make([]models.PostResponse, 0, len(merged))
+	for _, p := range merged {
+		responses = append(responses, FormatPost(p, userID))
+	}
+	if !more || len(merged) == 0 {
+		return responses, "", nil // 没有更多数据
+	}
+	last := merged[len(merged)-1]
+	return responses, FeedCursor{PostTime: last.PostTime.UnixMilli(), PostID: last.ID}.String(), nil
+}
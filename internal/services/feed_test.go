@@ -0,0 +1,104 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+	"time"
+)
+
+func setupFeedTest(t *testing.T) (author, reader models.User) {
+	t.Helper()
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.Post{}, &models.Follow{}, &models.Like{}, &models.Bookmark{},
+		&models.Attachment{}, &models.Poll{}, &models.PollOption{}, &models.PollVote{})
+
+	author = models.User{Username: "author", Password: "x", UserType: models.StudentRole}
+	reader = models.User{Username: "reader", Password: "x", UserType: models.StudentRole}
+	database.DB.Create(&author)
+	database.DB.Create(&reader)
+	return author, reader
+}
+
+// createFeedTestPosts 创建 n 个同一毫秒发布的帖子
+func createFeedTestPosts(t *testing.T, authorID, boardID uint, n int) []models.Post {
+	t.Helper()
+	postTime := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+	posts := make([]models.Post, n)
+	for i := range posts {
+		posts[i] = models.Post{Content: "内容", UserID: authorID, BoardID: boardID, PostTime: postTime}
+		if err := database.DB.Create(&posts[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+		database.DB.Model(&posts[i]).Update("status", models.PostStatusPublished)
+	}
+	return posts
+}
+
+// readWholeFeed 按游标翻完整个动态，返回依次读到的帖子ID
+func readWholeFeed(t *testing.T, userID uint, pageSize int) []uint {
+	t.Helper()
+	var ids []uint
+	cursor := FeedCursor{}
+	for page := 0; page < 20; page++ {
+		list, next, err := GetFeed(userID, cursor, pageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range list {
+			ids = append(ids, p.ID)
+		}
+		if next == "" {
+			return ids
+		}
+		cursor = ParseFeedCursor(next)
+	}
+	t.Fatal("翻页没有结束")
+	return nil
+}
+
+func assertFeedOrder(t *testing.T, got []uint, posts []models.Post) {
+	t.Helper()
+	if len(got) != len(posts) {
+		t.Fatalf("应读到全部 %d 个帖子且不重复，实际 %v", len(posts), got)
+	}
+	for i, id := range got {
+		if want := posts[len(posts)-1-i].ID; id != want {
+			t.Fatalf("同一时间的帖子应按ID倒序: got %v", got)
+		}
+	}
+}
+
+func TestFeedTimelinePagesThroughSameMillisecond(t *testing.T) {
+	author, reader := setupFeedTest(t)
+	posts := createFeedTestPosts(t, author.ID, 0, 5)
+	if err := addToTimelines([]uint{reader.ID}, posts); err != nil {
+		t.Fatal(err)
+	}
+	assertFeedOrder(t, readWholeFeed(t, reader.ID, 2), posts)
+}
+
+func TestFeedBoardPagesThroughSameMillisecond(t *testing.T) {
+	author, reader := setupFeedTest(t)
+	posts := createFeedTestPosts(t, author.ID, 3, 5)
+	database.DB.Create(&models.Follow{UserID: reader.ID, TargetType: models.FollowTargetBoard, TargetID: 3})
+	assertFeedOrder(t, readWholeFeed(t, reader.ID, 2), posts)
+}
+
+func TestParseFeedCursor(t *testing.T) {
+	cases := map[string]FeedCursor{
+		"":                {},
+		"abc":             {},
+		"1723600000000":   {PostTime: 1723600000000},
+		"1723600000000_7": {PostTime: 1723600000000, PostID: 7},
+	}
+	for raw, want := range cases {
+		if got := ParseFeedCursor(raw); got != want {
+			t.Errorf("ParseFeedCursor(%q) = %+v, want %+v", raw, got, want)
+		}
+	}
+	if s := (FeedCursor{PostTime: 1, PostID: 2}).String(); s != "1_2" {
+		t.Errorf("String() = %q", s)
+	}
+}
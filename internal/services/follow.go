@@ -0,0 +1,178 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+)
+
+// validateFollowTarget 校验关注对象是否存在
+func validateFollowTarget(userID uint, targetType int, targetID uint) *models.ServiceError {
+	switch targetType {
+	case models.FollowTargetUser:
+		if targetID == userID {
+			return &models.ServiceError{Code: 1001, Message: "不能关注自己"}
+		}
+		if user, err := GetUserByID(targetID); err != nil || user.IsClosed() {
+			return &models.ServiceError{Code: 1002, Message: "用户不存在"}
+		}
+	case models.FollowTargetBoard:
+		if targetID == 0 || !BoardExists(targetID) {
+			return &models.ServiceError{Code: 1003, Message: "版块不存在"}
+		}
+	default:
+		return &models.ServiceError{Code: 1004, Message: "关注类型无效"}
+	}
+	return nil
+}
+
+// Follow 关注用户或版块，关注普通用户时将其近期帖子回填到时间线
+func Follow(userID uint, targetType int, targetID uint) *models.ServiceError {
+	if serviceErr := validateFollowTarget(userID, targetType, targetID); serviceErr != nil {
+		return serviceErr
+	}
+
+	follow := models.Follow{UserID: userID, TargetType: targetType, TargetID: targetID}
+	result := database.DB.Where(follow).FirstOrCreate(&follow)
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1005, Message: "关注失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return nil // 已关注
+	}
+
+	if targetType == models.FollowTargetUser && !isFanoutOnRead(targetID) {
+		backfillTimeline(userID, targetID)
+	}
+	return nil
+}
+
+// Unfollow 取消关注，并从时间线中移除该用户的帖子
+func Unfollow(userID uint, targetType int, targetID uint) *models.ServiceError {
+	result := database.DB.Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+		Delete(&models.Follow{})
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1001, Message: "取消关注失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1002, Message: "未关注"}
+	}
+
+	if targetType == models.FollowTargetUser {
+		removeFromTimeline(userID, targetID)
+	}
+	return nil
+}
+
+// IsFollowing 判断用户是否已关注对象
+func IsFollowing(userID uint, targetType int, targetID uint) bool {
+	var count int64
+	database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+		Count(&count)
+	return count > 0
+}
+
+// GetFollowerCount 获取用户的粉丝数
+func GetFollowerCount(userID uint) int64 {
+	var count int64
+	database.DB.Model(&models.Follow{}).
+		Where("target_type = ? AND target_id = ?", models.FollowTargetUser, userID).
+		Count(&count)
+	return count
+}
+
+// GetFollowCounts 获取用户的粉丝数、关注用户数和关注版块数
+func GetFollowCounts(userID uint) (followers, followingUsers, followingBoards int64) {
+	followers = GetFollowerCount(userID)
+	database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND target_type = ?", userID, models.FollowTargetUser).
+		Count(&followingUsers)
+	database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND target_type = ?", userID, models.FollowTargetBoard).
+		Count(&followingBoards)
+	return
+}
+
+// getFollowerIDs 获取用户的全部粉丝ID
+func getFollowerIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := database.DB.Model(&models.Follow{}).
+		Where("target_type = ? AND target_id = ?", models.FollowTargetUser, userID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// getFollowingIDs 获取用户关注的某类对象的全部ID
+func getFollowingIDs(userID uint, targetType int) ([]uint, error) {
+	var ids []uint
+	err := database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND target_type = ?", userID, targetType).
+		Pluck("target_id", &ids).Error
+	return ids, err
+}
+
+func toFollowUserItems(follows []models.Follow, userIDOf func(models.Follow) uint) []models.FollowUserItem {
+	items := make([]models.FollowUserItem, 0, len(follows))
+	for _, f := range follows {
+		item := models.FollowUserItem{
+			UserID: userIDOf(f),
+			Time:   f.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+		}
+		if user, err := GetUserByID(item.UserID); err == nil {
+			item.Username = user.Username
+			item.Name = user.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// GetFollowers 分页获取用户的粉丝列表
+func GetFollowers(userID uint, page, pageSize int) ([]models.FollowUserItem, int64, error) {
+	query := database.DB.Model(&models.Follow{}).
+		Where("target_type = ? AND target_id = ?", models.FollowTargetUser, userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var follows []models.Follow
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&follows).Error; err != nil {
+		return nil, 0, err
+	}
+	return toFollowUserItems(follows, func(f models.Follow) uint { return f.UserID }), total, nil
+}
+
+// GetFollowingUsers 分页获取用户关注的用户列表
+func GetFollowingUsers(userID uint, page, pageSize int) ([]models.FollowUserItem, int64, error) {
+	query := database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND target_type = ?", userID, models.FollowTargetUser)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var follows []models.Follow
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&follows).Error; err != nil {
+		return nil, 0, err
+	}
+	return toFollowUserItems(follows, func(f models.Follow) uint { return f.TargetID }), total, nil
+}
+
+// GetFollowingBoards 获取用户关注的版块列表
+func GetFollowingBoards(userID uint) ([]models.Board, error) {
+	ids, err := getFollowingIDs(userID, models.FollowTargetBoard)
+	if err != nil {
+		return nil, err
+	}
+	boards := make([]models.Board, 0, len(ids))
+	if len(ids) == 0 {
+		return boards, nil
+	}
+	if err := database.DB.Where("id IN ?", ids).Order("id").Find(&boards).Error; err != nil {
+		logger.GetLogger().Errorf("获取关注版块失败: user_id=%d, err=%v", userID, err)
+		return nil, err
+	}
+	return boards, nil
+}
@@ -102,6 +102,15 @@ func ToggleLike(postID, userID uint) (map[string]interface{}, *models.ServiceErr
 		}
 	}
 
+	// 只能给已发布的帖子点赞，否则草稿、待审核帖子也会触发通知、Webhook 和声望变化
+	post, err := GetPostByID(postID)
+	if err != nil || post.Status != models.PostStatusPublished {
+		return nil, &models.ServiceError{
+			Code:    1009,
+			Message: "帖子不存在",
+		}
+	}
+
 	ctx := context.Background()
 	postKey := postLikesKey + strconv.Itoa(int(postID))
 	userKey := userLikesKey + strconv.Itoa(int(userID)) // hash key：user:likes:{userID}
@@ -188,6 +197,13 @@ func ToggleLike(postID, userID uint) (map[string]interface{}, *models.ServiceErr
 		}
 	}
 
+	onLikeToggled(postID, userID, !isLiked)
+
+	// 点赞（非取消）时通知帖子作者
+	if !isLiked {
+		NotifyPostLiked(postID, userID)
+	}
+
 	// 5. 获取最新点赞数（带滑动过期）
 	likes, err := GetLikesByPostID(postID)
 	if err != nil {
@@ -197,6 +213,22 @@ func ToggleLike(postID, userID uint) (map[string]interface{}, *models.ServiceErr
 		}
 	}
 
+	// 推送点赞数变化给订阅了该帖子的连接
+	PublishEvent(models.EventTypeLikeCount, 0, postID, map[string]interface{}{
+		"post_id": postID,
+		"likes":   likes,
+	})
+
+	webhookEvent := models.WebhookEventPostLiked
+	if isLiked {
+		webhookEvent = models.WebhookEventPostUnliked
+	}
+	EmitWebhookEvent(webhookEvent, map[string]interface{}{
+		"post_id": postID,
+		"user_id": userID,
+		"likes":   likes,
+	})
+
 	// 构造响应
 	response := map[string]interface{}{
 		"likes":    likes,
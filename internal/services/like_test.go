@@ -0,0 +1,144 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func setupLikeTest(t *testing.T) (author, liker models.User) {
+	t.Helper()
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.Post{}, &models.Like{}, &models.Notification{},
+		&models.NotificationMute{}, &models.Webhook{}, &models.WebhookDelivery{})
+
+	author = models.User{Username: "author", Password: "x", UserType: models.StudentRole}
+	liker = models.User{Username: "liker", Password: "x", UserType: models.StudentRole}
+	for _, u := range []*models.User{&author, &liker} {
+		if err := database.DB.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return author, liker
+}
+
+func createLikeTestPost(t *testing.T, authorID uint, status int) models.Post {
+	t.Helper()
+	post := models.Post{Content: "内容", UserID: authorID}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(&post).Update("status", status)
+	return post
+}
+
+func reloadUser(t *testing.T, id uint) models.User {
+	t.Helper()
+	var u models.User
+	if err := database.DB.First(&u, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestToggleLikePublishedPost(t *testing.T) {
+	author, liker := setupLikeTest(t)
+	post := createLikeTestPost(t, author.ID, models.PostStatusPublished)
+
+	result, serviceErr := ToggleLike(post.ID, liker.ID)
+	if serviceErr != nil || result["is_liked"] != true || result["likes"] != 1 {
+		t.Fatalf("点赞失败: %v, %v", result, serviceErr)
+	}
+	if got := reloadUser(t, author.ID).Reputation; got != ReputationLikeReceived {
+		t.Fatalf("作者声望应增加 %d，实际 %d", ReputationLikeReceived, got)
+	}
+
+	result, serviceErr = ToggleLike(post.ID, liker.ID)
+	if serviceErr != nil || result["is_liked"] != false || result["likes"] != 0 {
+		t.Fatalf("取消点赞失败: %v, %v", result, serviceErr)
+	}
+	if got := reloadUser(t, author.ID).Reputation; got != 0 {
+		t.Fatalf("取消点赞后声望应恢复，实际 %d", got)
+	}
+}
+
+func TestToggleLikeRejectsUnpublishedPost(t *testing.T) {
+	author, liker := setupLikeTest(t)
+	statuses := []int{models.PostStatusDraft, models.PostStatusScheduled, models.PostStatusPending}
+
+	for _, status := range statuses {
+		post := createLikeTestPost(t, author.ID, status)
+		if _, serviceErr := ToggleLike(post.ID, liker.ID); serviceErr == nil || serviceErr.Code != 1009 {
+			t.Fatalf("status=%d 的帖子不能点赞: %v", status, serviceErr)
+		}
+	}
+	if _, serviceErr := ToggleLike(9999, liker.ID); serviceErr == nil || serviceErr.Code != 1009 {
+		t.Fatalf("不存在的帖子不能点赞: %v", serviceErr)
+	}
+
+	var likes, notifications int64
+	database.DB.Model(&models.Like{}).Count(&likes)
+	database.DB.Model(&models.Notification{}).Count(&notifications)
+	if likes != 0 || notifications != 0 {
+		t.Fatalf("不应产生点赞或通知: likes=%d, notifications=%d", likes, notifications)
+	}
+	if got := reloadUser(t, author.ID).Reputation; got != 0 {
+		t.Fatalf("作者声望不应变化，实际 %d", got)
+	}
+}
+
+func TestLikeNotificationCountsDistinctLikers(t *testing.T) {
+	author, liker := setupLikeTest(t)
+	other := models.User{Username: "other", Password: "x", UserType: models.StudentRole}
+	database.DB.Create(&other)
+	post := createLikeTestPost(t, author.ID, models.PostStatusPublished)
+
+	// 反复取消再点赞只计一次
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			if _, serviceErr := ToggleLike(post.ID, liker.ID); serviceErr != nil {
+				t.Fatal(serviceErr)
+			}
+		}
+	}
+	ToggleLike(post.ID, liker.ID)
+	ToggleLike(post.ID, other.ID)
+
+	var notifications []models.Notification
+	database.DB.Where("user_id = ? AND type = ?", author.ID, models.NotificationTypeLike).Find(&notifications)
+	if len(notifications) != 1 {
+		t.Fatalf("应聚合为1条通知，实际 %d", len(notifications))
+	}
+	if n := notifications[0]; n.ActorCount != 2 || n.Content != "2人赞了你的帖子" {
+		t.Fatalf("应按不同的点赞人计数: %+v", n)
+	}
+}
+
+func TestConcurrentFirstLikesCreateOneNotification(t *testing.T) {
+	author, _ := setupLikeTest(t)
+	post := createLikeTestPost(t, author.ID, models.PostStatusPublished)
+
+	likers := make([]models.User, 5)
+	for i := range likers {
+		likers[i] = models.User{Username: "liker" + strconv.Itoa(i+1), Password: "x", UserType: models.StudentRole}
+		database.DB.Create(&likers[i])
+	}
+	var wg sync.WaitGroup
+	for _, liker := range likers {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			NotifyPostLiked(post.ID, id)
+		}(liker.ID)
+	}
+	wg.Wait()
+
+	var notifications []models.Notification
+	database.DB.Where("user_id = ? AND type = ?", author.ID, models.NotificationTypeLike).Find(&notifications)
+	if len(notifications) != 1 || notifications[0].ActorCount != len(likers) {
+		t.Fatalf("并发的首次点赞应聚合为1条通知: %+v", notifications)
+	}
+}
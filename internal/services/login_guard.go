@@ -0,0 +1,214 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Redis 键名定义
+const (
+	loginFailKey  = "login:fail:"  // 登录失败次数：string类型，key 为 login:fail:{scope}:{value}
+	loginDelayKey = "login:delay:" // 账号需等待的重试间隔：string类型，随过期自动解除
+	loginLockKey  = "login:lock:"  // 登录锁定：string类型，值为锁定时间戳，随过期自动解除
+
+	maxLoginUsernameLength = 32 // 超过该长度的账号不可能存在，直接按失败处理，避免产生过长的键
+)
+
+// incrFailureScript 失败次数加一，首次失败时设置统计窗口（毫秒）
+var incrFailureScript = goredis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+func loginKey(prefix, scope, value string) string {
+	return prefix + scope + ":" + value
+}
+
+// validLoginUsername 过长的账号不可能存在，不按账号计数，只计入 IP
+func validLoginUsername(username string) bool {
+	return username != "" && len(username) <= maxLoginUsernameLength
+}
+
+// CheckLoginAllowed 登录前检查账号和 IP 是否处于锁定或等待状态
+func CheckLoginAllowed(username, ip string) *models.ServiceError {
+	ctx := context.Background()
+	targets := [][2]string{{models.LoginLockScopeIP, ip}}
+	if validLoginUsername(username) {
+		targets = append(targets, [2]string{models.LoginLockScopeUser, username})
+	}
+	for _, target := range targets {
+		ttl, err := redis.RedisClient.TTL(ctx, loginKey(loginLockKey, target[0], target[1])).Result()
+		if err == nil && ttl > 0 {
+			return &models.ServiceError{Code: 200508, Message: fmt.Sprintf("登录失败次数过多，请%d分钟后再试", int(ttl.Minutes())+1)}
+		}
+	}
+	ttl, err := redis.RedisClient.PTTL(ctx, loginKey(loginDelayKey, models.LoginLockScopeUser, username)).Result()
+	if err == nil && ttl > 0 {
+		return &models.ServiceError{Code: 200509, Message: fmt.Sprintf("尝试过于频繁，请%d秒后再试", int(ttl.Seconds())+1)}
+	}
+	return nil
+}
+
+func incrFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return incrFailureScript.Run(ctx, redis.RedisClient, []string{key}, window.Milliseconds()).Int64()
+}
+
+// RecordLoginFailure 记录一次登录失败：同一账号失败较多时要求递增的等待间隔，
+// 账号或 IP 失败达到上限时临时锁定并记入审计日志。不存在的账号同样计数，避免据此探测账号是否存在
+func RecordLoginFailure(username, ip string) {
+	cfg := config.LoadedConfig.Login
+	ctx := context.Background()
+	window := time.Duration(cfg.WindowMinutes) * time.Minute
+
+	var userFails int64
+	if validLoginUsername(username) {
+		var err error
+		userFails, err = incrFailure(ctx, loginKey(loginFailKey, models.LoginLockScopeUser, username), window)
+		if err != nil {
+			logger.GetLogger().Errorf("记录登录失败次数失败: username=%s, err=%v", username, err)
+		}
+	}
+	ipFails, err := incrFailure(ctx, loginKey(loginFailKey, models.LoginLockScopeIP, ip), window)
+	if err != nil {
+		logger.GetLogger().Errorf("记录登录失败次数失败: ip=%s, err=%v", ip, err)
+	}
+
+	switch {
+	case userFails == 0: // 未按账号计数
+	case userFails >= int64(cfg.MaxUserFailures):
+		lockLogin(models.LoginLockScopeUser, username, ip, userFails)
+	case userFails >= int64(cfg.DelayAfter):
+		// 等待间隔按 1、2、4、8… 秒递增
+		delay := time.Duration(cfg.MaxDelaySeconds) * time.Second
+		if shift := userFails - int64(cfg.DelayAfter); shift < 16 {
+			delay = min(delay, time.Second<<shift)
+		}
+		redis.RedisClient.Set(ctx, loginKey(loginDelayKey, models.LoginLockScopeUser, username), 1, delay)
+	}
+	if ipFails >= int64(cfg.MaxIPFailures) {
+		lockLogin(models.LoginLockScopeIP, ip, ip, ipFails)
+	}
+}
+
+// lockLogin 锁定账号或 IP，清空失败计数，并写入审计日志
+func lockLogin(scope, value, ip string, failures int64) {
+	ctx := context.Background()
+	lockTTL := time.Duration(config.LoadedConfig.Login.LockMinutes) * time.Minute
+	ok, err := redis.RedisClient.SetNX(ctx, loginKey(loginLockKey, scope, value), time.Now().Unix(), lockTTL).Result()
+	if err != nil {
+		logger.GetLogger().Errorf("锁定登录失败: scope=%s, value=%s, err=%v", scope, value, err)
+		return
+	}
+	redis.RedisClient.Del(ctx, loginKey(loginFailKey, scope, value), loginKey(loginDelayKey, scope, value))
+	if !ok {
+		return // 已处于锁定状态
+	}
+
+	var targetID uint
+	if scope == models.LoginLockScopeUser {
+		if user, err := GetUserByUsername(value); err == nil {
+			targetID = user.ID
+		}
+	}
+	detail, _ := json.Marshal(map[string]interface{}{
+		"scope":        scope,
+		"value":        value,
+		"ip":           ip,
+		"failures":     failures,
+		"lock_minutes": config.LoadedConfig.Login.LockMinutes,
+	})
+	// 系统自动触发的事件 AdminID 记为0
+	if err := database.DB.Create(&models.AuditLog{
+		AdminID:  0,
+		Action:   "login_lockout",
+		TargetID: targetID,
+		Detail:   string(detail),
+	}).Error; err != nil {
+		logger.GetLogger().Errorf("记录登录锁定审计日志失败: scope=%s, value=%s, err=%v", scope, value, err)
+	}
+	logger.GetLogger().Infof("登录失败次数过多，已锁定: scope=%s, value=%s, failures=%d", scope, value, failures)
+}
+
+// ClearLoginFailures 登录成功后清除该账号的失败计数；IP 的计数保留，避免用一个已知账号重置 IP 限制
+func ClearLoginFailures(username string) {
+	ctx := context.Background()
+	redis.RedisClient.Del(ctx,
+		loginKey(loginFailKey, models.LoginLockScopeUser, username),
+		loginKey(loginDelayKey, models.LoginLockScopeUser, username))
+}
+
+// GetLoginLockouts 获取当前所有生效的登录锁定
+func GetLoginLockouts() ([]models.LoginLockout, error) {
+	ctx := context.Background()
+	lockouts := make([]models.LoginLockout, 0)
+	iter := redis.RedisClient.Scan(ctx, 0, loginLockKey+"*", 200).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		scope, value, ok := strings.Cut(strings.TrimPrefix(key, loginLockKey), ":")
+		if !ok {
+			continue
+		}
+		lockedAtStr, err := redis.RedisClient.Get(ctx, key).Result()
+		if err != nil {
+			continue // 扫描期间已过期
+		}
+		ttl, _ := redis.RedisClient.TTL(ctx, key).Result()
+		lockedAt, _ := strconv.ParseInt(lockedAtStr, 10, 64)
+		lockouts = append(lockouts, models.LoginLockout{
+			Scope:     scope,
+			Value:     value,
+			LockedAt:  time.Unix(lockedAt, 0).Format("2006-01-02T15:04:05.000-07:00"),
+			ExpiresIn: int64(ttl.Seconds()),
+		})
+	}
+	return lockouts, iter.Err()
+}
+
+// ClearLoginLockout 管理员解除登录锁定，同时清空失败计数，并写入审计日志
+func ClearLoginLockout(adminID uint, scope, value string) *models.ServiceError {
+	if scope != models.LoginLockScopeUser && scope != models.LoginLockScopeIP {
+		return &models.ServiceError{Code: 1001, Message: "无效的锁定类型"}
+	}
+	ctx := context.Background()
+	deleted, err := redis.RedisClient.Del(ctx,
+		loginKey(loginLockKey, scope, value),
+		loginKey(loginFailKey, scope, value),
+		loginKey(loginDelayKey, scope, value)).Result()
+	if err != nil {
+		return &models.ServiceError{Code: 1002, Message: "解除锁定失败: " + err.Error()}
+	}
+	if deleted == 0 {
+		return &models.ServiceError{Code: 1003, Message: "未找到该锁定记录"}
+	}
+
+	var targetID uint
+	if scope == models.LoginLockScopeUser {
+		if user, err := GetUserByUsername(value); err == nil {
+			targetID = user.ID
+		}
+	}
+	detail, _ := json.Marshal(map[string]string{"scope": scope, "value": value})
+	if err := database.DB.Create(&models.AuditLog{
+		AdminID:  adminID,
+		Action:   "clear_login_lockout",
+		TargetID: targetID,
+		Detail:   string(detail),
+	}).Error; err != nil {
+		logger.GetLogger().Errorf("记录解除锁定审计日志失败: scope=%s, value=%s, err=%v", scope, value, err)
+	}
+	return nil
+}
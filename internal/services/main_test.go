@@ -0,0 +1,78 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestConfig 设置测试用的全局配置，返回的指针可在测试中继续修改
+func setupTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	prev := config.LoadedConfig
+	cfg := &config.Config{}
+	cfg.JWT.SecretKey = "test-secret"
+	cfg.JWT.ExpirationHours = 24
+	cfg.Storage.SigningKey = "test-signing-key-0123456789abcdef"
+	cfg.Webhook.MaxAttempts = 3
+	cfg.Webhook.TimeoutSeconds = 5
+	cfg.Webhook.BaseDelaySeconds = 30
+	cfg.Login.WindowMinutes = 15
+	cfg.Login.DelayAfter = 100
+	cfg.Login.MaxDelaySeconds = 60
+	cfg.Login.MaxUserFailures = 5
+	cfg.Login.MaxIPFailures = 50
+	cfg.Login.LockMinutes = 15
+	cfg.Post.AnonymousPerDay = 3
+	cfg.Reputation.TrustedThreshold = 50
+	cfg.Feed.FanoutThreshold = 1000
+	cfg.Feed.TimelineSize = 800
+	config.LoadedConfig = cfg
+	t.Cleanup(func() { config.LoadedConfig = prev })
+	return cfg
+}
+
+// setupTestDB 使用内存 SQLite 替换全局数据库连接，并迁移测试需要的表
+func setupTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	// 内存数据库每个连接相互独立，限制为单连接保证所有查询看到同一份数据
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("迁移测试数据库失败: %v", err)
+	}
+
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = prev
+		sqlDB.Close()
+	})
+	return db
+}
+
+// setupTestRedis 启动内存 Redis 并替换全局客户端
+func setupTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	prev := redis.RedisClient
+	redis.RedisClient = goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redis.RedisClient.Close()
+		redis.RedisClient = prev
+	})
+	return mr
+}
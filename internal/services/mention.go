@@ -0,0 +1,135 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/markdown"
+	"fmt"
+	"strings"
+)
+
+const (
+	maxMentionsPerPost    = 20 // 单个帖子最多通知的提及人数
+	maxUserSuggestions    = 20 // 自动补全最多返回的用户数
+	defaultUserSuggestion = 10
+)
+
+// resolveMentionedUsers 将提及的名称解析为用户：优先匹配学号（用户名），
+// 其次匹配姓名，姓名重复时无法确定具体用户，不做解析
+func resolveMentionedUsers(names []string) []models.User {
+	if len(names) == 0 {
+		return nil
+	}
+	var candidates []models.User
+	if err := database.DB.Where("username IN ? OR name IN ?", names, names).Where("closed_at IS NULL").Find(&candidates).Error; err != nil {
+		logger.GetLogger().Errorf("解析提及用户失败: %v", err)
+		return nil
+	}
+
+	byUsername := make(map[string]models.User)
+	byName := make(map[string][]models.User)
+	for _, u := range candidates {
+		byUsername[u.Username] = u
+		byName[u.Name] = append(byName[u.Name], u)
+	}
+
+	seen := make(map[uint]bool)
+	var users []models.User
+	for _, name := range names {
+		u, ok := byUsername[name]
+		if !ok {
+			if matched := byName[name]; len(matched) == 1 {
+				u, ok = matched[0], true
+			}
+		}
+		if ok && !seen[u.ID] {
+			seen[u.ID] = true
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// syncPostMentions 根据帖子内容更新提及记录，只通知新增的被提及用户；
+// 屏蔽了作者的用户不会被记录和通知
+func syncPostMentions(post models.Post) {
+	names := markdown.ExtractMentions(post.ContentHTML)
+	if len(names) > maxMentionsPerPost {
+		names = names[:maxMentionsPerPost]
+	}
+
+	keep := make([]uint, 0, len(names))
+	for _, u := range resolveMentionedUsers(names) {
+		if u.ID == post.UserID || IsUserBlocked(u.ID, post.UserID) {
+			continue
+		}
+		keep = append(keep, u.ID)
+	}
+
+	var existing []models.Mention
+	if err := database.DB.Where("post_id = ?", post.ID).Find(&existing).Error; err != nil {
+		logger.GetLogger().Errorf("获取帖子提及记录失败: post_id=%d, err=%v", post.ID, err)
+		return
+	}
+	existed := make(map[uint]bool, len(existing))
+	for _, m := range existing {
+		existed[m.UserID] = true
+	}
+
+	// 删除修改后不再提及的用户
+	remove := database.DB.Where("post_id = ?", post.ID)
+	if len(keep) > 0 {
+		remove = remove.Where("user_id NOT IN ?", keep)
+	}
+	if err := remove.Delete(&models.Mention{}).Error; err != nil {
+		logger.GetLogger().Errorf("删除帖子提及记录失败: post_id=%d, err=%v", post.ID, err)
+	}
+
+	authorName := post.Pseudonym
+	if !post.IsAnonymous {
+		if author, err := GetUserByID(post.UserID); err == nil {
+			authorName = author.Name
+		}
+	}
+	for _, userID := range keep {
+		if existed[userID] {
+			continue
+		}
+		mention := models.Mention{PostID: post.ID, UserID: userID, AuthorID: post.UserID}
+		if err := database.DB.Create(&mention).Error; err != nil {
+			logger.GetLogger().Errorf("保存提及记录失败: post_id=%d, user_id=%d, err=%v", post.ID, userID, err)
+			continue
+		}
+		content := fmt.Sprintf("%s 在帖子中提到了你", authorName)
+		if err := Notify(userID, models.NotificationTypeMention, post.ID, content); err != nil {
+			logger.GetLogger().Errorf("发送提及通知失败: post_id=%d, user_id=%d, err=%v", post.ID, userID, err)
+		}
+	}
+}
+
+// SearchUsersByPrefix 按学号或姓名前缀搜索用户，用于 @提及 自动补全
+func SearchUsersByPrefix(prefix string, limit int) ([]models.UserSuggestion, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" {
+		return []models.UserSuggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultUserSuggestion
+	}
+	if limit > maxUserSuggestions {
+		limit = maxUserSuggestions
+	}
+
+	// 转义 LIKE 通配符，避免用户输入 % 或 _ 匹配任意内容
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+	var suggestions []models.UserSuggestion
+	err := database.DB.Model(&models.User{}).
+		Select("id", "username", "name").
+		Where("username LIKE ? OR name LIKE ?", escaped+"%", escaped+"%").
+		Where("closed_at IS NULL").
+		Order("username").
+		Limit(limit).
+		Find(&suggestions).Error
+	return suggestions, err
+}
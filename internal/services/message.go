@@ -0,0 +1,270 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const maxMessageLength = 2000 // 单条私信最大字符数
+
+// getOrCreateConversation 获取两人之间的会话，不存在时创建
+func getOrCreateConversation(userID, peerID uint) (*models.Conversation, error) {
+	a, b := userID, peerID
+	if a > b {
+		a, b = b, a
+	}
+	var conversation models.Conversation
+	err := database.DB.Where(models.Conversation{UserAID: a, UserBID: b}).
+		Attrs(models.Conversation{LastMessageAt: time.Now()}).
+		FirstOrCreate(&conversation).Error
+	return &conversation, err
+}
+
+// getConversationForUser 获取会话并校验用户是会话成员
+func getConversationForUser(conversationID, userID uint) (*models.Conversation, *models.ServiceError) {
+	var conversation models.Conversation
+	if err := database.DB.First(&conversation, conversationID).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1001, Message: "会话不存在"}
+	}
+	if !conversation.HasMember(userID) {
+		return nil, &models.ServiceError{Code: 1002, Message: "无权访问该会话"}
+	}
+	return &conversation, nil
+}
+
+// SendMessage 发送私信，接收方屏蔽了发送方时拒绝发送
+func SendMessage(senderID, receiverID uint, content string) (*models.MessageResponse, *models.ServiceError) {
+	content = strings.TrimSpace(content)
+	if content == "" || len([]rune(content)) > maxMessageLength {
+		return nil, &models.ServiceError{Code: 1001, Message: fmt.Sprintf("消息内容需为1-%d个字符", maxMessageLength)}
+	}
+	if senderID == receiverID {
+		return nil, &models.ServiceError{Code: 1002, Message: "不能给自己发私信"}
+	}
+	if receiver, err := GetUserByID(receiverID); err != nil || receiver.IsClosed() {
+		return nil, &models.ServiceError{Code: 1003, Message: "接收用户不存在"}
+	}
+	if IsUserBlocked(receiverID, senderID) || IsUserBlocked(senderID, receiverID) {
+		return nil, &models.ServiceError{Code: 1004, Message: "无法向该用户发送私信"}
+	}
+
+	conversation, err := getOrCreateConversation(senderID, receiverID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1005, Message: "创建会话失败: " + err.Error()}
+	}
+
+	message := models.Message{
+		ConversationID: conversation.ID,
+		SenderID:       senderID,
+		Content:        content,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		// 发送方自己的消息视为已读
+		readColumn := "user_a_last_read"
+		if conversation.UserBID == senderID {
+			readColumn = "user_b_last_read"
+		}
+		return tx.Model(conversation).Updates(map[string]interface{}{
+			"last_message_at": message.CreatedAt,
+			readColumn:        message.ID,
+		}).Error
+	})
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1006, Message: "发送失败: " + err.Error()}
+	}
+
+	response := message.ToResponse()
+	PublishEvent(models.EventTypeMessage, receiverID, 0, response)
+	return &response, nil
+}
+
+// GetConversations 获取用户的会话列表，按最近消息时间倒序
+func GetConversations(userID uint, page, pageSize int) ([]models.ConversationResponse, int64, error) {
+	query := database.DB.Model(&models.Conversation{}).Where("user_a_id = ? OR user_b_id = ?", userID, userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var conversations []models.Conversation
+	err := query.Order("last_message_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.ConversationResponse, 0, len(conversations))
+	for _, conv := range conversations {
+		peerID := conv.Peer(userID)
+		item := models.ConversationResponse{
+			ID:           conv.ID,
+			PeerID:       peerID,
+			PeerLastRead: conv.LastReadBy(peerID),
+		}
+		if peer, err := GetUserByID(peerID); err == nil {
+			item.PeerName = peer.Name
+		}
+
+		var last models.Message
+		if err := database.DB.Where("conversation_id = ?", conv.ID).Order("id DESC").First(&last).Error; err == nil {
+			resp := last.ToResponse()
+			item.LastMessage = &resp
+		}
+		database.DB.Model(&models.Message{}).
+			Where("conversation_id = ? AND id > ? AND sender_id <> ?", conv.ID, conv.LastReadBy(userID), userID).
+			Count(&item.UnreadCount)
+
+		responses = append(responses, item)
+	}
+	return responses, total, nil
+}
+
+// GetMessages 分页获取会话消息（新消息在前），返回对方已读到的消息ID作为已读回执
+func GetMessages(conversationID, userID uint, page, pageSize int) ([]models.MessageResponse, int64, uint, *models.ServiceError) {
+	conversation, serviceErr := getConversationForUser(conversationID, userID)
+	if serviceErr != nil {
+		return nil, 0, 0, serviceErr
+	}
+
+	list, total, err := listConversationMessages(conversationID, page, pageSize)
+	if err != nil {
+		return nil, 0, 0, &models.ServiceError{Code: 1003, Message: "获取消息失败: " + err.Error()}
+	}
+	return list, total, conversation.LastReadBy(conversation.Peer(userID)), nil
+}
+
+func listConversationMessages(conversationID uint, page, pageSize int) ([]models.MessageResponse, int64, error) {
+	query := database.DB.Model(&models.Message{}).Where("conversation_id = ?", conversationID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []models.Message
+	err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.MessageResponse, 0, len(messages))
+	for _, m := range messages {
+		responses = append(responses, m.ToResponse())
+	}
+	return responses, total, nil
+}
+
+// MarkConversationRead 将会话标记为已读到最新消息，并向对方推送已读回执
+func MarkConversationRead(conversationID, userID uint) *models.ServiceError {
+	conversation, serviceErr := getConversationForUser(conversationID, userID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+
+	var last models.Message
+	err := database.DB.Where("conversation_id = ?", conversationID).Order("id DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return &models.ServiceError{Code: 1003, Message: "获取消息失败: " + err.Error()}
+	}
+	if conversation.LastReadBy(userID) >= last.ID {
+		return nil
+	}
+
+	readColumn := "user_a_last_read"
+	if conversation.UserBID == userID {
+		readColumn = "user_b_last_read"
+	}
+	if err := database.DB.Model(conversation).Update(readColumn, last.ID).Error; err != nil {
+		return &models.ServiceError{Code: 1004, Message: "标记已读失败: " + err.Error()}
+	}
+
+	PublishEvent(models.EventTypeMessageRead, conversation.Peer(userID), 0, map[string]interface{}{
+		"conversation_id": conversationID,
+		"reader_id":       userID,
+		"last_read":       last.ID,
+	})
+	return nil
+}
+
+// GetMessageByID 根据ID获取私信
+func GetMessageByID(id uint) (message models.Message, err error) {
+	err = database.DB.First(&message, id).Error
+	return
+}
+
+// ReportMessage 举报私信，只有会话成员可以举报，举报记录进入统一的审核队列
+func ReportMessage(userID, messageID uint, reason string) *models.ServiceError {
+	message, err := GetMessageByID(messageID)
+	if err != nil {
+		return &models.ServiceError{Code: 1001, Message: "私信不存在"}
+	}
+	if _, serviceErr := getConversationForUser(message.ConversationID, userID); serviceErr != nil {
+		return serviceErr
+	}
+	if message.SenderID == userID {
+		return &models.ServiceError{Code: 1003, Message: "不能举报自己发送的私信"}
+	}
+
+	err = CreateBlock(models.Block{
+		UserID:       userID,
+		TargetID:     messageID,
+		TargetType:   models.BlockTargetMessage,
+		TargetUserID: message.SenderID,
+		Reason:       reason,
+	})
+	if err != nil {
+		return &models.ServiceError{Code: 1004, Message: "举报失败: " + err.Error()}
+	}
+	return nil
+}
+
+// GetReportedConversation 管理员查看被举报私信所在的会话，仅允许在举报待审核期间查看，并记录审计日志；
+// 举报处理完成后不能再以此为由查看会话
+func GetReportedConversation(adminID, messageID uint, page, pageSize int) ([]models.MessageResponse, int64, *models.ServiceError) {
+	var count int64
+	database.DB.Model(&models.Block{}).
+		Where("target_id = ? AND target_type = ? AND status = ?", messageID, models.BlockTargetMessage, 0).
+		Count(&count)
+	if count == 0 {
+		return nil, 0, &models.ServiceError{Code: 1001, Message: "该私信没有待处理的举报，无权查看"}
+	}
+
+	message, err := GetMessageByID(messageID)
+	if err != nil {
+		return nil, 0, &models.ServiceError{Code: 1002, Message: "私信不存在"}
+	}
+
+	auditLog := models.AuditLog{
+		AdminID:  adminID,
+		Action:   "read_conversation",
+		TargetID: message.ConversationID,
+		Detail:   fmt.Sprintf(`{"message_id": %d}`, messageID),
+	}
+	if err := database.DB.Create(&auditLog).Error; err != nil {
+		return nil, 0, &models.ServiceError{Code: 1011, Message: "记录审计日志失败"}
+	}
+
+	list, total, err := listConversationMessages(message.ConversationID, page, pageSize)
+	if err != nil {
+		return nil, 0, &models.ServiceError{Code: 1003, Message: "获取消息失败: " + err.Error()}
+	}
+	return list, total, nil
+}
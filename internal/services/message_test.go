@@ -0,0 +1,43 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+)
+
+func TestGetReportedConversationRequiresPendingReport(t *testing.T) {
+	setupTestConfig(t)
+	setupTestDB(t, &models.User{}, &models.Conversation{}, &models.Message{}, &models.Block{}, &models.AuditLog{})
+
+	admin := models.User{Username: "admin", Password: "x", UserType: models.AdminRole}
+	database.DB.Create(&admin)
+	conversation := models.Conversation{UserAID: 1, UserBID: 2}
+	database.DB.Create(&conversation)
+	message := models.Message{ConversationID: conversation.ID, SenderID: 2, Content: "hi"}
+	database.DB.Create(&message)
+
+	if _, _, serviceErr := GetReportedConversation(admin.ID, message.ID, 1, 20); serviceErr == nil || serviceErr.Code != 1001 {
+		t.Fatalf("未被举报的私信不能查看: %v", serviceErr)
+	}
+
+	report := models.Block{UserID: 1, TargetID: message.ID, TargetType: models.BlockTargetMessage, TargetUserID: 2}
+	database.DB.Create(&report)
+	list, total, serviceErr := GetReportedConversation(admin.ID, message.ID, 1, 20)
+	if serviceErr != nil || total != 1 || len(list) != 1 {
+		t.Fatalf("举报待处理时应可查看: total=%d, err=%v", total, serviceErr)
+	}
+	var audits int64
+	database.DB.Model(&models.AuditLog{}).Where("action = ? AND target_id = ?", "read_conversation", conversation.ID).Count(&audits)
+	if audits != 1 {
+		t.Fatal("查看会话应写入审计日志")
+	}
+
+	// 举报处理完成后不能再查看
+	for _, status := range []int{1, 2} {
+		database.DB.Model(&report).Update("status", status)
+		if _, _, serviceErr := GetReportedConversation(admin.ID, message.ID, 1, 20); serviceErr == nil || serviceErr.Code != 1001 {
+			t.Fatalf("status=%d 的举报不能再查看会话: %v", status, serviceErr)
+		}
+	}
+}
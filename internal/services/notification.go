@@ -0,0 +1,350 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	unreadCountKey    = "notification:unread:" // 用户未读通知数：string类型
+	unreadCountExpire = 10 * time.Minute
+	likersKey         = "notification:likers:" // 点赞通知已计入的点赞人：set类型，按通知ID区分
+	likersExpire      = 30 * 24 * time.Hour
+	likeNotifyLockKey = "lock:notification:like:" // 点赞通知聚合锁：按帖子区分，避免并发的首次点赞各自创建通知
+	likeNotifyLockTTL = 5 * time.Second
+	likeNotifyLockTry = 50  // 等待聚合锁的最大次数，每次间隔 20ms
+	announcementBatch = 500 // 公告批量写入的每批条数
+)
+
+// IsNotificationTypeValid 判断通知类型是否合法
+func IsNotificationTypeValid(notifyType string) bool {
+	for _, t := range models.NotificationTypes {
+		if t == notifyType {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotificationMuted 判断用户是否屏蔽了该类型通知
+func isNotificationMuted(userID uint, notifyType string) bool {
+	var count int64
+	database.DB.Model(&models.NotificationMute{}).
+		Where("user_id = ? AND type = ?", userID, notifyType).
+		Count(&count)
+	return count > 0
+}
+
+// invalidateUnreadCount 删除未读数缓存，下次读取时从数据库重建
+func invalidateUnreadCount(userIDs ...uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, unreadCountKey+strconv.Itoa(int(id)))
+	}
+	if err := redis.RedisClient.Del(context.Background(), keys...).Err(); err != nil {
+		logger.GetLogger().Errorf("清理未读通知缓存失败: user_ids=%v, err=%v", userIDs, err)
+	}
+}
+
+// Notify 给用户发送一条通知，用户屏蔽该类型时直接忽略
+func Notify(userID uint, notifyType string, targetID uint, content string) error {
+	_, err := createNotification(userID, notifyType, targetID, content)
+	return err
+}
+
+// createNotification 创建通知并推送，用户屏蔽该类型时返回 nil
+func createNotification(userID uint, notifyType string, targetID uint, content string) (*models.Notification, error) {
+	if userID == 0 || isNotificationMuted(userID, notifyType) {
+		return nil, nil
+	}
+	notification := models.Notification{
+		UserID:     userID,
+		Type:       notifyType,
+		TargetID:   targetID,
+		Content:    content,
+		ActorCount: 1,
+	}
+	if err := database.DB.Create(&notification).Error; err != nil {
+		return nil, err
+	}
+	invalidateUnreadCount(userID)
+	PublishEvent(models.EventTypeNotification, userID, targetID, notification.ToResponse())
+	return &notification, nil
+}
+
+// addLiker 记录点赞人已计入该通知，返回是否首次计入
+func addLiker(notificationID, likerID uint) (bool, error) {
+	ctx := context.Background()
+	key := likersKey + strconv.Itoa(int(notificationID))
+	added, err := redis.RedisClient.SAdd(ctx, key, likerID).Result()
+	if err != nil {
+		return false, err
+	}
+	redis.RedisClient.Expire(ctx, key, likersExpire)
+	return added > 0, nil
+}
+
+// NotifyPostLiked 帖子被点赞时通知作者，同一帖子未读的点赞通知会被聚合，
+// 同一用户反复取消再点赞只计一次
+func NotifyPostLiked(postID, likerID uint) {
+	post, err := GetPostByID(postID)
+	if err != nil || post.UserID == likerID {
+		return
+	}
+	if isNotificationMuted(post.UserID, models.NotificationTypeLike) {
+		return
+	}
+
+	// 查找和创建未读通知需要串行，否则并发的首次点赞会各自创建一条通知
+	lockKey := likeNotifyLockKey + strconv.Itoa(int(postID))
+	var token string
+	for attempt := 0; ; attempt++ {
+		t, ok, err := redis.TryLock(lockKey, likeNotifyLockTTL)
+		if err != nil {
+			logger.GetLogger().Errorf("获取点赞通知锁失败: post_id=%d, err=%v", postID, err)
+			return
+		}
+		if ok {
+			token = t
+			break
+		}
+		if attempt >= likeNotifyLockTry {
+			logger.GetLogger().Errorf("等待点赞通知锁超时: post_id=%d", postID)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer redis.Unlock(lockKey, token)
+
+	var notification models.Notification
+	err = database.DB.Where("user_id = ? AND type = ? AND target_id = ? AND is_read = ?",
+		post.UserID, models.NotificationTypeLike, postID, false).
+		First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		created, err := createNotification(post.UserID, models.NotificationTypeLike, postID, "有人赞了你的帖子")
+		if err != nil {
+			logger.GetLogger().Errorf("创建点赞通知失败: post_id=%d, err=%v", postID, err)
+			return
+		}
+		if created != nil {
+			if _, err := addLiker(created.ID, likerID); err != nil {
+				logger.GetLogger().Errorf("记录点赞通知的点赞人失败: notification_id=%d, err=%v", created.ID, err)
+			}
+		}
+		return
+	}
+	if err != nil {
+		logger.GetLogger().Errorf("查询点赞通知失败: post_id=%d, err=%v", postID, err)
+		return
+	}
+
+	// 已计入的点赞人不再累加；Redis 不可用时宁可少计也不重复计数
+	added, err := addLiker(notification.ID, likerID)
+	if err != nil {
+		logger.GetLogger().Errorf("记录点赞通知的点赞人失败: notification_id=%d, err=%v", notification.ID, err)
+		return
+	}
+	if !added {
+		return
+	}
+
+	// 已有未读通知，原子累加人数即可，未读数不变
+	err = database.DB.Model(&models.Notification{}).Where("id = ?", notification.ID).
+		Update("actor_count", gorm.Expr("actor_count + 1")).Error
+	if err != nil {
+		logger.GetLogger().Errorf("聚合点赞通知失败: notification_id=%d, err=%v", notification.ID, err)
+		return
+	}
+	if err := database.DB.First(&notification, notification.ID).Error; err != nil {
+		logger.GetLogger().Errorf("查询点赞通知失败: notification_id=%d, err=%v", notification.ID, err)
+		return
+	}
+	// 只在人数未被并发修改时更新文案，否则由最后一次累加的请求更新
+	notification.Content = fmt.Sprintf("%d人赞了你的帖子", notification.ActorCount)
+	err = database.DB.Model(&models.Notification{}).
+		Where("id = ? AND actor_count = ?", notification.ID, notification.ActorCount).
+		Update("content", notification.Content).Error
+	if err != nil {
+		logger.GetLogger().Errorf("聚合点赞通知失败: notification_id=%d, err=%v", notification.ID, err)
+		return
+	}
+	PublishEvent(models.EventTypeNotification, post.UserID, postID, notification.ToResponse())
+}
+
+// NotifyReportResult 举报审批完成后通知举报人，帖子被删除时（authorID 非0）同时通知作者
+func NotifyReportResult(reporterID, postID, authorID uint, approval int) {
+	content := "你举报的帖子未通过审核，帖子已保留"
+	if approval == 1 {
+		content = "你举报的帖子已被管理员删除，感谢你的反馈"
+	}
+	if err := Notify(reporterID, models.NotificationTypeModeration, postID, content); err != nil {
+		logger.GetLogger().Errorf("发送举报结果通知失败: user_id=%d, post_id=%d, err=%v", reporterID, postID, err)
+	}
+
+	if approval == 1 && authorID != 0 {
+		if err := Notify(authorID, models.NotificationTypeModeration, postID, "你的帖子因被举报已被管理员删除"); err != nil {
+			logger.GetLogger().Errorf("发送帖子删除通知失败: user_id=%d, post_id=%d, err=%v", authorID, postID, err)
+		}
+	}
+}
+
+// SendAnnouncement 给所有未屏蔽公告的用户发送公告通知
+func SendAnnouncement(content string, targetID uint) (int, *models.ServiceError) {
+	var userIDs []uint
+	err := database.DB.Model(&models.User{}).
+		Where("id NOT IN (?)", database.DB.Model(&models.NotificationMute{}).
+			Select("user_id").Where("type = ?", models.NotificationTypeAnnouncement)).
+		Pluck("id", &userIDs).Error
+	if err != nil {
+		return 0, &models.ServiceError{Code: 1001, Message: "查询用户失败: " + err.Error()}
+	}
+
+	for start := 0; start < len(userIDs); start += announcementBatch {
+		end := start + announcementBatch
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		batch := make([]models.Notification, 0, end-start)
+		for _, id := range userIDs[start:end] {
+			batch = append(batch, models.Notification{
+				UserID:     id,
+				Type:       models.NotificationTypeAnnouncement,
+				TargetID:   targetID,
+				Content:    content,
+				ActorCount: 1,
+			})
+		}
+		if err := database.DB.Create(&batch).Error; err != nil {
+			return start, &models.ServiceError{Code: 1002, Message: "发送公告失败: " + err.Error()}
+		}
+		invalidateUnreadCount(userIDs[start:end]...)
+	}
+	return len(userIDs), nil
+}
+
+// GetNotifications 分页获取用户通知，unreadOnly 为 true 时只返回未读通知
+func GetNotifications(userID uint, unreadOnly bool, page, pageSize int) ([]models.NotificationResponse, int64, error) {
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	err := query.Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		responses = append(responses, n.ToResponse())
+	}
+	return responses, total, nil
+}
+
+// MarkNotificationRead 将单条通知标记为已读
+func MarkNotificationRead(userID, notificationID uint) *models.ServiceError {
+	result := database.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true)
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1001, Message: "标记已读失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		database.DB.Model(&models.Notification{}).
+			Where("id = ? AND user_id = ?", notificationID, userID).
+			Count(&count)
+		if count == 0 {
+			return &models.ServiceError{Code: 1002, Message: "通知不存在"}
+		}
+	}
+	invalidateUnreadCount(userID)
+	return nil
+}
+
+// MarkAllNotificationsRead 将用户所有通知标记为已读
+func MarkAllNotificationsRead(userID uint) error {
+	err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Update("is_read", true).Error
+	if err != nil {
+		return err
+	}
+	invalidateUnreadCount(userID)
+	return nil
+}
+
+// GetUnreadNotificationCount 获取未读通知数，优先读取Redis缓存
+func GetUnreadNotificationCount(userID uint) (int, error) {
+	key := unreadCountKey + strconv.Itoa(int(userID))
+	ctx := context.Background()
+
+	countStr, err := redis.RedisClient.Get(ctx, key).Result()
+	if err == nil {
+		count, _ := strconv.Atoi(countStr)
+		return count, nil
+	}
+
+	var count int64
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if err := redis.RedisClient.Set(ctx, key, count, unreadCountExpire).Err(); err != nil {
+		logger.GetLogger().Errorf("同步未读通知数到Redis失败: user_id=%d, err=%v", userID, err)
+	}
+	return int(count), nil
+}
+
+// GetMutedNotificationTypes 获取用户屏蔽的通知类型
+func GetMutedNotificationTypes(userID uint) ([]string, error) {
+	var types []string
+	err := database.DB.Model(&models.NotificationMute{}).
+		Where("user_id = ?", userID).
+		Pluck("type", &types).Error
+	return types, err
+}
+
+// SetNotificationMuted 设置用户是否屏蔽某类通知
+func SetNotificationMuted(userID uint, notifyType string, muted bool) *models.ServiceError {
+	if !IsNotificationTypeValid(notifyType) {
+		return &models.ServiceError{Code: 1001, Message: "无效的通知类型"}
+	}
+
+	var err error
+	if muted {
+		err = database.DB.Where(models.NotificationMute{UserID: userID, Type: notifyType}).
+			FirstOrCreate(&models.NotificationMute{}).Error
+	} else {
+		err = database.DB.Where("user_id = ? AND type = ?", userID, notifyType).
+			Delete(&models.NotificationMute{}).Error
+	}
+	if err != nil {
+		return &models.ServiceError{Code: 1002, Message: "更新通知设置失败: " + err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/oidc"
+	"CMS/pkg/redis"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Redis 键名定义
+const (
+	oidcStateKey = "oidc:state:" // 授权请求的状态：hash类型（nonce、verifier），回调时一次性取出
+
+	oidcStateTTL    = 10 * time.Minute
+	oidcStateBytes  = 16
+	oidcRequestTime = 15 * time.Second
+)
+
+var (
+	oidcProvider     *oidc.Provider
+	oidcProviderOnce sync.Once
+)
+
+func getOIDCProvider() *oidc.Provider {
+	oidcProviderOnce.Do(func() {
+		cfg := config.LoadedConfig.OIDC
+		scopes := cfg.Scopes
+		hasOpenID := false
+		for _, scope := range scopes {
+			if scope == "openid" {
+				hasOpenID = true
+			}
+		}
+		if !hasOpenID {
+			scopes = append([]string{"openid"}, scopes...)
+		}
+		oidcProvider = oidc.NewProvider(oidc.Config{
+			Issuer:       cfg.Issuer,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+		}, nil)
+	})
+	return oidcProvider
+}
+
+// BeginOIDCLogin 生成跳转到统一身份认证的授权地址，state、nonce 和 PKCE verifier 暂存在 Redis 中
+func BeginOIDCLogin() (string, *models.ServiceError) {
+	if !config.LoadedConfig.OIDC.Enabled {
+		return "", &models.ServiceError{Code: 1301, Message: "未启用统一身份认证登录"}
+	}
+
+	state, err := randomHex(oidcStateBytes)
+	if err != nil {
+		return "", &models.ServiceError{Code: 1302, Message: "生成登录请求失败"}
+	}
+	nonce, err := randomHex(oidcStateBytes)
+	if err != nil {
+		return "", &models.ServiceError{Code: 1302, Message: "生成登录请求失败"}
+	}
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return "", &models.ServiceError{Code: 1302, Message: "生成登录请求失败"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTime)
+	defer cancel()
+	authURL, err := getOIDCProvider().AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		logger.GetLogger().Errorf("获取统一身份认证配置失败: %v", err)
+		return "", &models.ServiceError{Code: 1303, Message: "统一身份认证服务不可用"}
+	}
+
+	key := oidcStateKey + state
+	pipe := redis.RedisClient.TxPipeline()
+	pipe.HSet(ctx, key, "nonce", nonce, "verifier", verifier)
+	pipe.Expire(ctx, key, oidcStateTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", &models.ServiceError{Code: 1302, Message: "生成登录请求失败: " + err.Error()}
+	}
+	return authURL, nil
+}
+
+// claimString 读取字符串声明，数字类型的学号也按字符串处理
+func claimString(claims map[string]interface{}, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return ""
+	}
+}
+
+// claimGroups 读取用户组声明，兼容数组和以空格或逗号分隔的字符串
+func claimGroups(claims map[string]interface{}, name string) []string {
+	switch v := claims[name].(type) {
+	case []interface{}:
+		groups := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	default:
+		return nil
+	}
+}
+
+// mapOIDCRole 按用户组映射角色：属于任一管理员组为管理员，否则为学生
+func mapOIDCRole(groups []string) int {
+	for _, group := range groups {
+		for _, adminGroup := range config.LoadedConfig.OIDC.AdminGroups {
+			if group == adminGroup {
+				return models.AdminRole
+			}
+		}
+	}
+	return models.StudentRole
+}
+
+func writeOIDCAudit(tx *gorm.DB, userID uint, action, detail string) error {
+	// 统一身份认证触发的操作由系统执行，AdminID 记录为 0
+	return tx.Create(&models.AuditLog{
+		AdminID:  0,
+		Action:   action,
+		TargetID: userID,
+		Detail:   detail,
+	}).Error
+}
+
+// CompleteOIDCLogin 处理统一身份认证回调：校验 state，用授权码换取并校验 ID Token，
+// 按 sub 查找已绑定的账号；未绑定时按学号绑定已有账号，或在允许时自动创建学生账号
+func CompleteOIDCLogin(state, code string) (*models.User, *models.ServiceError) {
+	if !config.LoadedConfig.OIDC.Enabled {
+		return nil, &models.ServiceError{Code: 1301, Message: "未启用统一身份认证登录"}
+	}
+	invalidState := &models.ServiceError{Code: 1304, Message: "登录请求无效或已过期，请重新登录"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTime)
+	defer cancel()
+
+	// 取出即删除，保证同一 state 只能使用一次
+	key := oidcStateKey + state
+	pipe := redis.RedisClient.TxPipeline()
+	getCmd := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, invalidState
+	}
+	stored := getCmd.Val()
+	if stored["verifier"] == "" || stored["nonce"] == "" {
+		return nil, invalidState
+	}
+
+	provider := getOIDCProvider()
+	rawIDToken, err := provider.Exchange(ctx, code, stored["verifier"])
+	if err != nil {
+		logger.GetLogger().Errorf("统一身份认证换取token失败: %v", err)
+		return nil, &models.ServiceError{Code: 1305, Message: "统一身份认证失败"}
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, stored["nonce"])
+	if err != nil {
+		logger.GetLogger().Errorf("统一身份认证 ID Token 校验失败: %v", err)
+		return nil, &models.ServiceError{Code: 1305, Message: "统一身份认证失败"}
+	}
+
+	cfg := config.LoadedConfig.OIDC
+	subject := claimString(claims, "sub")
+	username := claimString(claims, cfg.UsernameClaim)
+	if subject == "" || username == "" {
+		return nil, &models.ServiceError{Code: 1306, Message: "统一身份认证未返回学号"}
+	}
+	if len(username) > maxUsernameLen {
+		return nil, &models.ServiceError{Code: 1306, Message: "学号格式不正确"}
+	}
+	name := []rune(claimString(claims, cfg.NameClaim))
+	if len(name) > maxNameLen {
+		name = name[:maxNameLen]
+	}
+	groups := claimGroups(claims, cfg.GroupsClaim)
+
+	return provisionOIDCUser(subject, username, string(name), groups)
+}
+
+// provisionOIDCUser 查找、绑定或创建统一身份认证对应的本地账号，并按用户组同步角色
+func provisionOIDCUser(subject, username, name string, groups []string) (*models.User, *models.ServiceError) {
+	cfg := config.LoadedConfig.OIDC
+	role := mapOIDCRole(groups)
+	syncRole := len(cfg.AdminGroups) > 0
+
+	var user models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("oidc_subject = ?", subject).First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = tx.Where("username = ?", username).First(&user).Error
+			switch {
+			case err == nil:
+				// 学号已有本地账号：未绑定时绑定到该统一身份账号
+				if user.OIDCSubject != nil || user.IsServiceAccount {
+					return &models.ServiceError{Code: 1307, Message: "该学号已绑定其他统一身份账号"}
+				}
+				if err := tx.Model(&user).Update("oidc_subject", subject).Error; err != nil {
+					return err
+				}
+				if err := writeOIDCAudit(tx, user.ID, "oidc_link", fmt.Sprintf(`{"username": %q}`, username)); err != nil {
+					return err
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if !cfg.AutoProvision {
+					return &models.ServiceError{Code: 1308, Message: "账号不存在，请联系管理员开通"}
+				}
+				// 与注册、名单导入使用相同的账号规则，身份提供方未返回姓名时用学号代替
+				if strings.TrimSpace(name) == "" {
+					name = username
+				}
+				if serviceErr := ValidateNewUser(username, name, role); serviceErr != nil {
+					return &models.ServiceError{Code: 1306, Message: "统一身份账号信息不符合要求: " + serviceErr.Message}
+				}
+				// 自动创建的账号使用随机密码，只能通过统一身份认证或管理员重置密码后登录
+				random, err := randomHex(passwordResetTokenBytes)
+				if err != nil {
+					return err
+				}
+				hashed, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+				if err != nil {
+					return err
+				}
+				user = models.User{
+					Username:    username,
+					Password:    string(hashed),
+					Name:        name,
+					UserType:    role,
+					OIDCSubject: &subject,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return err
+				}
+				return writeOIDCAudit(tx, user.ID, "oidc_provision", fmt.Sprintf(`{"username": %q, "user_type": %d}`, username, role))
+			default:
+				return err
+			}
+		}
+
+		if syncRole && user.UserType != role {
+			if err := tx.Model(&user).Update("user_type", role).Error; err != nil {
+				return err
+			}
+			detail := fmt.Sprintf(`{"from": %d, "to": %d}`, user.UserType, role)
+			user.UserType = role
+			return writeOIDCAudit(tx, user.ID, "oidc_role_change", detail)
+		}
+		return nil
+	})
+	if err != nil {
+		var serviceErr *models.ServiceError
+		if errors.As(err, &serviceErr) {
+			return nil, serviceErr
+		}
+		return nil, &models.ServiceError{Code: 1309, Message: "登录失败: " + err.Error()}
+	}
+	return &user, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/oidc/oidctest"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testOIDCClientID = "cms-client"
+
+// setupOIDCTest 启动本地身份提供方并按其地址配置统一身份认证
+func setupOIDCTest(t *testing.T) (*oidctest.Server, *config.Config) {
+	t.Helper()
+	cfg := setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.AuditLog{})
+
+	srv := oidctest.NewServer(testOIDCClientID)
+	t.Cleanup(srv.Close)
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.Issuer = srv.Issuer
+	cfg.OIDC.ClientID = testOIDCClientID
+	cfg.OIDC.RedirectURL = "http://cms.test/api/user/oidc/callback"
+	cfg.OIDC.Scopes = []string{"profile"}
+	cfg.OIDC.UsernameClaim = "student_id"
+	cfg.OIDC.NameClaim = "name"
+	cfg.OIDC.GroupsClaim = "groups"
+	cfg.OIDC.AutoProvision = true
+
+	// 提供方按配置懒加载，每个测试重新创建
+	oidcProvider, oidcProviderOnce = nil, sync.Once{}
+	t.Cleanup(func() { oidcProvider, oidcProviderOnce = nil, sync.Once{} })
+	return srv, cfg
+}
+
+// oidcLogin 走一遍完整的授权流程，返回回调使用的 state 和授权码
+func oidcLogin(t *testing.T, srv *oidctest.Server, claims jwt.MapClaims) (string, string) {
+	t.Helper()
+	authURL, serviceErr := BeginOIDCLogin()
+	if serviceErr != nil {
+		t.Fatalf("生成授权地址失败: %v", serviceErr)
+	}
+	state, code, err := srv.Authorize(authURL, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state, code
+}
+
+func createOIDCTestUser(t *testing.T, username string, subject *string) models.User {
+	t.Helper()
+	user := models.User{Username: username, Password: "x", Name: "测试", UserType: models.StudentRole, OIDCSubject: subject}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func countOIDCAudit(t *testing.T, action string, userID uint) int64 {
+	t.Helper()
+	var count int64
+	database.DB.Model(&models.AuditLog{}).Where("action = ? AND target_id = ?", action, userID).Count(&count)
+	return count
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	srv, _ := setupOIDCTest(t)
+	state, code := oidcLogin(t, srv, jwt.MapClaims{"sub": "sub-1", "student_id": "2023001", "name": "张三"})
+
+	user, serviceErr := CompleteOIDCLogin(state, code)
+	if serviceErr != nil {
+		t.Fatalf("登录失败: %v", serviceErr)
+	}
+	if user.Username != "2023001" || user.Name != "张三" || user.OIDCSubject == nil || *user.OIDCSubject != "sub-1" {
+		t.Fatalf("账号信息错误: %+v", user)
+	}
+
+	// 再次登录按 sub 找到同一账号
+	state, code = oidcLogin(t, srv, jwt.MapClaims{"sub": "sub-1", "student_id": "2023001"})
+	again, serviceErr := CompleteOIDCLogin(state, code)
+	if serviceErr != nil || again.ID != user.ID {
+		t.Fatalf("应登录到同一账号: %+v, %v", again, serviceErr)
+	}
+}
+
+func TestOIDCStateReuse(t *testing.T) {
+	srv, _ := setupOIDCTest(t)
+	state, code := oidcLogin(t, srv, jwt.MapClaims{"sub": "sub-1", "student_id": "2023001"})
+
+	if _, serviceErr := CompleteOIDCLogin(state, code); serviceErr != nil {
+		t.Fatalf("登录失败: %v", serviceErr)
+	}
+	// state 已被取出删除，重放回调必须失败
+	if _, serviceErr := CompleteOIDCLogin(state, code); serviceErr == nil || serviceErr.Code != 1304 {
+		t.Fatalf("重复使用 state 应返回1304: %v", serviceErr)
+	}
+	if _, serviceErr := CompleteOIDCLogin("unknown-state", code); serviceErr == nil || serviceErr.Code != 1304 {
+		t.Fatalf("未知 state 应返回1304: %v", serviceErr)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidToken(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"nonce不一致", jwt.MapClaims{"nonce": "attacker-nonce"}},
+		{"issuer错误", jwt.MapClaims{"iss": "https://evil.example"}},
+		{"audience错误", jwt.MapClaims{"aud": "another-client"}},
+		{"已过期", jwt.MapClaims{"exp": 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv, _ := setupOIDCTest(t)
+			claims := jwt.MapClaims{"sub": "sub-1", "student_id": "2023001"}
+			for k, v := range c.claims {
+				claims[k] = v
+			}
+			state, code := oidcLogin(t, srv, claims)
+			if _, serviceErr := CompleteOIDCLogin(state, code); serviceErr == nil || serviceErr.Code != 1305 {
+				t.Fatalf("应拒绝登录: %v", serviceErr)
+			}
+			var count int64
+			database.DB.Model(&models.User{}).Count(&count)
+			if count != 0 {
+				t.Fatal("校验失败时不应创建账号")
+			}
+		})
+	}
+}
+
+func TestProvisionOIDCUserLink(t *testing.T) {
+	setupOIDCTest(t)
+	existing := createOIDCTestUser(t, "2023001", nil)
+
+	user, serviceErr := provisionOIDCUser("sub-1", "2023001", "张三", nil)
+	if serviceErr != nil {
+		t.Fatalf("绑定失败: %v", serviceErr)
+	}
+	if user.ID != existing.ID || user.OIDCSubject == nil || *user.OIDCSubject != "sub-1" {
+		t.Fatalf("应绑定已有账号: %+v", user)
+	}
+	if countOIDCAudit(t, "oidc_link", existing.ID) != 1 {
+		t.Fatal("绑定应写入审计日志")
+	}
+
+	// 学号已绑定其他统一身份账号时拒绝
+	if _, serviceErr := provisionOIDCUser("sub-2", "2023001", "", nil); serviceErr == nil || serviceErr.Code != 1307 {
+		t.Fatalf("已绑定的学号应返回1307: %v", serviceErr)
+	}
+}
+
+func TestProvisionOIDCUserRejectsServiceAccount(t *testing.T) {
+	setupOIDCTest(t)
+	bot := createOIDCTestUser(t, "bot", nil)
+	database.DB.Model(&bot).Update("is_service_account", true)
+
+	if _, serviceErr := provisionOIDCUser("sub-1", "bot", "", nil); serviceErr == nil || serviceErr.Code != 1307 {
+		t.Fatalf("服务账号不能绑定: %v", serviceErr)
+	}
+}
+
+func TestProvisionOIDCUserAutoProvision(t *testing.T) {
+	setupOIDCTest(t)
+
+	user, serviceErr := provisionOIDCUser("sub-1", "2023001", "张三", nil)
+	if serviceErr != nil {
+		t.Fatalf("自动创建失败: %v", serviceErr)
+	}
+	if user.ID == 0 || user.UserType != models.StudentRole || user.Name != "张三" {
+		t.Fatalf("自动创建的账号错误: %+v", user)
+	}
+	if countOIDCAudit(t, "oidc_provision", user.ID) != 1 {
+		t.Fatal("自动创建应写入审计日志")
+	}
+}
+
+func TestProvisionOIDCUserAutoProvisionValidatesUsername(t *testing.T) {
+	setupOIDCTest(t)
+
+	// 自动创建与注册使用相同的账号规则
+	if _, serviceErr := provisionOIDCUser("sub-1", "zhangsan", "张三", nil); serviceErr == nil || serviceErr.Code != 1306 {
+		t.Fatalf("非数字学号应拒绝自动创建: %v", serviceErr)
+	}
+	var count int64
+	database.DB.Model(&models.User{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("校验失败时不应创建账号，实际 %d 个", count)
+	}
+
+	// 未返回姓名时用学号代替
+	user, serviceErr := provisionOIDCUser("sub-2", "2023002", "", nil)
+	if serviceErr != nil || user.Name != "2023002" {
+		t.Fatalf("未返回姓名时应使用学号: %+v, %v", user, serviceErr)
+	}
+}
+
+func TestProvisionOIDCUserAutoProvisionDisabled(t *testing.T) {
+	_, cfg := setupOIDCTest(t)
+	cfg.OIDC.AutoProvision = false
+
+	if _, serviceErr := provisionOIDCUser("sub-1", "2023001", "", nil); serviceErr == nil || serviceErr.Code != 1308 {
+		t.Fatalf("未开启自动创建时应返回1308: %v", serviceErr)
+	}
+}
+
+func TestProvisionOIDCUserRoleSync(t *testing.T) {
+	_, cfg := setupOIDCTest(t)
+	cfg.OIDC.AdminGroups = []string{"cms-admins"}
+	student := createOIDCTestUser(t, "2023001", nil)
+
+	user, serviceErr := provisionOIDCUser("sub-1", "2023001", "", []string{"students", "cms-admins"})
+	if serviceErr != nil || user.UserType != models.AdminRole {
+		t.Fatalf("属于管理员组应同步为管理员: %+v, %v", user, serviceErr)
+	}
+	if countOIDCAudit(t, "oidc_role_change", student.ID) != 1 {
+		t.Fatal("角色变更应写入审计日志")
+	}
+
+	// 移出管理员组后降为学生
+	user, serviceErr = provisionOIDCUser("sub-1", "2023001", "", []string{"students"})
+	if serviceErr != nil || user.UserType != models.StudentRole {
+		t.Fatalf("移出管理员组应降为学生: %+v, %v", user, serviceErr)
+	}
+	if countOIDCAudit(t, "oidc_role_change", student.ID) != 2 {
+		t.Fatal("角色变更应写入审计日志")
+	}
+
+	// 角色未变化时不写审计
+	provisionOIDCUser("sub-1", "2023001", "", []string{"students"})
+	if countOIDCAudit(t, "oidc_role_change", student.ID) != 2 {
+		t.Fatal("角色未变化时不应写入审计日志")
+	}
+}
+
+func TestProvisionOIDCUserNoRoleSyncWithoutAdminGroups(t *testing.T) {
+	setupOIDCTest(t)
+	admin := createOIDCTestUser(t, "teacher", nil)
+	database.DB.Model(&admin).Update("user_type", models.AdminRole)
+
+	// 未配置管理员组时不改动本地角色
+	user, serviceErr := provisionOIDCUser("sub-1", "teacher", "", nil)
+	if serviceErr != nil || user.UserType != models.AdminRole {
+		t.Fatalf("未配置管理员组时应保留本地角色: %+v, %v", user, serviceErr)
+	}
+}
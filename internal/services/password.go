@@ -0,0 +1,286 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	minPasswordLength       = 8
+	maxPasswordLength       = 64             // bcrypt 只使用前72字节
+	passwordResetTTL        = 24 * time.Hour // 重置凭证有效期
+	passwordResetTokenBytes = 24             // 重置凭证随机字节数
+
+	passwordChangeLoginKey = "login:password:" // 登录时强制修改密码的中间凭证：string类型（user_id），修改成功后删除
+	passwordChangeLoginTTL = 10 * time.Minute
+)
+
+// commonPasswords 常见弱密码，按小写比较
+var commonPasswords = map[string]bool{
+	"12345678": true, "123456789": true, "1234567890": true, "87654321": true,
+	"11111111": true, "00000000": true, "88888888": true, "66666666": true,
+	"password": true, "password1": true, "password123": true, "passw0rd": true,
+	"qwerty123": true, "qwertyuiop": true, "abc12345": true, "abcd1234": true,
+	"a1234567": true, "1qaz2wsx": true, "iloveyou": true, "admin123": true,
+	"woaini1314": true, "aa123456": true, "qq123456": true, "asd12345": true,
+}
+
+// ValidatePasswordStrength 校验密码强度：长度8-64位，至少包含字母、数字、符号中的两类，
+// 不能是常见弱密码，也不能包含账号
+func ValidatePasswordStrength(username, password string) *models.ServiceError {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return &models.ServiceError{Code: 1101, Message: fmt.Sprintf("密码长度需为%d-%d位", minPasswordLength, maxPasswordLength)}
+	}
+
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII || unicode.IsSpace(r) || !unicode.IsPrint(r):
+			return &models.ServiceError{Code: 1102, Message: "密码只能包含字母、数字和英文符号"}
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{hasLetter, hasDigit, hasSymbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < 2 {
+		return &models.ServiceError{Code: 1103, Message: "密码需至少包含字母、数字、符号中的两类"}
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return &models.ServiceError{Code: 1104, Message: "密码过于常见，请更换"}
+	}
+	if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+		return &models.ServiceError{Code: 1105, Message: "密码不能包含账号"}
+	}
+	return nil
+}
+
+// setPassword 在事务中更新密码，同时清除强制修改密码标记和初始密码的失效时间
+func setPassword(tx *gorm.DB, userID uint, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password":             string(hashed),
+		"must_change_password": false,
+		"password_expires_at":  nil,
+	}).Error
+}
+
+// BeginPasswordChangeLogin 密码校验通过但必须先修改密码时，签发修改密码用的中间凭证，
+// 此时不创建会话；初始密码已过期时拒绝登录
+func BeginPasswordChangeLogin(user *models.User) (string, *models.ServiceError) {
+	if user.PasswordExpiresAt != nil && time.Now().After(*user.PasswordExpiresAt) {
+		return "", &models.ServiceError{Code: 1106, Message: "初始密码已过期，请联系管理员重置密码"}
+	}
+	token, err := randomHex(passwordResetTokenBytes)
+	if err != nil {
+		return "", &models.ServiceError{Code: 1002, Message: "登录失败"}
+	}
+	if err := redis.RedisClient.Set(context.Background(), passwordChangeLoginKey+token, user.ID, passwordChangeLoginTTL).Err(); err != nil {
+		return "", &models.ServiceError{Code: 1002, Message: "登录失败"}
+	}
+	return token, nil
+}
+
+// CompletePasswordChangeLogin 用中间凭证设置新密码，成功后凭证失效，返回的用户可继续完成登录
+func CompletePasswordChangeLogin(token, newPassword string) (*models.User, *models.ServiceError) {
+	invalid := &models.ServiceError{Code: 1107, Message: "登录已过期，请重新输入密码"}
+	ctx := context.Background()
+	key := passwordChangeLoginKey + token
+
+	userID, err := redis.RedisClient.Get(ctx, key).Int()
+	if err != nil {
+		return nil, invalid
+	}
+	user, err := GetUserByID(uint(userID))
+	if err != nil || !user.MustChangePassword || user.IsClosed() {
+		redis.RedisClient.Del(ctx, key)
+		return nil, invalid
+	}
+	if user.CheckPasswordHash(newPassword) {
+		return nil, &models.ServiceError{Code: 1003, Message: "新密码不能与原密码相同"}
+	}
+	if serviceErr := ValidatePasswordStrength(user.Username, newPassword); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// 删除成功才算使用凭证，避免并发请求重复使用
+	if deleted, err := redis.RedisClient.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return nil, invalid
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setPassword(tx, user.ID, newPassword); err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  user.ID,
+			Action:   "change_password",
+			TargetID: user.ID,
+			Detail:   `{"by": "initial"}`,
+		}).Error
+	})
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1004, Message: "修改密码失败: " + err.Error()}
+	}
+	user.MustChangePassword = false
+	user.PasswordExpiresAt = nil
+	return user, nil
+}
+
+// ChangePassword 用户凭旧密码修改密码，修改后除 currentSessionID 外的所有已登录会话失效
+func ChangePassword(userID uint, currentSessionID, oldPassword, newPassword string) *models.ServiceError {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+	if !user.CheckPasswordHash(oldPassword) {
+		return &models.ServiceError{Code: 1002, Message: "原密码错误"}
+	}
+	if oldPassword == newPassword {
+		return &models.ServiceError{Code: 1003, Message: "新密码不能与原密码相同"}
+	}
+	if serviceErr := ValidatePasswordStrength(user.Username, newPassword); serviceErr != nil {
+		return serviceErr
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setPassword(tx, userID, newPassword); err != nil {
+			return err
+		}
+		// 用户自行操作时 AdminID 记录为本人
+		return tx.Create(&models.AuditLog{
+			AdminID:  userID,
+			Action:   "change_password",
+			TargetID: userID,
+			Detail:   `{"by": "self"}`,
+		}).Error
+	})
+	if err != nil {
+		return &models.ServiceError{Code: 1004, Message: "修改密码失败: " + err.Error()}
+	}
+	if _, err := revokeSessions(userID, currentSessionID); err != nil {
+		logger.GetLogger().Errorf("修改密码后撤销其他会话失败: user_id=%d, error=%v", userID, err)
+	}
+	return nil
+}
+
+// sha256Hex 计算高熵随机凭证的 SHA-256 哈希，用于只存哈希的一次性凭证
+func sha256Hex(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssuePasswordReset 管理员为用户签发一次性密码重置凭证，由管理员线下交给用户；
+// 凭证明文只在此时返回一次，同一用户此前未使用的凭证随之作废
+func IssuePasswordReset(adminID, userID uint) (string, time.Time, *models.ServiceError) {
+	if user, err := GetUserByID(userID); err != nil || user.IsClosed() {
+		return "", time.Time{}, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+
+	buf := make([]byte, passwordResetTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, &models.ServiceError{Code: 1002, Message: "生成重置凭证失败"}
+	}
+	token := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(passwordResetTTL)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", userID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		reset := models.PasswordResetToken{
+			UserID:    userID,
+			TokenHash: sha256Hex(token),
+			CreatedBy: adminID,
+			ExpiresAt: expiresAt,
+		}
+		if err := tx.Create(&reset).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  adminID,
+			Action:   "issue_password_reset",
+			TargetID: userID,
+			Detail:   fmt.Sprintf(`{"reset_id": %d, "expires_at": "%s"}`, reset.ID, expiresAt.Format(time.RFC3339)),
+		}).Error
+	})
+	if err != nil {
+		return "", time.Time{}, &models.ServiceError{Code: 1003, Message: "签发重置凭证失败: " + err.Error()}
+	}
+	return token, expiresAt, nil
+}
+
+// ResetPasswordWithToken 使用一次性凭证重置密码，成功后凭证失效且该用户所有已登录的会话失效
+func ResetPasswordWithToken(token, newPassword string) *models.ServiceError {
+	invalid := &models.ServiceError{Code: 1001, Message: "重置凭证无效或已过期"}
+
+	var reset models.PasswordResetToken
+	if err := database.DB.Where("token_hash = ?", sha256Hex(token)).First(&reset).Error; err != nil {
+		return invalid
+	}
+	if reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return invalid
+	}
+	user, err := GetUserByID(reset.UserID)
+	if err != nil {
+		return invalid
+	}
+	if serviceErr := ValidatePasswordStrength(user.Username, newPassword); serviceErr != nil {
+		return serviceErr
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 通过条件更新保证凭证只能使用一次
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return invalid
+		}
+		if err := setPassword(tx, user.ID, newPassword); err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			AdminID:  reset.CreatedBy,
+			Action:   "reset_password",
+			TargetID: user.ID,
+			Detail:   fmt.Sprintf(`{"reset_id": %d}`, reset.ID),
+		}).Error
+	})
+	if err != nil {
+		if serviceErr, ok := err.(*models.ServiceError); ok {
+			return serviceErr
+		}
+		return &models.ServiceError{Code: 1002, Message: "重置密码失败: " + err.Error()}
+	}
+	RevokeAllSessions(user.ID)
+	return nil
+}
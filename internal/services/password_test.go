@@ -0,0 +1,77 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+	"time"
+)
+
+// importTestUser 通过名单导入创建账号，返回账号和初始密码
+func importTestUser(t *testing.T, username string) (models.User, string) {
+	t.Helper()
+	rows := []models.RosterRow{{Row: 2, Username: username, Name: "张三", ClassName: "一班", UserType: models.StudentRole}}
+	result, serviceErr := ImportRoster(0, rows, nil, false, 0)
+	if serviceErr != nil || result.Created != 1 {
+		t.Fatalf("导入失败: %+v, %v", result, serviceErr)
+	}
+	var user models.User
+	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user, result.Items[0].InitialPassword
+}
+
+func setupPasswordTest(t *testing.T) {
+	t.Helper()
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.AuditLog{})
+}
+
+func TestImportedUserMustChangePassword(t *testing.T) {
+	setupPasswordTest(t)
+	user, initial := importTestUser(t, "2023001")
+	if !user.MustChangePassword || user.PasswordExpiresAt == nil || !user.PasswordExpiresAt.After(time.Now()) {
+		t.Fatalf("导入的账号应要求修改初始密码并设置失效时间: %+v", user)
+	}
+
+	token, serviceErr := BeginPasswordChangeLogin(&user)
+	if serviceErr != nil {
+		t.Fatal(serviceErr)
+	}
+	if _, serviceErr := CompletePasswordChangeLogin(token, initial); serviceErr == nil || serviceErr.Code != 1003 {
+		t.Fatalf("新密码不能与初始密码相同: %v", serviceErr)
+	}
+
+	changed, serviceErr := CompletePasswordChangeLogin(token, "Str0ng-Passw0rd")
+	if serviceErr != nil || changed.ID != user.ID || changed.MustChangePassword {
+		t.Fatalf("修改初始密码失败: %+v, %v", changed, serviceErr)
+	}
+	reloaded := reloadUser(t, user.ID)
+	if reloaded.MustChangePassword || reloaded.PasswordExpiresAt != nil || !reloaded.CheckPasswordHash("Str0ng-Passw0rd") {
+		t.Fatalf("修改后应清除标记并更新密码: %+v", reloaded)
+	}
+	var audits int64
+	database.DB.Model(&models.AuditLog{}).Where("action = ? AND target_id = ?", "change_password", user.ID).Count(&audits)
+	if audits != 1 {
+		t.Fatalf("修改初始密码应写入审计日志，实际 %d 条", audits)
+	}
+
+	// 凭证只能使用一次
+	if _, serviceErr := CompletePasswordChangeLogin(token, "An0ther-Passw0rd"); serviceErr == nil || serviceErr.Code != 1107 {
+		t.Fatalf("凭证重复使用应返回1107: %v", serviceErr)
+	}
+}
+
+func TestExpiredInitialPasswordRejected(t *testing.T) {
+	setupPasswordTest(t)
+	user, _ := importTestUser(t, "2023001")
+	expired := time.Now().Add(-time.Minute)
+	database.DB.Model(&user).Update("password_expires_at", expired)
+	user = reloadUser(t, user.ID)
+
+	if _, serviceErr := BeginPasswordChangeLogin(&user); serviceErr == nil || serviceErr.Code != 1106 {
+		t.Fatalf("初始密码过期后应拒绝登录: %v", serviceErr)
+	}
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Redis 键名定义
+const (
+	pollCountKey  = "poll:count:"  // 投票各选项票数：hash类型（field为选项ID）
+	pollVoterKey  = "poll:voters:" // 已投票用户：set类型
+	pollLockKey   = "lock:poll:"   // 重建票数缓存的锁
+	pollKeyExpire = 7 * 24 * time.Hour
+
+	minPollOptions      = 2
+	maxPollOptions      = 10
+	maxPollOptionLength = 100
+)
+
+// pollVoteScript 原子地完成"检查是否已投票 - 记录投票人 - 选项计数"，
+// 缓存不存在时返回 -1 由调用方从 MySQL 重建，已投票返回 0，成功返回 1
+var pollVoteScript = goredis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+if redis.call("SISMEMBER", KEYS[2], ARGV[1]) == 1 then
+	return 0
+end
+redis.call("SADD", KEYS[2], ARGV[1])
+for i = 3, #ARGV do
+	redis.call("HINCRBY", KEYS[1], ARGV[i], 1)
+end
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+redis.call("EXPIRE", KEYS[2], ARGV[2])
+return 1
+`)
+
+// pollUnvoteScript 写入 MySQL 失败时撤销 Redis 中的投票
+var pollUnvoteScript = goredis.NewScript(`
+if redis.call("SREM", KEYS[2], ARGV[1]) == 1 then
+	for i = 2, #ARGV do
+		redis.call("HINCRBY", KEYS[1], ARGV[i], -1)
+	end
+end
+return 1
+`)
+
+func pollKeys(pollID uint) []string {
+	id := strconv.Itoa(int(pollID))
+	return []string{pollCountKey + id, pollVoterKey + id}
+}
+
+// normalizePollInput 校验投票参数并去除选项首尾空白
+func normalizePollInput(input *models.PollInput) *models.ServiceError {
+	if len(input.Options) < minPollOptions || len(input.Options) > maxPollOptions {
+		return &models.ServiceError{Code: 1201, Message: fmt.Sprintf("投票选项需为%d-%d个", minPollOptions, maxPollOptions)}
+	}
+	seen := make(map[string]bool, len(input.Options))
+	for i, text := range input.Options {
+		text = strings.TrimSpace(text)
+		if text == "" || len([]rune(text)) > maxPollOptionLength {
+			return &models.ServiceError{Code: 1202, Message: fmt.Sprintf("投票选项需为1-%d个字符", maxPollOptionLength)}
+		}
+		if seen[text] {
+			return &models.ServiceError{Code: 1203, Message: "投票选项不能重复"}
+		}
+		seen[text] = true
+		input.Options[i] = text
+	}
+	if input.ClosesAt != nil && !input.ClosesAt.After(time.Now()) {
+		return &models.ServiceError{Code: 1204, Message: "投票截止时间必须晚于当前时间"}
+	}
+	return nil
+}
+
+// CreatePoll 为帖子创建投票，需在创建帖子的事务中调用
+func CreatePoll(tx *gorm.DB, postID uint, input *models.PollInput) *models.ServiceError {
+	if input == nil {
+		return nil
+	}
+	if serviceErr := normalizePollInput(input); serviceErr != nil {
+		return serviceErr
+	}
+
+	poll := models.Poll{
+		PostID:      postID,
+		MultiChoice: input.MultiChoice,
+		Anonymous:   input.Anonymous,
+		ClosesAt:    input.ClosesAt,
+	}
+	if err := tx.Create(&poll).Error; err != nil {
+		return &models.ServiceError{Code: 1205, Message: "创建投票失败: " + err.Error()}
+	}
+	options := make([]models.PollOption, 0, len(input.Options))
+	for i, text := range input.Options {
+		options = append(options, models.PollOption{PollID: poll.ID, Text: text, Position: i})
+	}
+	if err := tx.Create(&options).Error; err != nil {
+		return &models.ServiceError{Code: 1205, Message: "创建投票失败: " + err.Error()}
+	}
+	return nil
+}
+
+// ReplacePoll 修改帖子的投票：已有人投票时不允许修改，input 为空表示不修改；
+// 帖子原本没有投票时新建投票
+func ReplacePoll(tx *gorm.DB, postID uint, input *models.PollInput) *models.ServiceError {
+	if input == nil {
+		return nil
+	}
+
+	// 锁定投票记录直到事务结束，与 Vote 写入投票互斥，避免统计票数后又有投票写入被删除的选项
+	var poll models.Poll
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("post_id = ?", postID).First(&poll).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return CreatePoll(tx, postID, input)
+	}
+	if err != nil {
+		return &models.ServiceError{Code: 1206, Message: "获取投票失败: " + err.Error()}
+	}
+
+	var votes int64
+	tx.Model(&models.PollVote{}).Where("poll_id = ?", poll.ID).Count(&votes)
+	if votes > 0 {
+		return &models.ServiceError{Code: 1207, Message: "投票已有人参与，不能修改选项"}
+	}
+
+	if err := tx.Where("poll_id = ?", poll.ID).Delete(&models.PollOption{}).Error; err != nil {
+		return &models.ServiceError{Code: 1206, Message: "修改投票失败: " + err.Error()}
+	}
+	if err := tx.Delete(&poll).Error; err != nil {
+		return &models.ServiceError{Code: 1206, Message: "修改投票失败: " + err.Error()}
+	}
+	redis.RedisClient.Del(context.Background(), pollKeys(poll.ID)...)
+	return CreatePoll(tx, postID, input)
+}
+
+// rebuildPollCache 从 MySQL 重建投票的 Redis 票数和投票人缓存
+func rebuildPollCache(pollID uint) error {
+	lockKey := pollLockKey + strconv.Itoa(int(pollID))
+	token, ok, err := redis.TryLock(lockKey, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 其他请求正在重建，稍后直接重试投票
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	defer redis.Unlock(lockKey, token)
+
+	keys := pollKeys(pollID)
+	ctx := context.Background()
+	if n, _ := redis.RedisClient.Exists(ctx, keys[0]).Result(); n > 0 {
+		return nil
+	}
+
+	var options []models.PollOption
+	if err := database.DB.Where("poll_id = ?", pollID).Find(&options).Error; err != nil {
+		return err
+	}
+	var voters []uint
+	if err := database.DB.Model(&models.PollVote{}).Where("poll_id = ?", pollID).Distinct().Pluck("user_id", &voters).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[string]interface{}, len(options))
+	for _, o := range options {
+		counts[strconv.Itoa(int(o.ID))] = o.VoteCount
+	}
+	_, err = redis.RedisClient.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, keys[1])
+		pipe.HSet(ctx, keys[0], counts)
+		if len(voters) > 0 {
+			members := make([]interface{}, 0, len(voters))
+			for _, v := range voters {
+				members = append(members, v)
+			}
+			pipe.SAdd(ctx, keys[1], members...)
+		}
+		pipe.Expire(ctx, keys[0], pollKeyExpire)
+		pipe.Expire(ctx, keys[1], pollKeyExpire)
+		return nil
+	})
+	return err
+}
+
+// getPollByPostID 获取帖子的投票
+func getPollByPostID(postID uint) (*models.Poll, error) {
+	var poll models.Poll
+	err := database.DB.Where("post_id = ?", postID).First(&poll).Error
+	return &poll, err
+}
+
+// Vote 投票：通过 Redis 脚本原子地计数并防止重复投票，随后持久化到 MySQL
+func Vote(userID, postID uint, optionIDs []uint) *models.ServiceError {
+	post, err := GetPostByID(postID)
+	if err != nil || post.Status != models.PostStatusPublished {
+		return &models.ServiceError{Code: 1001, Message: "帖子不存在"}
+	}
+	poll, err := getPollByPostID(postID)
+	if err != nil {
+		return &models.ServiceError{Code: 1002, Message: "该帖子没有投票"}
+	}
+	if poll.IsClosed() {
+		return &models.ServiceError{Code: 1003, Message: "投票已截止"}
+	}
+
+	// 校验选项：去重后必须都属于该投票，单选只能选一项
+	unique := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		unique[id] = true
+	}
+	if len(unique) == 0 || (!poll.MultiChoice && len(unique) > 1) {
+		return &models.ServiceError{Code: 1004, Message: "投票选项数量不正确"}
+	}
+	selected := make([]uint, 0, len(unique))
+	for id := range unique {
+		selected = append(selected, id)
+	}
+	var valid int64
+	database.DB.Model(&models.PollOption{}).Where("poll_id = ? AND id IN ?", poll.ID, selected).Count(&valid)
+	if int(valid) != len(selected) {
+		return &models.ServiceError{Code: 1005, Message: "投票选项不存在"}
+	}
+
+	keys := pollKeys(poll.ID)
+	ctx := context.Background()
+	args := []interface{}{userID, int(pollKeyExpire.Seconds())}
+	for _, id := range selected {
+		args = append(args, id)
+	}
+	var result int64
+	for attempt := 0; attempt < 3; attempt++ {
+		result, err = pollVoteScript.Run(ctx, redis.RedisClient, keys, args...).Int64()
+		if err != nil || result != -1 {
+			break
+		}
+		if err = rebuildPollCache(poll.ID); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		return &models.ServiceError{Code: 1006, Message: "投票失败: " + err.Error()}
+	}
+	if result == 0 {
+		return &models.ServiceError{Code: 1007, Message: "已经投过票了"}
+	}
+	if result != 1 {
+		return &models.ServiceError{Code: 1006, Message: "投票失败，请稍后重试"}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 与 ReplacePoll 互斥：投票已被修改删除时放弃写入
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Poll{}, poll.ID).Error; err != nil {
+			return err
+		}
+		for _, id := range selected {
+			if err := tx.Create(&models.PollVote{PollID: poll.ID, UserID: userID, OptionID: id}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.PollOption{}).Where("id = ?", id).
+				Update("vote_count", gorm.Expr("vote_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		undoArgs := append([]interface{}{userID}, args[2:]...)
+		if undoErr := pollUnvoteScript.Run(ctx, redis.RedisClient, keys, undoArgs...).Err(); undoErr != nil {
+			logger.GetLogger().Errorf("撤销Redis投票失败: poll_id=%d, user_id=%d, err=%v", poll.ID, userID, undoErr)
+		}
+		return &models.ServiceError{Code: 1006, Message: "投票失败: " + err.Error()}
+	}
+	return nil
+}
+
+// GetPollResult 获取帖子的投票结果，票数优先读取 Redis，缓存不存在时使用 MySQL 中的持久化票数
+func GetPollResult(postID, userID uint) (*models.PollResponse, error) {
+	poll, err := getPollByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+	var options []models.PollOption
+	if err := database.DB.Where("poll_id = ?", poll.ID).Order("position").Find(&options).Error; err != nil {
+		return nil, err
+	}
+
+	keys := pollKeys(poll.ID)
+	ctx := context.Background()
+	cached, _ := redis.RedisClient.HGetAll(ctx, keys[0]).Result()
+
+	response := &models.PollResponse{
+		ID:          poll.ID,
+		MultiChoice: poll.MultiChoice,
+		Anonymous:   poll.Anonymous,
+		Closed:      poll.IsClosed(),
+		Options:     make([]models.PollOptionResponse, 0, len(options)),
+		MyVotes:     []uint{},
+	}
+	if poll.ClosesAt != nil {
+		response.ClosesAt = poll.ClosesAt.Format("2006-01-02T15:04:05.000-07:00")
+	}
+
+	var votes []models.PollVote
+	if userID != 0 || !poll.Anonymous {
+		query := database.DB.Where("poll_id = ?", poll.ID)
+		if poll.Anonymous {
+			query = query.Where("user_id = ?", userID)
+		}
+		if err := query.Find(&votes).Error; err != nil {
+			return nil, err
+		}
+	}
+	votersByOption := make(map[uint][]uint)
+	voterIDs := make([]uint, 0, len(votes))
+	for _, v := range votes {
+		if v.UserID == userID {
+			response.MyVotes = append(response.MyVotes, v.OptionID)
+		}
+		votersByOption[v.OptionID] = append(votersByOption[v.OptionID], v.UserID)
+		voterIDs = append(voterIDs, v.UserID)
+	}
+	// 一次查询所有投票人，避免每个投票人单独查询
+	users := make(map[uint]models.User)
+	if !poll.Anonymous && len(voterIDs) > 0 {
+		var list []models.User
+		if err := database.DB.Select("id", "username", "name").Where("id IN ?", voterIDs).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range list {
+			users[u.ID] = u
+		}
+	}
+
+	for _, o := range options {
+		item := models.PollOptionResponse{ID: o.ID, Text: o.Text, Votes: o.VoteCount}
+		if count, ok := cached[strconv.Itoa(int(o.ID))]; ok {
+			if n, err := strconv.Atoi(count); err == nil {
+				item.Votes = n
+			}
+		}
+		if !poll.Anonymous {
+			for _, uid := range votersByOption[o.ID] {
+				voter := models.UserSuggestion{ID: uid}
+				if user, ok := users[uid]; ok {
+					voter.Username = user.Username
+					voter.Name = user.Name
+				}
+				item.Voters = append(item.Voters, voter)
+			}
+		}
+		response.Options = append(response.Options, item)
+	}
+
+	if count, err := redis.RedisClient.SCard(ctx, keys[1]).Result(); err == nil && len(cached) > 0 {
+		response.TotalVoters = count
+	} else {
+		database.DB.Model(&models.PollVote{}).Where("poll_id = ?", poll.ID).Distinct("user_id").Count(&response.TotalVoters)
+	}
+	return response, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+)
+
+func TestReplacePollAfterVotesAndVoterList(t *testing.T) {
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.Post{}, &models.Poll{}, &models.PollOption{}, &models.PollVote{})
+
+	voters := []models.User{
+		{Username: "2023001", Password: "x", Name: "张三", UserType: models.StudentRole},
+		{Username: "2023002", Password: "x", Name: "李四", UserType: models.StudentRole},
+	}
+	database.DB.Create(&voters)
+	post := createLikeTestPost(t, voters[0].ID, models.PostStatusPublished)
+	if serviceErr := CreatePoll(database.DB, post.ID, &models.PollInput{Options: []string{"A", "B"}}); serviceErr != nil {
+		t.Fatal(serviceErr)
+	}
+
+	// 没有人投票时可以修改选项
+	if serviceErr := ReplacePoll(database.DB, post.ID, &models.PollInput{Options: []string{"甲", "乙"}}); serviceErr != nil {
+		t.Fatalf("无人投票时应允许修改: %v", serviceErr)
+	}
+	result, err := GetPollResult(post.ID, 0)
+	if err != nil || len(result.Options) != 2 || result.Options[0].Text != "甲" {
+		t.Fatalf("修改后的选项错误: %+v, %v", result, err)
+	}
+
+	for _, v := range voters {
+		if serviceErr := Vote(v.ID, post.ID, []uint{result.Options[0].ID}); serviceErr != nil {
+			t.Fatalf("投票失败: %v", serviceErr)
+		}
+	}
+	if serviceErr := ReplacePoll(database.DB, post.ID, &models.PollInput{Options: []string{"C", "D"}}); serviceErr == nil || serviceErr.Code != 1207 {
+		t.Fatalf("已有人投票时不能修改: %v", serviceErr)
+	}
+
+	result, err = GetPollResult(post.ID, voters[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := result.Options[0]
+	if got.Votes != 2 || len(got.Voters) != 2 || got.Voters[0].Name != "张三" || got.Voters[1].Username != "2023002" {
+		t.Fatalf("投票人信息错误: %+v", got)
+	}
+	if len(result.MyVotes) != 1 || result.TotalVoters != 2 {
+		t.Fatalf("投票统计错误: %+v", result)
+	}
+}
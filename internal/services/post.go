@@ -1,17 +1,81 @@
 package services
 
 import (
+	"CMS/internal/logger"
 	"CMS/internal/models"
 	"CMS/internal/pkg/database"
+	"CMS/pkg/markdown"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-func CreatePost(post models.Post) error {
-	result := database.DB.Create(&post)
-	return result.Error
+// CreatePost 以已登录用户 authorID 的身份创建帖子并关联已上传的附件和投票，任一步失败时帖子不会创建。
+// 作者、匿名配额和是否先审后发都以 authorID 为准，忽略 post.UserID 原有的值
+func CreatePost(authorID uint, post *models.Post, attachmentIDs []uint, poll *models.PollInput) error {
+	post.UserID = authorID
+	if post.IsAnonymous {
+		if serviceErr := checkAnonymousAllowed(post.UserID, post.BoardID); serviceErr != nil {
+			return serviceErr
+		}
+		pseudonym, err := newPseudonym()
+		if err != nil {
+			releaseAnonymousQuota(post.UserID)
+			return &models.ServiceError{Code: 1304, Message: "匿名发帖失败，请稍后重试"}
+		}
+		post.Pseudonym = pseudonym
+	}
+	// 开启先审后发时，声望不足的用户发帖进入待审核队列；回答不做预审
+	if post.Status == models.PostStatusPublished && post.PostType != models.PostTypeAnswer && needsPreModeration(authorID) {
+		post.Status = models.PostStatusPending
+	}
+	post.ContentHTML = markdown.Render(post.Content)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if serviceErr := AttachToPost(tx, post.ID, post.UserID, attachmentIDs); serviceErr != nil {
+			return serviceErr
+		}
+		if serviceErr := CreatePoll(tx, post.ID, poll); serviceErr != nil {
+			return serviceErr
+		}
+		return nil
+	})
+	if err != nil {
+		if post.IsAnonymous {
+			releaseAnonymousQuota(post.UserID)
+		}
+		return err
+	}
+	if post.Status == models.PostStatusPublished {
+		onPostPublished(*post)
+	}
+	return nil
+}
+
+// onPostPublished 帖子对外可见后的处理：广播新帖子事件并触发 Webhook
+func onPostPublished(post models.Post) {
+	response := post.ToResponse()
+	response.Attachments = GetPostAttachments(post.ID)
+	PublishEvent(models.EventTypeNewPost, 0, post.ID, response)
+	EmitWebhookEvent(models.WebhookEventPostCreated, response)
+	syncPostMentions(post)
+	fanoutPost(post)
 }
 
-func GetAllPosts() (posts []models.Post, err error) {
-	result := database.DB.Order("post_time desc").Find(&posts)
+// GetAllPosts 获取帖子列表，置顶中的公告排在最前；boardID 非0时只返回该版块的帖子和全站公告
+func GetAllPosts(boardID uint) (posts []models.Post, err error) {
+	// 草稿和未到时间的定时帖子不出现在列表中，回答只在所属提问下展示
+	query := database.DB.Model(&models.Post{}).
+		Where("status = ? AND post_type <> ?", models.PostStatusPublished, models.PostTypeAnswer)
+	if boardID != 0 {
+		query = query.Where("board_id = ? OR (board_id = 0 AND is_announcement = ?)", boardID, true)
+	}
+	result := query.
+		Order(gorm.Expr("COALESCE(pinned_until > ?, 0) DESC", time.Now())).
+		Order("post_time desc").
+		Find(&posts)
 	err = result.Error
 	return
 }
@@ -22,28 +86,96 @@ func GetPostByID(id uint) (post models.Post, err error) {
 	return
 }
 
-func GetAllPostsWithFormat() ([]models.PostResponse, error) {
-	posts, err := GetAllPosts()
+// GetAllPostsWithFormat 获取帖子列表并填充点赞数及当前用户相关的状态
+func GetAllPostsWithFormat(userID, boardID uint) ([]models.PostResponse, error) {
+	posts, err := GetAllPosts(boardID)
 	if err != nil {
 		return nil, err
 	}
 	var postResponses []models.PostResponse
 	for _, post := range posts {
-		postResponse := post.ToResponse()
-		likes, err := GetLikesByPostID(post.ID)
-		if err == nil {
-			postResponse.Likes = likes
-		}
-		postResponses = append(postResponses, postResponse)
+		postResponses = append(postResponses, FormatPost(post, userID))
 	}
 	return postResponses, nil
 }
+
+// FormatPost 将帖子转换为响应结构，填充点赞数及当前用户相关的状态
+func FormatPost(post models.Post, userID uint) models.PostResponse {
+	postResponse := post.ToResponse()
+	likes, err := GetLikesByPostID(post.ID)
+	if err == nil {
+		postResponse.Likes = likes
+	}
+	if post.IsAnnouncement && userID != 0 {
+		postResponse.Acknowledged = IsAnnouncementAcknowledged(post.ID, userID)
+	}
+	if userID != 0 {
+		postResponse.IsBookmarked = IsBookmarked(userID, post.ID)
+		postResponse.IsOwn = post.UserID == userID
+	}
+	postResponse.Attachments = GetPostAttachments(post.ID)
+	if poll, err := GetPollResult(post.ID, userID); err == nil {
+		postResponse.Poll = poll
+	}
+	return postResponse
+}
 func DeletePostByID(id uint) error {
+	post, err := GetPostByID(id)
+	if err != nil {
+		return err
+	}
+	creditedLikes := countCreditedLikes(database.DB, post)
 	result := database.DB.Where("id = ?", id).Delete(&models.Post{})
-	return result.Error
+	if result.Error != nil {
+		return result.Error
+	}
+	onAnswerDeleted(post)
+	onPostRemoved(post, creditedLikes, false)
+	EmitWebhookEvent(models.WebhookEventPostDeleted, map[string]interface{}{
+		"post_id": id,
+		"reason":  "author",
+	})
+	return nil
 }
 
-func UpdatePostByID(id uint, content string) error {
-	result := database.DB.Where("id = ?", id).Updates(models.Post{Content: content})
-	return result.Error
+// UpdatePostByID 修改帖子内容，poll 不为空时同时修改投票选项（已有人投票时拒绝修改）
+func UpdatePostByID(id uint, content string, poll *models.PollInput) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).Updates(models.Post{Content: content, ContentHTML: markdown.Render(content)}).Error; err != nil {
+			return err
+		}
+		if serviceErr := ReplacePoll(tx, id, poll); serviceErr != nil {
+			return serviceErr
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// 已发布的帖子修改后重新解析提及，草稿在发布时解析
+	if post, err := GetPostByID(id); err == nil && post.Status == models.PostStatusPublished {
+		syncPostMentions(post)
+	}
+	return nil
+}
+
+// RerenderPosts 按当前渲染规则重新生成所有帖子的 HTML，用于渲染规则变更后或历史数据迁移
+func RerenderPosts(batchSize int) (int, error) {
+	var posts []models.Post
+	updated := 0
+	result := database.DB.Select("id", "content", "content_html").FindInBatches(&posts, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, post := range posts {
+			html := markdown.Render(post.Content)
+			if html == post.ContentHTML {
+				continue
+			}
+			if err := database.DB.Model(&models.Post{}).Where("id = ?", post.ID).Update("content_html", html).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		logger.GetLogger().Infof("重新渲染帖子: 第%d批完成, 累计更新%d条", batch, updated)
+		return nil
+	})
+	return updated, result.Error
 }
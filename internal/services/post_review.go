@@ -0,0 +1,85 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetPendingPosts 管理员分页获取待审核的帖子，先提交的在前
+func GetPendingPosts(page, pageSize int) ([]models.PostResponse, int64, error) {
+	query := database.DB.Model(&models.Post{}).Where("status = ?", models.PostStatusPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var posts []models.Post
+	if err := query.Order("post_time").Offset((page - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		responses = append(responses, FormatPost(post, 0))
+	}
+	return responses, total, nil
+}
+
+// ReviewPendingPost 审核待发布的帖子：approval 为1时发布，为2时删除，并通知作者
+func ReviewPendingPost(adminID, postID uint, approval int) *models.ServiceError {
+	post, err := GetPostByID(postID)
+	if err != nil || post.Status != models.PostStatusPending {
+		return &models.ServiceError{Code: 1001, Message: "帖子不存在或不在待审核状态"}
+	}
+
+	now := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Post{}).Where("id = ? AND status = ?", postID, models.PostStatusPending)
+		var result *gorm.DB
+		if approval == 1 {
+			result = query.Updates(map[string]interface{}{
+				"status":    models.PostStatusPublished,
+				"post_time": now,
+			})
+		} else {
+			result = query.Delete(&models.Post{})
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &models.ServiceError{Code: 1001, Message: "帖子不存在或不在待审核状态"}
+		}
+
+		auditLog := models.AuditLog{
+			AdminID:  adminID,
+			Action:   "review_post",
+			TargetID: postID,
+			Detail:   fmt.Sprintf(`{"approval": %d, "author_id": %d}`, approval, post.UserID),
+		}
+		return tx.Create(&auditLog).Error
+	})
+	if err != nil {
+		if serviceErr, ok := err.(*models.ServiceError); ok {
+			return serviceErr
+		}
+		return &models.ServiceError{Code: 1002, Message: "审核帖子失败: " + err.Error()}
+	}
+
+	content := "你的帖子未通过审核，已被删除"
+	if approval == 1 {
+		content = "你的帖子已通过审核并发布"
+		post.Status = models.PostStatusPublished
+		post.PostTime = now
+		onPostPublished(post)
+	}
+	if err := Notify(post.UserID, models.NotificationTypeModeration, postID, content); err != nil {
+		logger.GetLogger().Errorf("发送帖子审核结果通知失败: user_id=%d, post_id=%d, err=%v", post.UserID, postID, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxBioLength        = 500 // 个人简介最大字符数
+	maxClassNameLength  = 50  // 班级最大字符数
+	maxDepartmentLength = 100 // 院系最大字符数
+	recentPostsLimit    = 10  // 个人主页展示的近期帖子数
+)
+
+// canViewProfileDetails 判断 viewerID 能否查看 user 的主页详情：本人和管理员始终可见，
+// 屏蔽了对方的用户不可见，其余按用户设置的可见范围判断
+func canViewProfileDetails(user *models.User, viewerID uint) bool {
+	if viewerID == user.ID {
+		return true
+	}
+	if isAdmin, _ := CheckUserIsAdmin(viewerID); isAdmin {
+		return true
+	}
+	if IsUserBlocked(user.ID, viewerID) {
+		return false
+	}
+	switch user.ProfileVisibility {
+	case models.ProfileVisibilityPublic:
+		return true
+	case models.ProfileVisibilityFollowers:
+		return IsFollowing(viewerID, models.FollowTargetUser, user.ID)
+	default:
+		return false
+	}
+}
+
+// publicPostQuery 用户公开发布的帖子，匿名帖子不计入以免暴露作者
+func publicPostQuery(userID uint) *gorm.DB {
+	return database.DB.Model(&models.Post{}).
+		Where("user_id = ? AND status = ? AND is_anonymous = ?", userID, models.PostStatusPublished, false)
+}
+
+// countLikesReceived 统计用户公开帖子收到的点赞数，不含自己点赞
+func countLikesReceived(userID uint) int64 {
+	var count int64
+	database.DB.Model(&models.Like{}).
+		Joins("JOIN posts ON posts.id = likes.post_id").
+		Where("posts.user_id = ? AND posts.status = ? AND posts.is_anonymous = ? AND likes.user_id <> posts.user_id",
+			userID, models.PostStatusPublished, false).
+		Count(&count)
+	return count
+}
+
+// GetUserProfile 获取用户主页；viewerID 为当前登录用户，用于可见范围判断
+func GetUserProfile(userID, viewerID uint) (*models.UserProfile, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil || user.IsClosed() {
+		return nil, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+
+	followers, following, _ := GetFollowCounts(userID)
+	profile := &models.UserProfile{
+		ID:          user.ID,
+		Username:    user.Username,
+		Name:        user.Name,
+		UserType:    user.UserType,
+		Reputation:  user.Reputation,
+		Followers:   followers,
+		Following:   following,
+		IsFollowing: viewerID != userID && IsFollowing(viewerID, models.FollowTargetUser, userID),
+	}
+	if user.AvatarID != 0 {
+		profile.AvatarURL = AttachmentURL(user.AvatarID, AttachmentVariantOriginal)
+		profile.AvatarThumbnailURL = AttachmentURL(user.AvatarID, AttachmentVariantThumbnail)
+	}
+	if viewerID == userID {
+		visibility := user.ProfileVisibility
+		profile.ProfileVisibility = &visibility
+	}
+
+	if !canViewProfileDetails(user, viewerID) {
+		profile.Restricted = true
+		return profile, nil
+	}
+
+	profile.Bio = user.Bio
+	profile.ClassName = user.ClassName
+	profile.Department = user.Department
+	publicPostQuery(userID).Count(&profile.PostCount)
+	profile.LikesReceived = countLikesReceived(userID)
+
+	var posts []models.Post
+	if err := publicPostQuery(userID).Order("post_time DESC").Limit(recentPostsLimit).Find(&posts).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1002, Message: "获取近期帖子失败: " + err.Error()}
+	}
+	profile.RecentPosts = make([]models.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		profile.RecentPosts = append(profile.RecentPosts, FormatPost(post, viewerID))
+	}
+	return profile, nil
+}
+
+// checkTextLength 去除首尾空白后校验长度
+func checkTextLength(value *string, limit int, field string) *models.ServiceError {
+	*value = strings.TrimSpace(*value)
+	if len([]rune(*value)) > limit {
+		return &models.ServiceError{Code: 1001, Message: field + "不能超过" + strconv.Itoa(limit) + "个字符"}
+	}
+	return nil
+}
+
+// UpdateProfile 修改个人资料；头像需先通过附件上传接口上传图片，再在此处设置
+func UpdateProfile(userID uint, input models.ProfileInput) *models.ServiceError {
+	updates := make(map[string]interface{})
+	for _, field := range []struct {
+		value  *string
+		column string
+		limit  int
+		label  string
+	}{
+		{input.Bio, "bio", maxBioLength, "个人简介"},
+		{input.ClassName, "class_name", maxClassNameLength, "班级"},
+		{input.Department, "department", maxDepartmentLength, "院系"},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if serviceErr := checkTextLength(field.value, field.limit, field.label); serviceErr != nil {
+			return serviceErr
+		}
+		updates[field.column] = *field.value
+	}
+
+	if input.ProfileVisibility != nil {
+		switch *input.ProfileVisibility {
+		case models.ProfileVisibilityPublic, models.ProfileVisibilityFollowers, models.ProfileVisibilityPrivate:
+			updates["profile_visibility"] = *input.ProfileVisibility
+		default:
+			return &models.ServiceError{Code: 1002, Message: "无效的可见范围"}
+		}
+	}
+
+	if input.AvatarID != nil {
+		if *input.AvatarID != 0 {
+			var attachment models.Attachment
+			err := database.DB.Where("id = ? AND user_id = ? AND post_id = 0", *input.AvatarID, userID).First(&attachment).Error
+			if err != nil {
+				return &models.ServiceError{Code: 1003, Message: "头像图片不存在或已被使用"}
+			}
+			// 头像展示依赖缩略图，只接受能生成缩略图的图片
+			if !thumbnailTypes[attachment.ContentType] || attachment.ThumbnailKey == "" {
+				return &models.ServiceError{Code: 1004, Message: "头像必须是 JPEG、PNG 或 GIF 图片"}
+			}
+		}
+		updates["avatar_id"] = *input.AvatarID
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		return &models.ServiceError{Code: 1005, Message: "修改个人资料失败: " + err.Error()}
+	}
+	return nil
+}
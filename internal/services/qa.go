@@ -0,0 +1,186 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 提问列表筛选条件
+const (
+	QuestionFilterAll        = ""
+	QuestionFilterUnanswered = "unanswered" // 还没有回答
+	QuestionFilterAnswered   = "answered"   // 已有回答
+	QuestionFilterAccepted   = "accepted"   // 已采纳回答
+)
+
+// getQuestion 获取已发布的提问
+func getQuestion(questionID uint) (*models.Post, *models.ServiceError) {
+	question, err := GetPostByID(questionID)
+	if err != nil || question.Status != models.PostStatusPublished || question.PostType != models.PostTypeQuestion {
+		return nil, &models.ServiceError{Code: 1401, Message: "提问不存在"}
+	}
+	return &question, nil
+}
+
+// CreateAnswer 回答提问，回答本身是 PostType 为回答的帖子，复用帖子的渲染、附件、点赞等能力
+func CreateAnswer(userID, questionID uint, content string, attachmentIDs []uint) (*models.Post, error) {
+	question, serviceErr := getQuestion(questionID)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	answer := models.Post{
+		Content:  content,
+		BoardID:  question.BoardID,
+		PostTime: time.Now(),
+		PostType: models.PostTypeAnswer,
+		ParentID: question.ID,
+	}
+	if err := CreatePost(userID, &answer, attachmentIDs, nil); err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(&models.Post{}).Where("id = ?", question.ID).
+		Update("answer_count", gorm.Expr("answer_count + 1")).Error; err != nil {
+		logger.GetLogger().Errorf("更新回答数失败: question_id=%d, err=%v", question.ID, err)
+	}
+	if question.UserID != userID {
+		if err := Notify(question.UserID, models.NotificationTypeAnswer, question.ID, "你的提问收到了新回答"); err != nil {
+			logger.GetLogger().Errorf("发送回答通知失败: question_id=%d, err=%v", question.ID, err)
+		}
+	}
+	return &answer, nil
+}
+
+// AcceptAnswer 采纳回答，提问者或管理员（教师）可操作；改为采纳其他回答时撤回原回答者的声望
+func AcceptAnswer(userID, questionID, answerID uint) *models.ServiceError {
+	question, serviceErr := getQuestion(questionID)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	if question.UserID != userID {
+		if isAdmin, _ := CheckUserIsAdmin(userID); !isAdmin {
+			return &models.ServiceError{Code: 1402, Message: "只有提问者或教师可以采纳回答"}
+		}
+	}
+
+	answer, err := GetPostByID(answerID)
+	if err != nil || answer.PostType != models.PostTypeAnswer || answer.ParentID != questionID {
+		return &models.ServiceError{Code: 1403, Message: "回答不存在"}
+	}
+	if question.AcceptedAnswerID == answerID {
+		return nil
+	}
+
+	// 带上原采纳条件，避免并发采纳时重复计算声望
+	result := database.DB.Model(&models.Post{}).
+		Where("id = ? AND accepted_answer_id = ?", questionID, question.AcceptedAnswerID).
+		Update("accepted_answer_id", answerID)
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1404, Message: "采纳失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1405, Message: "采纳状态已变化，请刷新后重试"}
+	}
+
+	if question.AcceptedAnswerID != 0 {
+		if previous, err := GetPostByID(question.AcceptedAnswerID); err == nil && previous.UserID != question.UserID {
+			AddReputation(previous.UserID, -ReputationAcceptedAnswer)
+		}
+	}
+	// 自问自答被采纳不获得声望
+	if answer.UserID != question.UserID {
+		AddReputation(answer.UserID, ReputationAcceptedAnswer)
+		if err := Notify(answer.UserID, models.NotificationTypeAnswer, questionID, "你的回答被采纳了"); err != nil {
+			logger.GetLogger().Errorf("发送采纳通知失败: answer_id=%d, err=%v", answerID, err)
+		}
+	}
+	return nil
+}
+
+// onAnswerDeleted 回答被删除后更新提问的回答数，被采纳的回答删除时取消采纳并撤回声望
+func onAnswerDeleted(answer models.Post) {
+	if answer.PostType != models.PostTypeAnswer {
+		return
+	}
+	database.DB.Model(&models.Post{}).Where("id = ? AND answer_count > 0", answer.ParentID).
+		Update("answer_count", gorm.Expr("answer_count - 1"))
+
+	result := database.DB.Model(&models.Post{}).
+		Where("id = ? AND accepted_answer_id = ?", answer.ParentID, answer.ID).
+		Update("accepted_answer_id", 0)
+	if result.Error == nil && result.RowsAffected > 0 {
+		if question, err := GetPostByID(answer.ParentID); err == nil && question.UserID != answer.UserID {
+			AddReputation(answer.UserID, -ReputationAcceptedAnswer)
+		}
+	}
+}
+
+// GetQuestions 分页获取提问列表，可按版块和回答状态筛选
+func GetQuestions(userID, boardID uint, filter string, page, pageSize int) ([]models.PostResponse, int64, *models.ServiceError) {
+	query := database.DB.Model(&models.Post{}).
+		Where("post_type = ? AND status = ?", models.PostTypeQuestion, models.PostStatusPublished)
+	if boardID != 0 {
+		query = query.Where("board_id = ?", boardID)
+	}
+	switch filter {
+	case QuestionFilterAll:
+	case QuestionFilterUnanswered:
+		query = query.Where("answer_count = 0")
+	case QuestionFilterAnswered:
+		query = query.Where("answer_count > 0")
+	case QuestionFilterAccepted:
+		query = query.Where("accepted_answer_id <> 0")
+	default:
+		return nil, 0, &models.ServiceError{Code: 1001, Message: "无效的筛选条件"}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, &models.ServiceError{Code: 1002, Message: "获取提问列表失败: " + err.Error()}
+	}
+	var posts []models.Post
+	if err := query.Order("post_time DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		return nil, 0, &models.ServiceError{Code: 1002, Message: "获取提问列表失败: " + err.Error()}
+	}
+
+	responses := make([]models.PostResponse, 0, len(posts))
+	for _, p := range posts {
+		responses = append(responses, FormatPost(p, userID))
+	}
+	return responses, total, nil
+}
+
+// GetAnswers 分页获取提问的回答，被采纳的回答排在最前；sortByVotes 为 true 时按点赞数排序，否则按时间
+func GetAnswers(userID, questionID uint, sortByVotes bool, page, pageSize int) ([]models.PostResponse, int64, *models.ServiceError) {
+	question, serviceErr := getQuestion(questionID)
+	if serviceErr != nil {
+		return nil, 0, serviceErr
+	}
+
+	query := database.DB.Model(&models.Post{}).
+		Where("post_type = ? AND parent_id = ? AND status = ?", models.PostTypeAnswer, questionID, models.PostStatusPublished)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, &models.ServiceError{Code: 1002, Message: "获取回答失败: " + err.Error()}
+	}
+
+	query = query.Order(gorm.Expr("id = ? DESC", question.AcceptedAnswerID))
+	if sortByVotes {
+		query = query.Order("(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) DESC")
+	}
+	var posts []models.Post
+	if err := query.Order("post_time").Offset((page - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		return nil, 0, &models.ServiceError{Code: 1002, Message: "获取回答失败: " + err.Error()}
+	}
+
+	responses := make([]models.PostResponse, 0, len(posts))
+	for _, p := range posts {
+		responses = append(responses, FormatPost(p, userID))
+	}
+	return responses, total, nil
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Redis 键名定义
+const (
+	reputationRankKey    = "user:reputation:rank"      // 用户声望排行榜：zset类型
+	reputationRebuildKey = "user:reputation:rebuild"   // 重算声望时写入的临时排行榜
+	reputationLockKey    = "lock:reputation:recompute" // 重算声望任务锁
+	reputationDirtyKey   = "user:reputation:dirty"     // 重算期间声望有变化的用户：set类型，重算结束前逐个重新计算
+	reputationLockTTL    = 10 * time.Minute
+)
+
+// 声望变化值
+const (
+	ReputationLikeReceived   = 2   // 帖子被他人点赞
+	ReputationAcceptedAnswer = 15  // 回答被采纳
+	ReputationReportUpheld   = 5   // 举报被管理员采纳
+	ReputationReportRejected = -2  // 举报被驳回
+	ReputationPostRemoved    = -20 // 帖子因举报被删除
+)
+
+const (
+	maxRankLimit       = 100 // 排行榜单次最多返回的条数
+	recomputeBatchSize = 500
+)
+
+// AddReputation 增减用户声望，同时更新 MySQL 和 Redis 排行榜
+func AddReputation(userID uint, delta int) {
+	if userID == 0 || delta == 0 {
+		return
+	}
+	err := database.DB.Model(&models.User{}).Where("id = ?", userID).
+		Update("reputation", gorm.Expr("reputation + ?", delta)).Error
+	if err != nil {
+		logger.GetLogger().Errorf("更新用户声望失败: user_id=%d, delta=%d, err=%v", userID, delta, err)
+		return
+	}
+	ctx := context.Background()
+	if err := redis.RedisClient.ZIncrBy(ctx, reputationRankKey, float64(delta), strconv.Itoa(int(userID))).Err(); err != nil {
+		logger.GetLogger().Errorf("更新声望排行榜失败: user_id=%d, delta=%d, err=%v", userID, delta, err)
+	}
+	// 重算进行中时这次变化可能被整体写回覆盖，记下用户，由重算在结束前按最新记录重新计算
+	if n, err := redis.RedisClient.Exists(ctx, reputationLockKey).Result(); err == nil && n > 0 {
+		redis.RedisClient.SAdd(ctx, reputationDirtyKey, userID)
+	}
+}
+
+// onLikeToggled 点赞或取消点赞后更新帖子作者的声望，自己点赞和匿名帖子不计入
+func onLikeToggled(postID, likerID uint, liked bool) {
+	post, err := GetPostByID(postID)
+	if err != nil || post.IsAnonymous || post.UserID == likerID {
+		return
+	}
+	if liked {
+		AddReputation(post.UserID, ReputationLikeReceived)
+	} else {
+		AddReputation(post.UserID, -ReputationLikeReceived)
+	}
+}
+
+// countCreditedLikes 统计帖子中计入作者声望的点赞数，需在点赞记录删除前调用
+func countCreditedLikes(tx *gorm.DB, post models.Post) int64 {
+	if post.IsAnonymous {
+		return 0
+	}
+	var count int64
+	tx.Model(&models.Like{}).Where("post_id = ? AND user_id <> ?", post.ID, post.UserID).Count(&count)
+	return count
+}
+
+// onPostRemoved 帖子被删除后撤回其带来的声望；因举报被删除时作者额外扣分
+func onPostRemoved(post models.Post, creditedLikes int64, byReport bool) {
+	delta := -int(creditedLikes) * ReputationLikeReceived
+	if byReport {
+		delta += ReputationPostRemoved
+	}
+	AddReputation(post.UserID, delta)
+
+	// 提问被删除后，被采纳回答的作者同样失去采纳所得的声望
+	if post.PostType == models.PostTypeQuestion && post.AcceptedAnswerID != 0 {
+		if answer, err := GetPostByID(post.AcceptedAnswerID); err == nil && answer.UserID != post.UserID {
+			AddReputation(answer.UserID, -ReputationAcceptedAnswer)
+		}
+	}
+}
+
+// onReportDecided 举报审批后调整举报人的声望
+func onReportDecided(reporterID uint, approval int) {
+	if approval == 1 {
+		AddReputation(reporterID, ReputationReportUpheld)
+	} else {
+		AddReputation(reporterID, ReputationReportRejected)
+	}
+}
+
+// ReportWeight 根据举报人声望计算举报权重，用于管理员审核时排序：
+// 声望每100分增加1倍权重，最低0.5，最高3
+func ReportWeight(reputation int) float64 {
+	weight := 1 + float64(reputation)/100
+	return max(0.5, min(3, weight))
+}
+
+// needsPreModeration 判断用户发帖是否需要先审后发：管理员和声望达到阈值的用户免审核
+func needsPreModeration(userID uint) bool {
+	if !config.LoadedConfig.Reputation.PreModeration {
+		return false
+	}
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return true
+	}
+	return user.UserType != models.AdminRole && user.Reputation < config.LoadedConfig.Reputation.TrustedThreshold
+}
+
+// GetReputationRank 获取声望排行榜前 limit 名
+func GetReputationRank(limit int) ([]models.ReputationRankItem, error) {
+	if limit <= 0 || limit > maxRankLimit {
+		limit = 20
+	}
+	members, err := redis.RedisClient.ZRevRangeWithScores(context.Background(), reputationRankKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.ReputationRankItem, 0, len(members))
+	for i, m := range members {
+		id, _ := strconv.Atoi(m.Member.(string))
+		item := models.ReputationRankItem{
+			Rank:       int64(i + 1),
+			UserID:     uint(id),
+			Reputation: int(m.Score),
+		}
+		if user, err := GetUserByID(item.UserID); err == nil {
+			item.Username = user.Username
+			item.Name = user.Name
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetUserReputationRank 获取用户在声望排行榜中的名次，未上榜时返回0
+func GetUserReputationRank(userID uint) int64 {
+	rank, err := redis.RedisClient.ZRevRank(context.Background(), reputationRankKey, strconv.Itoa(int(userID))).Result()
+	if err != nil {
+		return 0
+	}
+	return rank + 1
+}
+
+// reputationRow 重算声望时按用户汇总的查询结果
+type reputationRow struct {
+	UserID uint
+	Count  int64
+}
+
+// collectReputation 汇总一类历史记录带来的声望
+func collectReputation(scores map[uint]int, points int, query *gorm.DB) error {
+	var rows []reputationRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		scores[row.UserID] += int(row.Count) * points
+	}
+	return nil
+}
+
+// reputationSource 一类计入声望的历史记录
+type reputationSource struct {
+	points int
+	column string // 获得声望的用户所在的列
+	query  func(db *gorm.DB) *gorm.DB
+}
+
+var reputationSources = []reputationSource{
+	// 收到的点赞：只统计已发布的非匿名帖子，不含自己点赞
+	{ReputationLikeReceived, "posts.user_id", func(db *gorm.DB) *gorm.DB {
+		return db.Table("likes").
+			Select("posts.user_id AS user_id, COUNT(*) AS count").
+			Joins("JOIN posts ON posts.id = likes.post_id").
+			Where("posts.status = ? AND posts.is_anonymous = ? AND likes.user_id <> posts.user_id", models.PostStatusPublished, false).
+			Group("posts.user_id")
+	}},
+	// 被采纳的回答：不含自问自答
+	{ReputationAcceptedAnswer, "a.user_id", func(db *gorm.DB) *gorm.DB {
+		return db.Table("posts AS q").
+			Select("a.user_id AS user_id, COUNT(*) AS count").
+			Joins("JOIN posts AS a ON a.id = q.accepted_answer_id").
+			Where("q.post_type = ? AND a.user_id <> q.user_id", models.PostTypeQuestion).
+			Group("a.user_id")
+	}},
+	// 举报结果：帖子和私信举报均计入举报人
+	{ReputationReportUpheld, "user_id", func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.Block{}).Select("user_id, COUNT(*) AS count").Where("status = ?", 1).Group("user_id")
+	}},
+	{ReputationReportRejected, "user_id", func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.Block{}).Select("user_id, COUNT(*) AS count").Where("status = ?", 2).Group("user_id")
+	}},
+	// 因举报被删除的帖子：同一帖子的多条举报只扣一次
+	{ReputationPostRemoved, "target_user_id", func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.Block{}).
+			Select("target_user_id AS user_id, COUNT(DISTINCT target_id) AS count").
+			Where("target_type = ? AND status = ? AND target_user_id <> 0", models.BlockTargetPost, 1).
+			Group("target_user_id")
+	}},
+}
+
+// computeReputation 根据历史记录计算声望，userIDs 为空时计算所有用户
+func computeReputation(db *gorm.DB, userIDs []uint) (map[uint]int, error) {
+	scores := make(map[uint]int)
+	for _, source := range reputationSources {
+		query := source.query(db)
+		if len(userIDs) > 0 {
+			query = query.Where(source.column+" IN ?", userIDs)
+		}
+		if err := collectReputation(scores, source.points, query); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// recomputeDirtyUsers 重新计算重算期间声望发生过增量变化的用户，直到没有新的变化
+func recomputeDirtyUsers(ctx context.Context) (int, error) {
+	db := database.DB
+	changed := 0
+	for {
+		members, err := redis.RedisClient.SPopN(ctx, reputationDirtyKey, recomputeBatchSize).Result()
+		if err != nil {
+			return changed, err
+		}
+		if len(members) == 0 {
+			return changed, nil
+		}
+		userIDs := make([]uint, 0, len(members))
+		for _, member := range members {
+			if id, err := strconv.Atoi(member); err == nil && id > 0 {
+				userIDs = append(userIDs, uint(id))
+			}
+		}
+		scores, err := computeReputation(db, userIDs)
+		if err != nil {
+			return changed, err
+		}
+		var users []models.User
+		if err := db.Select("id", "reputation").Where("id IN ? AND closed_at IS NULL", userIDs).Find(&users).Error; err != nil {
+			return changed, err
+		}
+		for _, user := range users {
+			score := scores[user.ID]
+			if score != user.Reputation {
+				if err := db.Model(&models.User{}).Where("id = ?", user.ID).Update("reputation", score).Error; err != nil {
+					return changed, err
+				}
+				changed++
+			}
+			if err := redis.RedisClient.ZAdd(ctx, reputationRankKey, &goredis.Z{Score: float64(score), Member: strconv.Itoa(int(user.ID))}).Err(); err != nil {
+				return changed, err
+			}
+		}
+	}
+}
+
+// RecomputeReputation 根据 MySQL 中的历史记录重新计算所有用户的声望并重建排行榜，
+// 用于声望规则调整或数据不一致时修复。返回声望发生变化的用户数。
+// 重算期间线上的增量更新照常进行，涉及的用户会在整体写回后按最新记录再算一次，不会被覆盖
+func RecomputeReputation() (int, error) {
+	token, ok, err := redis.TryLock(reputationLockKey, reputationLockTTL)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("声望重算正在进行中")
+	}
+	defer redis.Unlock(reputationLockKey, token)
+
+	ctx := context.Background()
+	redis.RedisClient.Del(ctx, reputationDirtyKey)
+
+	db := database.DB
+	scores, err := computeReputation(db, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// 写回 MySQL，只更新发生变化的用户；已注销的账号不参与排行
+	changed := 0
+	var users []models.User
+	redis.RedisClient.Del(ctx, reputationRebuildKey)
+	result := db.Select("id", "reputation").Where("closed_at IS NULL").FindInBatches(&users, recomputeBatchSize, func(tx *gorm.DB, batch int) error {
+		members := make([]*goredis.Z, 0, len(users))
+		for _, user := range users {
+			score := scores[user.ID]
+			if score != user.Reputation {
+				if err := db.Model(&models.User{}).Where("id = ?", user.ID).Update("reputation", score).Error; err != nil {
+					return err
+				}
+				changed++
+			}
+			members = append(members, &goredis.Z{Score: float64(score), Member: strconv.Itoa(int(user.ID))})
+		}
+		if len(members) > 0 {
+			return redis.RedisClient.ZAdd(ctx, reputationRebuildKey, members...).Err()
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return changed, result.Error
+	}
+
+	// 临时排行榜构建完成后整体替换，避免读取到不完整的数据
+	if result.RowsAffected == 0 {
+		if err := redis.RedisClient.Del(ctx, reputationRankKey).Err(); err != nil {
+			return changed, err
+		}
+	} else if err := redis.RedisClient.Rename(ctx, reputationRebuildKey, reputationRankKey).Err(); err != nil {
+		return changed, err
+	}
+
+	// 整体写回和替换排行榜会覆盖重算期间的增量更新，持有锁期间重新计算这些用户
+	dirty, err := recomputeDirtyUsers(ctx)
+	changed += dirty
+	if err != nil {
+		return changed, err
+	}
+	logger.GetLogger().Infof("声望重算完成: 共%d名用户声望发生变化", changed)
+	return changed, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+func setupReputationTest(t *testing.T) (author models.User, likers []models.User, post models.Post) {
+	t.Helper()
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.Post{}, &models.Like{}, &models.Block{})
+
+	author = models.User{Username: "author", Password: "x", UserType: models.StudentRole}
+	database.DB.Create(&author)
+	for i := 0; i < 3; i++ {
+		u := models.User{Username: "liker" + strconv.Itoa(i), Password: "x", UserType: models.StudentRole}
+		database.DB.Create(&u)
+		likers = append(likers, u)
+	}
+	post = models.Post{Content: "内容", UserID: author.ID}
+	database.DB.Create(&post)
+	return author, likers, post
+}
+
+func rankScore(t *testing.T, userID uint) float64 {
+	t.Helper()
+	score, err := redis.RedisClient.ZScore(context.Background(), reputationRankKey, strconv.Itoa(int(userID))).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return score
+}
+
+func TestRecomputeReputation(t *testing.T) {
+	author, likers, post := setupReputationTest(t)
+	database.DB.Create(&models.Like{PostID: post.ID, UserID: likers[0].ID})
+	database.DB.Create(&models.Like{PostID: post.ID, UserID: likers[1].ID})
+	database.DB.Create(&models.Like{PostID: post.ID, UserID: author.ID}) // 自己点赞不计入
+	database.DB.Model(&author).Update("reputation", 999)
+
+	if _, err := RecomputeReputation(); err != nil {
+		t.Fatal(err)
+	}
+	want := 2 * ReputationLikeReceived
+	if got := reloadUser(t, author.ID).Reputation; got != want {
+		t.Fatalf("声望应为 %d，实际 %d", want, got)
+	}
+	if got := rankScore(t, author.ID); got != float64(want) {
+		t.Fatalf("排行榜分数应为 %d，实际 %v", want, got)
+	}
+}
+
+func TestAddReputationDuringRecompute(t *testing.T) {
+	author, likers, post := setupReputationTest(t)
+	database.DB.Create(&models.Like{PostID: post.ID, UserID: likers[0].ID})
+	if _, err := RecomputeReputation(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟重算进行中：整体写回使用的是新点赞之前的数据
+	token, ok, err := redis.TryLock(reputationLockKey, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	database.DB.Create(&models.Like{PostID: post.ID, UserID: likers[1].ID})
+	AddReputation(author.ID, ReputationLikeReceived)
+	database.DB.Model(&author).Update("reputation", ReputationLikeReceived)
+	redis.RedisClient.ZAdd(context.Background(), reputationRankKey, &goredis.Z{Score: ReputationLikeReceived, Member: strconv.Itoa(int(author.ID))})
+
+	if members, _ := redis.RedisClient.SMembers(context.Background(), reputationDirtyKey).Result(); len(members) != 1 || members[0] != strconv.Itoa(int(author.ID)) {
+		t.Fatalf("重算期间的增量更新应记录用户: %v", members)
+	}
+	if _, err := recomputeDirtyUsers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	redis.Unlock(reputationLockKey, token)
+
+	want := 2 * ReputationLikeReceived
+	if got := reloadUser(t, author.ID).Reputation; got != want {
+		t.Fatalf("增量更新不应被重算覆盖: 声望应为 %d，实际 %d", want, got)
+	}
+	if got := rankScore(t, author.ID); got != float64(want) {
+		t.Fatalf("排行榜分数应为 %d，实际 %v", want, got)
+	}
+
+	// 没有重算时不记录
+	AddReputation(author.ID, ReputationLikeReceived)
+	if n, _ := redis.RedisClient.SCard(context.Background(), reputationDirtyKey).Result(); n != 0 {
+		t.Fatalf("未在重算时不应记录用户: %d", n)
+	}
+}
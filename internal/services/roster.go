@@ -0,0 +1,469 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"CMS/pkg/xlsx"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"gorm.io/gorm"
+)
+
+// Redis 键名定义
+const (
+	rosterResultKey = "roster:result:" // 导入结果文件（含初始密码）：string类型，下载一次后删除
+
+	RosterMaxFileBytes    = 10 << 20        // 名单文件大小上限
+	RosterDefaultBatch    = 500             // 每个事务处理的行数
+	maxRosterRows         = 20000           // 单次导入的最大行数
+	rosterResultTTL       = 5 * time.Minute // 结果文件含明文初始密码，导入后应立即下载
+	initialPasswordTTL    = 7 * 24 * time.Hour
+	initialPasswordLength = 12
+)
+
+// initialPasswordAlphabet 初始密码字符集，去掉了容易混淆的 0/O、1/l/I
+const initialPasswordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// rosterHeaders 表头别名，按小写比较
+var rosterHeaders = map[string]string{
+	"username": "username", "学号": "username", "工号": "username", "账号": "username",
+	"name": "name", "姓名": "name",
+	"class": "class", "class_name": "class", "班级": "class",
+	"role": "role", "user_type": "role", "角色": "role", "身份": "role",
+}
+
+// parseRosterRole 解析角色列，为空时默认为学生（只对新建账号生效，已有账号见 importRosterBatch）
+func parseRosterRole(value string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "1", "student", "学生":
+		return models.StudentRole, true
+	case "2", "admin", "管理员":
+		return models.AdminRole, true
+	default:
+		return 0, false
+	}
+}
+
+// readRosterTable 按扩展名读取 CSV 或 XLSX；CSV 兼容 UTF-8 BOM 和 Excel 默认保存的 GBK 编码
+func readRosterTable(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		return xlsx.ReadFirstSheet(bytes.NewReader(data), int64(len(data)))
+	case ".csv":
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(data) {
+			decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		}
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", filepath.Ext(filename))
+	}
+}
+
+// ParseRoster 解析名单文件，第一行为表头，必须包含账号和姓名列；格式错误的行在导入时作为错误行报告
+func ParseRoster(filename string, data []byte) ([]models.RosterRow, []models.RosterResultItem, *models.ServiceError) {
+	table, err := readRosterTable(filename, data)
+	if err != nil {
+		return nil, nil, &models.ServiceError{Code: 1001, Message: "无法解析名单文件，请上传 CSV 或 XLSX 文件: " + err.Error()}
+	}
+	if len(table) == 0 {
+		return nil, nil, &models.ServiceError{Code: 1002, Message: "名单文件为空"}
+	}
+	if len(table)-1 > maxRosterRows {
+		return nil, nil, &models.ServiceError{Code: 1003, Message: fmt.Sprintf("单次最多导入%d行", maxRosterRows)}
+	}
+
+	columns := make(map[string]int)
+	for i, header := range table[0] {
+		if field, ok := rosterHeaders[strings.ToLower(strings.TrimSpace(header))]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, nil, &models.ServiceError{Code: 1004, Message: "表头缺少账号（username/学号）列"}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, &models.ServiceError{Code: 1004, Message: "表头缺少姓名（name）列"}
+	}
+	_, hasClass := columns["class"]
+	_, hasRole := columns["role"]
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []models.RosterRow
+	var invalid []models.RosterResultItem
+	seen := make(map[string]int)
+	for i, record := range table[1:] {
+		rowNumber := i + 2
+		row := models.RosterRow{
+			Row:       rowNumber,
+			Username:  cell(record, "username"),
+			Name:      cell(record, "name"),
+			ClassName: cell(record, "class"),
+
+			HasClassName: hasClass,
+			HasUserType:  hasRole,
+		}
+		// 跳过空行
+		if row.Username == "" && row.Name == "" && row.ClassName == "" && cell(record, "role") == "" {
+			continue
+		}
+
+		fail := func(message string) {
+			invalid = append(invalid, models.RosterResultItem{
+				Row:       row.Row,
+				Username:  row.Username,
+				Name:      row.Name,
+				ClassName: row.ClassName,
+				Action:    models.RosterActionError,
+				Error:     message,
+			})
+		}
+		userType, ok := parseRosterRole(cell(record, "role"))
+		if !ok {
+			fail("无效的角色: " + cell(record, "role"))
+			continue
+		}
+		row.UserType = userType
+		if serviceErr := ValidateNewUser(row.Username, row.Name, row.UserType); serviceErr != nil {
+			fail(serviceErr.Message)
+			continue
+		}
+		if len([]rune(row.ClassName)) > maxClassNameLength {
+			fail(fmt.Sprintf("班级不能超过%d个字符", maxClassNameLength))
+			continue
+		}
+		if first, dup := seen[row.Username]; dup {
+			fail(fmt.Sprintf("账号与第%d行重复", first))
+			continue
+		}
+		seen[row.Username] = rowNumber
+		rows = append(rows, row)
+	}
+	return rows, invalid, nil
+}
+
+// generateInitialPassword 生成满足密码强度要求的随机初始密码
+func generateInitialPassword(username string) (string, error) {
+	max := big.NewInt(int64(len(initialPasswordAlphabet)))
+	for {
+		buf := make([]byte, initialPasswordLength)
+		for i := range buf {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			buf[i] = initialPasswordAlphabet[n.Int64()]
+		}
+		password := string(buf)
+		if ValidatePasswordStrength(username, password) == nil {
+			return password, nil
+		}
+	}
+}
+
+// hashPasswords 并发计算 bcrypt 哈希，导入上百个账号时避免逐个串行计算
+func hashPasswords(passwords []string) ([]string, error) {
+	hashes := make([]string, len(passwords))
+	errs := make([]error, len(passwords))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hashed, err := bcrypt.GenerateFromPassword([]byte(passwords[i]), bcrypt.DefaultCost)
+				hashes[i], errs[i] = string(hashed), err
+			}
+		}()
+	}
+	for i := range passwords {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// importRosterBatch 在一个事务中处理一批名单；adminID 为操作的管理员（命令行导入时为 0），
+// 返回每行的结果，事务失败时整批标记为错误
+func importRosterBatch(adminID uint, adminUsername string, batch []models.RosterRow, dryRun bool) []models.RosterResultItem {
+	items := make([]models.RosterResultItem, len(batch))
+	usernames := make([]string, len(batch))
+	for i, row := range batch {
+		items[i] = models.RosterResultItem{
+			Row:       row.Row,
+			Username:  row.Username,
+			Name:      row.Name,
+			ClassName: row.ClassName,
+			UserType:  row.UserType,
+		}
+		usernames[i] = row.Username
+	}
+	failAll := func(message string) []models.RosterResultItem {
+		for i := range items {
+			if items[i].Action != models.RosterActionError {
+				items[i].Action = models.RosterActionError
+				items[i].InitialPassword = ""
+				items[i].Error = message
+			}
+		}
+		return items
+	}
+
+	var existing []models.User
+	if err := database.DB.Where("username IN ?", usernames).Find(&existing).Error; err != nil {
+		return failAll("查询已有账号失败: " + err.Error())
+	}
+	byUsername := make(map[string]models.User, len(existing))
+	for _, user := range existing {
+		byUsername[user.Username] = user
+	}
+
+	// 先确定每行的处理方式，新账号生成初始密码
+	var newIndexes []int
+	var passwords []string
+	updates := make(map[int]map[string]interface{})
+	for i, row := range batch {
+		user, ok := byUsername[row.Username]
+		if !ok {
+			items[i].Action = models.RosterActionCreate
+			if !dryRun {
+				password, err := generateInitialPassword(row.Username)
+				if err != nil {
+					return failAll("生成初始密码失败")
+				}
+				items[i].InitialPassword = password
+				passwords = append(passwords, password)
+				newIndexes = append(newIndexes, i)
+			}
+			continue
+		}
+
+		switch {
+		case user.IsServiceAccount:
+			items[i].Action = models.RosterActionError
+			items[i].Error = "该账号为服务账号"
+			continue
+		case row.HasUserType && adminUsername != "" && user.Username == adminUsername && user.UserType != row.UserType:
+			items[i].Action = models.RosterActionError
+			items[i].Error = "不能通过导入修改自己的角色"
+			continue
+		}
+		// 只修改名单中包含的列，缺少的列保留账号原有的值
+		changes := make(map[string]interface{})
+		if user.Name != row.Name {
+			changes["name"] = row.Name
+		}
+		if row.HasClassName && user.ClassName != row.ClassName {
+			changes["class_name"] = row.ClassName
+		}
+		if row.HasUserType && user.UserType != row.UserType {
+			changes["user_type"] = row.UserType
+		}
+		if len(changes) == 0 {
+			items[i].Action = models.RosterActionUnchanged
+			continue
+		}
+		items[i].Action = models.RosterActionUpdate
+		updates[i] = changes
+	}
+	if dryRun {
+		return items
+	}
+
+	hashes, err := hashPasswords(passwords)
+	if err != nil {
+		return failAll("生成初始密码失败")
+	}
+	// 初始密码只能用于首次登录时设置新密码，且到期后失效，需由管理员签发重置凭证
+	expiresAt := time.Now().Add(initialPasswordTTL)
+	newUsers := make([]models.User, 0, len(newIndexes))
+	for j, i := range newIndexes {
+		newUsers = append(newUsers, models.User{
+			Username:           batch[i].Username,
+			Password:           hashes[j],
+			Name:               batch[i].Name,
+			ClassName:          batch[i].ClassName,
+			UserType:           batch[i].UserType,
+			MustChangePassword: true,
+			PasswordExpiresAt:  &expiresAt,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(newUsers) > 0 {
+			if err := tx.Create(&newUsers).Error; err != nil {
+				return err
+			}
+		}
+		for i, changes := range updates {
+			user := byUsername[batch[i].Username]
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(changes).Error; err != nil {
+				return err
+			}
+			// 角色变更涉及权限，每个账号单独记录审计日志
+			if role, ok := changes["user_type"]; ok {
+				err := tx.Create(&models.AuditLog{
+					AdminID:  adminID,
+					Action:   "import_role_change",
+					TargetID: user.ID,
+					Detail:   fmt.Sprintf(`{"from": %d, "to": %d}`, user.UserType, role),
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.GetLogger().Errorf("名单导入批次写入失败: admin_user_id=%d, first_row=%d, error=%v", adminID, batch[0].Row, err)
+		return failAll("批量写入失败: " + err.Error())
+	}
+	return items
+}
+
+// ImportRoster 批量导入名单：已有账号更新姓名、班级和角色，新账号生成一次性展示的初始密码；
+// dryRun 时只预览处理结果，不写入数据库。invalid 为解析阶段已判定为错误的行，一并计入结果
+func ImportRoster(adminID uint, rows []models.RosterRow, invalid []models.RosterResultItem, dryRun bool, batchSize int) (*models.RosterImportResult, *models.ServiceError) {
+	if batchSize <= 0 {
+		batchSize = RosterDefaultBatch
+	}
+	var adminUsername string
+	if adminID != 0 {
+		admin, err := GetUserByID(adminID)
+		if err != nil {
+			return nil, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+		}
+		adminUsername = admin.Username
+	}
+
+	result := &models.RosterImportResult{DryRun: dryRun}
+	result.Items = append(result.Items, invalid...)
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		result.Items = append(result.Items, importRosterBatch(adminID, adminUsername, rows[start:end], dryRun)...)
+	}
+
+	// 按行号排序，便于对照原文件
+	sort.Slice(result.Items, func(i, j int) bool { return result.Items[i].Row < result.Items[j].Row })
+	result.Total = len(result.Items)
+	for _, item := range result.Items {
+		switch item.Action {
+		case models.RosterActionCreate:
+			result.Created++
+		case models.RosterActionUpdate:
+			result.Updated++
+		case models.RosterActionUnchanged:
+			result.Unchanged++
+		default:
+			result.Failed++
+		}
+	}
+
+	if !dryRun && (result.Created > 0 || result.Updated > 0) {
+		err := database.DB.Create(&models.AuditLog{
+			AdminID: adminID,
+			Action:  "import_users",
+			Detail: fmt.Sprintf(`{"total": %d, "created": %d, "updated": %d, "failed": %d}`,
+				result.Total, result.Created, result.Updated, result.Failed),
+		}).Error
+		if err != nil {
+			logger.GetLogger().Errorf("记录名单导入审计日志失败: admin_user_id=%d, error=%v", adminID, err)
+		}
+	}
+	return result, nil
+}
+
+// WriteRosterResult 将导入结果写为 CSV（含新账号的初始密码），带 BOM 以便 Excel 正确识别中文
+func WriteRosterResult(w io.Writer, result *models.RosterImportResult) error {
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "username", "name", "class", "role", "action", "initial_password", "error"})
+	for _, item := range result.Items {
+		role := ""
+		if item.UserType != 0 {
+			role = strconv.Itoa(item.UserType)
+		}
+		writer.Write([]string{
+			strconv.Itoa(item.Row), item.Username, item.Name, item.ClassName, role,
+			item.Action, item.InitialPassword, item.Error,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// SaveRosterResultFile 暂存导入结果文件供管理员下载，返回下载ID；文件含初始密码，只能下载一次
+func SaveRosterResultFile(result *models.RosterImportResult) (string, error) {
+	var buf bytes.Buffer
+	if err := WriteRosterResult(&buf, result); err != nil {
+		return "", err
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	if err := redis.RedisClient.Set(context.Background(), rosterResultKey+id, buf.Bytes(), rosterResultTTL).Err(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// TakeRosterResultFile 取出导入结果文件，取出后即删除
+func TakeRosterResultFile(id string) ([]byte, bool) {
+	ctx := context.Background()
+	key := rosterResultKey + id
+	pipe := redis.RedisClient.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, false
+	}
+	data, err := getCmd.Bytes()
+	return data, err == nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+)
+
+func TestImportRosterOnlyUpdatesPresentColumns(t *testing.T) {
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.AuditLog{})
+
+	admin := models.User{Username: "1001", Password: "x", Name: "王老师", ClassName: "教务处", UserType: models.AdminRole}
+	if err := database.DB.Create(&admin).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 只有账号和姓名列：不修改角色和班级
+	rows, invalid, serviceErr := ParseRoster("roster.csv", []byte("学号,姓名\n1001,王老师\n"))
+	if serviceErr != nil || len(invalid) != 0 {
+		t.Fatalf("解析失败: %v, %+v", serviceErr, invalid)
+	}
+	result, serviceErr := ImportRoster(0, rows, invalid, false, 0)
+	if serviceErr != nil || result.Unchanged != 1 {
+		t.Fatalf("缺少的列不应产生修改: %+v, %v", result, serviceErr)
+	}
+	if got := reloadUser(t, admin.ID); got.UserType != models.AdminRole || got.ClassName != "教务处" {
+		t.Fatalf("缺少角色和班级列时应保留原值: %+v", got)
+	}
+
+	// 包含角色列时修改角色，并单独记录审计日志
+	rows, invalid, _ = ParseRoster("roster.csv", []byte("学号,姓名,角色\n1001,王老师,学生\n"))
+	if result, serviceErr = ImportRoster(0, rows, invalid, false, 0); serviceErr != nil || result.Updated != 1 {
+		t.Fatalf("应修改角色: %+v, %v", result, serviceErr)
+	}
+	if got := reloadUser(t, admin.ID); got.UserType != models.StudentRole || got.ClassName != "教务处" {
+		t.Fatalf("只应修改角色: %+v", got)
+	}
+	var audit models.AuditLog
+	if err := database.DB.Where("action = ? AND target_id = ?", "import_role_change", admin.ID).First(&audit).Error; err != nil {
+		t.Fatalf("角色变更应写入审计日志: %v", err)
+	}
+	if audit.Detail != `{"from": 2, "to": 1}` {
+		t.Fatalf("审计内容错误: %s", audit.Detail)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"CMS/pkg/utils"
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Redis 键名定义
+const (
+	sessionKey = "session:" // 有效会话：hash类型（user_id、last_seen），过期时间与 token 一致，撤销时删除
+
+	sessionIDBytes     = 16
+	maxUserAgentLength = 255
+)
+
+// touchSessionScript 会话存在时更新最近活跃时间并返回所属用户ID，不存在时返回 0（不会重新创建已撤销的会话）
+var touchSessionScript = goredis.NewScript(`
+local uid = redis.call("HGET", KEYS[1], "user_id")
+if not uid then
+	return 0
+end
+redis.call("HSET", KEYS[1], "last_seen", ARGV[1])
+return tonumber(uid)
+`)
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
+
+// cacheSession 将有效会话写入 Redis，过期时间与 token 一致
+func cacheSession(ctx context.Context, sessionID string, userID uint, lastSeen, expiresAt time.Time) error {
+	key := sessionKey + sessionID
+	pipe := redis.RedisClient.TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "last_seen", lastSeen.Unix())
+	pipe.ExpireAt(ctx, key, expiresAt)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// StartSession 登录成功后创建会话并签发 token
+func StartSession(userID uint, mfa bool, userAgent, ip string) (string, error) {
+	sessionID, err := randomHex(sessionIDBytes)
+	if err != nil {
+		return "", err
+	}
+	token, expiresAt, err := utils.GenerateSessionToken(userID, sessionID, mfa)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := models.UserSession{
+		UserID:     userID,
+		SessionID:  sessionID,
+		UserAgent:  truncateUserAgent(userAgent),
+		IP:         ip,
+		MFA:        mfa,
+		ExpiresAt:  expiresAt,
+		LastSeenAt: now,
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return "", err
+	}
+	if err := cacheSession(context.Background(), sessionID, userID, now, expiresAt); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ValidateSession 校验会话是否有效并记录最近活跃时间，只访问 Redis
+func ValidateSession(sessionID string, userID uint) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	uid, err := touchSessionScript.Run(context.Background(), redis.RedisClient,
+		[]string{sessionKey + sessionID}, time.Now().Unix()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return uid != 0 && uint(uid) == userID, nil
+}
+
+// RefreshSession 为当前会话签发新的 token 并延长有效期，mfa 为新 token 是否标记通过两步验证
+func RefreshSession(userID uint, sessionID string, mfa bool, userAgent, ip string) (string, *models.ServiceError) {
+	var session models.UserSession
+	err := database.DB.Where("session_id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error
+	if err != nil {
+		return "", &models.ServiceError{Code: 401, Message: "登录已失效，请重新登录"}
+	}
+
+	token, expiresAt, err := utils.GenerateSessionToken(userID, sessionID, mfa)
+	if err != nil {
+		return "", &models.ServiceError{Code: 1001, Message: "生成token失败"}
+	}
+	now := time.Now()
+	err = database.DB.Model(&session).Updates(map[string]interface{}{
+		"user_agent":   truncateUserAgent(userAgent),
+		"ip":           ip,
+		"mfa":          mfa,
+		"expires_at":   expiresAt,
+		"last_seen_at": now,
+	}).Error
+	if err != nil {
+		return "", &models.ServiceError{Code: 1002, Message: "刷新token失败: " + err.Error()}
+	}
+	if err := cacheSession(context.Background(), sessionID, userID, now, expiresAt); err != nil {
+		return "", &models.ServiceError{Code: 1002, Message: "刷新token失败: " + err.Error()}
+	}
+	return token, nil
+}
+
+// GetSessions 获取用户的有效会话，最近活跃时间以 Redis 中的记录为准
+func GetSessions(userID uint, currentSessionID string) ([]models.SessionResponse, error) {
+	var sessions []models.UserSession
+	err := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("id DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	pipe := redis.RedisClient.Pipeline()
+	cmds := make([]*goredis.StringCmd, len(sessions))
+	for i, session := range sessions {
+		cmds[i] = pipe.HGet(ctx, sessionKey+session.SessionID, "last_seen")
+	}
+	pipe.Exec(ctx)
+
+	list := make([]models.SessionResponse, 0, len(sessions))
+	for i, session := range sessions {
+		if ts, err := strconv.ParseInt(cmds[i].Val(), 10, 64); err == nil {
+			session.LastSeenAt = time.Unix(ts, 0)
+		}
+		list = append(list, session.ToResponse(currentSessionID))
+	}
+	return list, nil
+}
+
+// revokeSessions 撤销用户的会话：先在数据库中标记，再删除 Redis 中的缓存；exceptSessionID 不为空时保留该会话
+func revokeSessions(userID uint, exceptSessionID string) (int64, error) {
+	query := database.DB.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != "" {
+		query = query.Where("session_id <> ?", exceptSessionID)
+	}
+	var sessionIDs []string
+	if err := query.Pluck("session_id", &sessionIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+
+	err := database.DB.Model(&models.UserSession{}).
+		Where("session_id IN ? AND revoked_at IS NULL", sessionIDs).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, sessionKey+sessionID)
+	}
+	if err := redis.RedisClient.Del(context.Background(), keys...).Err(); err != nil {
+		return 0, err
+	}
+	return int64(len(sessionIDs)), nil
+}
+
+// RevokeAllSessions 撤销用户的全部会话（重置密码、删除账号等场景），失败只记录日志
+func RevokeAllSessions(userID uint) {
+	if _, err := revokeSessions(userID, ""); err != nil {
+		logger.GetLogger().Errorf("撤销用户会话失败: user_id=%d, error=%v", userID, err)
+	}
+}
+
+// RevokeSession 撤销用户自己的某个会话
+func RevokeSession(userID, id uint) *models.ServiceError {
+	var session models.UserSession
+	err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&session).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1001, Message: "会话不存在或已失效"}
+	}
+	if err := database.DB.Model(&session).Update("revoked_at", time.Now()).Error; err != nil {
+		return &models.ServiceError{Code: 1002, Message: "撤销会话失败: " + err.Error()}
+	}
+	if err := redis.RedisClient.Del(context.Background(), sessionKey+session.SessionID).Err(); err != nil {
+		return &models.ServiceError{Code: 1002, Message: "撤销会话失败: " + err.Error()}
+	}
+	return nil
+}
+
+// RevokeSessionBySessionID 撤销当前会话（退出登录）
+func RevokeSessionBySessionID(userID uint, sessionID string) *models.ServiceError {
+	var session models.UserSession
+	err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1001, Message: "会话不存在或已失效"}
+	}
+	return RevokeSession(userID, session.ID)
+}
+
+// RevokeOtherSessions 退出除当前会话外的所有设备，返回撤销的会话数
+func RevokeOtherSessions(userID uint, currentSessionID string) (int64, *models.ServiceError) {
+	count, err := revokeSessions(userID, currentSessionID)
+	if err != nil {
+		return 0, &models.ServiceError{Code: 1001, Message: "退出其他设备失败: " + err.Error()}
+	}
+	return count, nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"CMS/pkg/redis"
+	"CMS/pkg/totp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Redis 键名定义
+const (
+	twoFactorSetupKey = "2fa:setup:" // 待确认的两步验证密钥：string类型，确认后写入数据库
+	twoFactorLoginKey = "2fa:login:" // 两步登录的中间凭证：hash类型（user_id、attempts）
+
+	twoFactorSetupTTL      = 10 * time.Minute
+	twoFactorLoginTTL      = 5 * time.Minute
+	maxTwoFactorAttempts   = 5  // 同一中间凭证最多尝试的验证码次数
+	recoveryCodeCount      = 10 // 每次生成的恢复码数量
+	recoveryCodeBytes      = 5  // 每个恢复码的随机字节数，编码为10位十六进制
+	twoFactorLoginTokenLen = 24
+)
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// normalizeRecoveryCode 恢复码忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// replaceRecoveryCodes 在事务中作废旧恢复码并生成新的一组，返回明文（只展示一次）
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomHex(recoveryCodeBytes)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: sha256Hex(raw)})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func writeTwoFactorAudit(tx *gorm.DB, userID uint, action string) error {
+	// 用户自行操作时 AdminID 记录为本人
+	return tx.Create(&models.AuditLog{
+		AdminID:  userID,
+		Action:   action,
+		TargetID: userID,
+		Detail:   `{"by": "self"}`,
+	}).Error
+}
+
+// verifySecondFactor 校验验证码或恢复码：6位数字按 TOTP 校验并拒绝重放，其余按恢复码校验，恢复码只能使用一次
+func verifySecondFactor(user *models.User, code string) *models.ServiceError {
+	invalid := &models.ServiceError{Code: 1201, Message: "验证码错误"}
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !ok || step <= user.TOTPLastStep {
+			return invalid
+		}
+		// 条件更新保证同一时间步的验证码只能使用一次
+		result := database.DB.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return invalid
+		}
+		return nil
+	}
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, sha256Hex(normalizeRecoveryCode(code))).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return invalid
+		}
+		return writeTwoFactorAudit(tx, user.ID, "use_recovery_code")
+	})
+	if err != nil {
+		return invalid
+	}
+	return nil
+}
+
+// GetTwoFactorStatus 获取两步验证状态及剩余可用的恢复码数量
+func GetTwoFactorStatus(userID uint) (bool, int64, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return false, 0, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+	var remaining int64
+	if user.TOTPEnabled {
+		database.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining)
+	}
+	return user.TOTPEnabled, remaining, nil
+}
+
+// SetupTwoFactor 生成两步验证密钥，需在有效期内用验证器生成的验证码确认后才会启用
+func SetupTwoFactor(userID uint) (*models.TwoFactorSetup, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+	if user.TOTPEnabled {
+		return nil, &models.ServiceError{Code: 1002, Message: "已启用两步验证"}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "生成密钥失败"}
+	}
+	key := twoFactorSetupKey + strconv.Itoa(int(userID))
+	if err := redis.RedisClient.Set(context.Background(), key, secret, twoFactorSetupTTL).Err(); err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "生成密钥失败: " + err.Error()}
+	}
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(config.LoadedConfig.TwoFactor.Issuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTwoFactor 用验证码确认密钥并启用两步验证，返回恢复码（只展示一次）
+func EnableTwoFactor(userID uint, code string) ([]string, *models.ServiceError) {
+	ctx := context.Background()
+	key := twoFactorSetupKey + strconv.Itoa(int(userID))
+	secret, err := redis.RedisClient.Get(ctx, key).Result()
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1001, Message: "请先获取两步验证密钥"}
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, &models.ServiceError{Code: 1201, Message: "验证码错误"}
+	}
+
+	var codes []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_enabled = ?", userID, false).
+			Updates(map[string]interface{}{
+				"totp_secret":    secret,
+				"totp_enabled":   true,
+				"totp_last_step": step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &models.ServiceError{Code: 1002, Message: "已启用两步验证"}
+		}
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, userID); err != nil {
+			return err
+		}
+		return writeTwoFactorAudit(tx, userID, "enable_2fa")
+	})
+	if err != nil {
+		if serviceErr, ok := err.(*models.ServiceError); ok {
+			return nil, serviceErr
+		}
+		return nil, &models.ServiceError{Code: 1003, Message: "启用两步验证失败: " + err.Error()}
+	}
+	redis.RedisClient.Del(ctx, key)
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需同时提供密码和验证码（或恢复码）；强制管理员两步验证时管理员不能关闭
+func DisableTwoFactor(userID uint, password, code string) *models.ServiceError {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+	if !user.TOTPEnabled {
+		return &models.ServiceError{Code: 1002, Message: "未启用两步验证"}
+	}
+	if user.UserType == models.AdminRole && config.LoadedConfig.TwoFactor.RequiredForAdmin {
+		return &models.ServiceError{Code: 1003, Message: "管理员必须启用两步验证"}
+	}
+	if !user.CheckPasswordHash(password) {
+		return &models.ServiceError{Code: 1004, Message: "密码错误"}
+	}
+	if serviceErr := verifySecondFactor(user, code); serviceErr != nil {
+		return serviceErr
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return writeTwoFactorAudit(tx, userID, "disable_2fa")
+	})
+	if err != nil {
+		return &models.ServiceError{Code: 1005, Message: "关闭两步验证失败: " + err.Error()}
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodes(userID uint, code string) ([]string, *models.ServiceError) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1001, Message: "用户不存在"}
+	}
+	if !user.TOTPEnabled {
+		return nil, &models.ServiceError{Code: 1002, Message: "未启用两步验证"}
+	}
+	if serviceErr := verifySecondFactor(user, code); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	var codes []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if codes, err = replaceRecoveryCodes(tx, userID); err != nil {
+			return err
+		}
+		return writeTwoFactorAudit(tx, userID, "regenerate_recovery_codes")
+	})
+	if err != nil {
+		return nil, &models.ServiceError{Code: 1003, Message: "生成恢复码失败: " + err.Error()}
+	}
+	return codes, nil
+}
+
+// BeginTwoFactorLogin 密码校验通过后签发两步登录的中间凭证
+func BeginTwoFactorLogin(userID uint) (string, error) {
+	token, err := randomHex(twoFactorLoginTokenLen)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	key := twoFactorLoginKey + token
+	pipe := redis.RedisClient.TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "attempts", 0)
+	pipe.Expire(ctx, key, twoFactorLoginTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetTwoFactorLoginUser 查询中间凭证对应的账号，不消耗验证次数，用于按账号限制和统计两步验证失败
+func GetTwoFactorLoginUser(token string) (*models.User, *models.ServiceError) {
+	invalidToken := &models.ServiceError{Code: 1202, Message: "登录已过期，请重新输入密码"}
+	userIDStr, err := redis.RedisClient.HGet(context.Background(), twoFactorLoginKey+token, "user_id").Result()
+	if err != nil {
+		return nil, invalidToken
+	}
+	userID, _ := strconv.Atoi(userIDStr)
+	user, err := GetUserByID(uint(userID))
+	if err != nil {
+		return nil, invalidToken
+	}
+	return user, nil
+}
+
+// CompleteTwoFactorLogin 用中间凭证和验证码（或恢复码）完成登录，凭证只能成功使用一次，
+// 错误次数过多时凭证作废，需重新输入密码
+func CompleteTwoFactorLogin(token, code string) (*models.User, *models.ServiceError) {
+	invalidToken := &models.ServiceError{Code: 1202, Message: "登录已过期，请重新输入密码"}
+	ctx := context.Background()
+	key := twoFactorLoginKey + token
+
+	userIDStr, err := redis.RedisClient.HGet(ctx, key, "user_id").Result()
+	if err != nil {
+		return nil, invalidToken
+	}
+	attempts, err := redis.RedisClient.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil || attempts > maxTwoFactorAttempts {
+		redis.RedisClient.Del(ctx, key)
+		return nil, invalidToken
+	}
+
+	userID, _ := strconv.Atoi(userIDStr)
+	user, err := GetUserByID(uint(userID))
+	if err != nil || !user.TOTPEnabled {
+		redis.RedisClient.Del(ctx, key)
+		return nil, invalidToken
+	}
+	if serviceErr := verifySecondFactor(user, code); serviceErr != nil {
+		return nil, serviceErr
+	}
+
+	// 删除成功才算登录成功，避免并发请求重复使用同一凭证
+	if deleted, err := redis.RedisClient.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return nil, invalidToken
+	}
+	logger.GetLogger().Infof("两步验证通过: user_id=%d", user.ID)
+	return user, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"testing"
+)
+
+func TestGetTwoFactorLoginUser(t *testing.T) {
+	setupTestConfig(t)
+	setupTestRedis(t)
+	setupTestDB(t, &models.User{}, &models.RecoveryCode{}, &models.AuditLog{})
+
+	user := models.User{Username: "2023001", Password: "x", UserType: models.StudentRole, TOTPSecret: "JBSWY3DPEHPK3PXP", TOTPEnabled: true}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	token, err := BeginTwoFactorLogin(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 查询账号不消耗验证次数
+	for i := 0; i < maxTwoFactorAttempts+1; i++ {
+		pending, serviceErr := GetTwoFactorLoginUser(token)
+		if serviceErr != nil || pending.ID != user.ID || pending.Username != user.Username {
+			t.Fatalf("应返回凭证对应的账号: %+v, %v", pending, serviceErr)
+		}
+	}
+	if _, serviceErr := CompleteTwoFactorLogin(token, "000000"); serviceErr == nil || serviceErr.Code != 1201 {
+		t.Fatalf("凭证仍有效时应返回验证码错误: %v", serviceErr)
+	}
+
+	if _, serviceErr := GetTwoFactorLoginUser("unknown"); serviceErr == nil || serviceErr.Code != 1202 {
+		t.Fatalf("无效凭证应返回1202: %v", serviceErr)
+	}
+}
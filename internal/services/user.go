@@ -4,6 +4,10 @@ import (
 	"CMS/internal/models"
 	"CMS/internal/pkg/database"
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -14,6 +18,31 @@ func GetUserByUsername(username string) (user *models.User, err error) {
 	return
 }
 
+const (
+	maxUsernameLen = 20 // 与 users.username 列长度一致
+	maxNameLen     = 50 // 与 users.name 列长度一致
+)
+
+var usernamePattern = regexp.MustCompile(`^\d+$`)
+
+// ValidateNewUser 校验注册账号的基本信息：账号只能是数字，姓名不能为空，用户类型只能是学生或管理员
+func ValidateNewUser(username, name string, userType int) *models.ServiceError {
+	if !usernamePattern.MatchString(username) {
+		return &models.ServiceError{Code: 1002, Message: "账号必须为数字"}
+	}
+	if len(username) > maxUsernameLen {
+		return &models.ServiceError{Code: 1002, Message: fmt.Sprintf("账号不能超过%d位", maxUsernameLen)}
+	}
+	if userType != models.StudentRole && userType != models.AdminRole {
+		return &models.ServiceError{Code: 1004, Message: "用户类型错误"}
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxNameLen {
+		return &models.ServiceError{Code: 1007, Message: fmt.Sprintf("姓名不能为空且不能超过%d个字符", maxNameLen)}
+	}
+	return nil
+}
+
 func RegisterUser(user *models.User) error {
 	hashedpassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -25,13 +54,21 @@ func RegisterUser(user *models.User) error {
 	return result.Error
 }
 
+// ErrInvalidPassword 密码错误
+var ErrInvalidPassword = errors.New("invalid password")
+
+// dummyPasswordHash 账号不存在时用于比对的哈希，使响应耗时与密码错误时一致，避免据此探测账号是否存在
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
 func CheckLogin(username, password string) (*models.User, error) {
 	user, err := GetUserByUsername(username)
 	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
 		return nil, err
 	}
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
-		return nil, errors.New("invalid password")
+	// 服务账号和已注销的账号不能通过密码登录
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil || user.IsServiceAccount || user.IsClosed() {
+		return nil, ErrInvalidPassword
 	}
 	return user, nil
 }
@@ -58,10 +95,14 @@ func CheckUserIsAdmin(userID uint) (bool, *models.ServiceError) {
 
 // AdminReportItem 管理员查看举报列表的响应项
 type AdminReportItem struct {
-	Username string `json:"username"`
-	Content  string `json:"content"`
-	Reason   string `json:"reason"`
-	PostID   uint   `json:"post_id"`
+	Username           string  `json:"username"`
+	Content            string  `json:"content"`
+	Reason             string  `json:"reason"`
+	TargetType         int     `json:"target_type"` // 0-帖子, 1-私信
+	PostID             uint    `json:"post_id,omitempty"`
+	MessageID          uint    `json:"message_id,omitempty"`
+	ReporterReputation int     `json:"reporter_reputation"` // 举报人声望
+	Weight             float64 `json:"weight"`              // 同一对象所有待审举报的权重之和
 }
 
 // GetPendingReportsForAdmin 获取管理员待审批的举报列表
@@ -85,25 +126,59 @@ func GetPendingReportsForAdmin() ([]AdminReportItem, *models.ServiceError) {
 			continue
 		}
 
+		if block.TargetType == models.BlockTargetMessage {
+			item := AdminReportItem{
+				Username:           user.Username,
+				Reason:             block.Reason,
+				TargetType:         block.TargetType,
+				MessageID:          block.TargetID,
+				ReporterReputation: user.Reputation,
+			}
+			if message, err := GetMessageByID(block.TargetID); err != nil {
+				item.Content = "私信已被删除"
+			} else {
+				item.Content = message.Content
+			}
+			reportItems = append(reportItems, item)
+			continue
+		}
+
 		// 获取被举报的帖子
 		post, err := GetPostByID(block.TargetID)
 		if err != nil {
 			// 如果帖子不存在，使用默认内容
 			reportItems = append(reportItems, AdminReportItem{
-				Username: user.Username,
-				Content:  "帖子已被删除",
-				Reason:   block.Reason,
-				PostID:   block.TargetID,
+				Username:           user.Username,
+				Content:            "帖子已被删除",
+				Reason:             block.Reason,
+				PostID:             block.TargetID,
+				ReporterReputation: user.Reputation,
 			})
 		} else {
 			reportItems = append(reportItems, AdminReportItem{
-				Username: user.Username,
-				Content:  post.Content,
-				Reason:   block.Reason,
-				PostID:   post.ID,
+				Username:           user.Username,
+				Content:            post.Content,
+				Reason:             block.Reason,
+				PostID:             post.ID,
+				ReporterReputation: user.Reputation,
 			})
 		}
 	}
 
+	// 按举报人声望加权，同一对象被多名高声望用户举报时优先处理
+	weights := make(map[[2]uint]float64)
+	targetOf := func(item AdminReportItem) [2]uint {
+		return [2]uint{uint(item.TargetType), item.PostID + item.MessageID}
+	}
+	for _, item := range reportItems {
+		weights[targetOf(item)] += ReportWeight(item.ReporterReputation)
+	}
+	for i := range reportItems {
+		reportItems[i].Weight = weights[targetOf(reportItems[i])]
+	}
+	sort.SliceStable(reportItems, func(i, j int) bool {
+		return reportItems[i].Weight > reportItems[j].Weight
+	})
+
 	return reportItems, nil
 }
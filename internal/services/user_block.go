@@ -0,0 +1,59 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+)
+
+// IsUserBlocked 判断 userID 是否屏蔽了 targetID
+func IsUserBlocked(userID, targetID uint) bool {
+	var count int64
+	database.DB.Model(&models.UserBlock{}).
+		Where("user_id = ? AND blocked_user_id = ?", userID, targetID).
+		Count(&count)
+	return count > 0
+}
+
+// BlockUser 屏蔽用户，被屏蔽的用户无法再给自己发私信
+func BlockUser(userID, targetID uint) *models.ServiceError {
+	if userID == targetID {
+		return &models.ServiceError{Code: 1001, Message: "不能屏蔽自己"}
+	}
+	if _, err := GetUserByID(targetID); err != nil {
+		return &models.ServiceError{Code: 1002, Message: "用户不存在"}
+	}
+	err := database.DB.Where(models.UserBlock{UserID: userID, BlockedUserID: targetID}).
+		FirstOrCreate(&models.UserBlock{}).Error
+	if err != nil {
+		return &models.ServiceError{Code: 1003, Message: "屏蔽失败: " + err.Error()}
+	}
+	return nil
+}
+
+// UnblockUser 取消屏蔽用户
+func UnblockUser(userID, targetID uint) error {
+	return database.DB.Where("user_id = ? AND blocked_user_id = ?", userID, targetID).
+		Delete(&models.UserBlock{}).Error
+}
+
+// GetBlockedUsers 获取用户的屏蔽列表
+func GetBlockedUsers(userID uint) ([]map[string]interface{}, error) {
+	var blocks []models.UserBlock
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]map[string]interface{}, 0, len(blocks))
+	for _, b := range blocks {
+		item := map[string]interface{}{
+			"user_id": b.BlockedUserID,
+			"time":    b.CreatedAt.Format("2006-01-02T15:04:05.000-07:00"),
+		}
+		if user, err := GetUserByID(b.BlockedUserID); err == nil {
+			item["username"] = user.Username
+			item["name"] = user.Name
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"CMS/config"
+	"CMS/internal/logger"
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	webhookBatchSize  = 50              // 每轮处理的投递条数
+	webhookLease      = 2 * time.Minute // 投递租约，防止多个实例重复投递同一条
+	webhookMaxBackoff = 6 * time.Hour   // 退避间隔上限
+	webhookErrorLimit = 1000            // 记录的错误信息长度上限
+	webhookUserAgent  = "CMS-Webhook/1.0"
+)
+
+// WebhookPayload 投递给订阅方的请求体
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// validateWebhook 校验 URL 和事件类型，返回规范化后的事件字符串
+func validateWebhook(rawURL string, events []string) (string, *models.ServiceError) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", &models.ServiceError{Code: 1001, Message: "无效的URL"}
+	}
+	if len(events) == 0 {
+		return "", &models.ServiceError{Code: 1002, Message: "至少订阅一个事件"}
+	}
+	for _, event := range events {
+		valid := false
+		for _, e := range models.WebhookEvents {
+			if e == event {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", &models.ServiceError{Code: 1003, Message: "无效的事件类型: " + event}
+		}
+	}
+	return strings.Join(events, ","), nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateWebhook 创建 Webhook 订阅，未指定 secret 时自动生成，secret 只在创建时返回一次
+func CreateWebhook(adminID uint, rawURL string, events []string, secret string) (*models.Webhook, *models.ServiceError) {
+	eventStr, serviceErr := validateWebhook(rawURL, events)
+	if serviceErr != nil {
+		return nil, serviceErr
+	}
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, &models.ServiceError{Code: 1004, Message: "生成密钥失败"}
+		}
+	}
+
+	webhook := models.Webhook{
+		URL:       rawURL,
+		Events:    eventStr,
+		Secret:    secret,
+		Enabled:   true,
+		CreatedBy: adminID,
+	}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		return nil, &models.ServiceError{Code: 1005, Message: "创建Webhook失败: " + err.Error()}
+	}
+	return &webhook, nil
+}
+
+// GetWebhooks 获取所有 Webhook 订阅
+func GetWebhooks() ([]models.WebhookResponse, error) {
+	var webhooks []models.Webhook
+	if err := database.DB.Order("id DESC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	responses := make([]models.WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		responses = append(responses, w.ToResponse())
+	}
+	return responses, nil
+}
+
+// UpdateWebhook 修改 Webhook 的 URL、事件和启用状态，secret 为空时保持不变
+func UpdateWebhook(id uint, rawURL string, events []string, secret string, enabled bool) *models.ServiceError {
+	eventStr, serviceErr := validateWebhook(rawURL, events)
+	if serviceErr != nil {
+		return serviceErr
+	}
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, id).Error; err != nil {
+		return &models.ServiceError{Code: 1006, Message: "Webhook不存在"}
+	}
+	updates := map[string]interface{}{
+		"url":     rawURL,
+		"events":  eventStr,
+		"enabled": enabled,
+	}
+	if secret != "" {
+		updates["secret"] = secret
+	}
+	if err := database.DB.Model(&webhook).Updates(updates).Error; err != nil {
+		return &models.ServiceError{Code: 1005, Message: "修改Webhook失败: " + err.Error()}
+	}
+	return nil
+}
+
+// DeleteWebhook 删除 Webhook 订阅及其未完成的投递
+func DeleteWebhook(id uint) *models.ServiceError {
+	result := database.DB.Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1005, Message: "删除Webhook失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1006, Message: "Webhook不存在"}
+	}
+	database.DB.Where("webhook_id = ? AND status = ?", id, models.WebhookDeliveryPending).Delete(&models.WebhookDelivery{})
+	return nil
+}
+
+// EmitWebhookEvent 为订阅了该事件的 Webhook 写入待投递记录，实际投递由后台任务完成
+func EmitWebhookEvent(event string, data interface{}) {
+	var webhooks []models.Webhook
+	if err := database.DB.Where("enabled = ? AND events LIKE ?", true, "%"+event+"%").Find(&webhooks).Error; err != nil {
+		logger.GetLogger().Errorf("查询Webhook订阅失败: event=%s, err=%v", event, err)
+		return
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		Event:     event,
+		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000-07:00"),
+		Data:      data,
+	})
+	if err != nil {
+		logger.GetLogger().Errorf("序列化Webhook事件失败: event=%s, err=%v", event, err)
+		return
+	}
+
+	for _, w := range webhooks {
+		if !w.Subscribes(event) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     w.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := database.DB.Create(&delivery).Error; err != nil {
+			logger.GetLogger().Errorf("写入Webhook投递记录失败: webhook_id=%d, event=%s, err=%v", w.ID, event, err)
+		}
+	}
+}
+
+// SignWebhookPayload 计算签名：HMAC-SHA256(secret, timestamp + "." + body)
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff 第 attempts 次失败后的重试间隔：base * 2^(attempts-1)，不超过上限
+func webhookBackoff(attempts int) time.Duration {
+	delay := time.Duration(config.LoadedConfig.Webhook.BaseDelaySeconds) * time.Second
+	for i := 1; i < attempts && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxBackoff {
+		delay = webhookMaxBackoff
+	}
+	return delay
+}
+
+// ProcessWebhookDeliveries 投递到期的 Webhook，由定时任务调用
+func ProcessWebhookDeliveries() {
+	// 已停用的 Webhook 暂停投递，待投递记录保留到重新启用
+	enabled := database.DB.Model(&models.Webhook{}).Select("id").Where("enabled = ?", true)
+	var deliveries []models.WebhookDelivery
+	err := database.DB.Where("status = ? AND next_attempt_at <= ? AND webhook_id IN (?)", models.WebhookDeliveryPending, time.Now(), enabled).
+		Order("next_attempt_at").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error
+	if err != nil {
+		logger.GetLogger().Errorf("查询待投递Webhook失败: %v", err)
+		return
+	}
+
+	client := &http.Client{Timeout: time.Duration(config.LoadedConfig.Webhook.TimeoutSeconds) * time.Second}
+	for _, delivery := range deliveries {
+		// 抢占租约：只有成功把 next_attempt_at 推后的实例才进行投递
+		result := database.DB.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.WebhookDeliveryPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", time.Now().Add(webhookLease))
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		deliverWebhook(client, delivery)
+	}
+}
+
+func deliverWebhook(client *http.Client, delivery models.WebhookDelivery) {
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+		database.DB.Model(&delivery).Updates(map[string]interface{}{
+			"status":     models.WebhookDeliveryDead,
+			"last_error": "Webhook不存在",
+		})
+		return
+	}
+	if !webhook.Enabled {
+		// 查询后才被停用：撤回租约，不计入尝试次数
+		database.DB.Model(&delivery).Update("next_attempt_at", delivery.NextAttemptAt)
+		return
+	}
+
+	statusCode, sendErr := sendWebhook(client, webhook, delivery)
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliverySuccess
+	case attempts >= config.LoadedConfig.Webhook.MaxAttempts:
+		updates["status"] = models.WebhookDeliveryDead
+		updates["last_error"] = truncateError(sendErr.Error())
+		logger.GetLogger().Errorf("Webhook投递失败，进入死信: delivery_id=%d, webhook_id=%d, err=%v", delivery.ID, webhook.ID, sendErr)
+	default:
+		updates["next_attempt_at"] = time.Now().Add(webhookBackoff(attempts))
+		updates["last_error"] = truncateError(sendErr.Error())
+	}
+
+	if err := database.DB.Model(&delivery).Updates(updates).Error; err != nil {
+		logger.GetLogger().Errorf("更新Webhook投递状态失败: delivery_id=%d, err=%v", delivery.ID, err)
+	}
+}
+
+// sendWebhook 发送一次带签名的请求，2xx 视为成功
+func sendWebhook(client *http.Client, webhook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	if !webhook.Enabled {
+		return 0, fmt.Errorf("Webhook已停用")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set("X-CMS-Event", delivery.Event)
+	req.Header.Set("X-CMS-Delivery", strconv.Itoa(int(delivery.ID)))
+	req.Header.Set("X-CMS-Timestamp", timestamp)
+	req.Header.Set("X-CMS-Signature", SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("订阅方返回状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncateError(msg string) string {
+	if len(msg) > webhookErrorLimit {
+		return msg[:webhookErrorLimit]
+	}
+	return msg
+}
+
+// GetDeadWebhookDeliveries 分页获取死信投递
+func GetDeadWebhookDeliveries(page, pageSize int) ([]models.WebhookDeliveryResponse, int64, error) {
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("status = ?", models.WebhookDeliveryDead)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		responses = append(responses, d.ToResponse())
+	}
+	return responses, total, nil
+}
+
+// ReplayWebhookDelivery 将死信投递重新放回队列，重置重试次数
+func ReplayWebhookDelivery(id uint) *models.ServiceError {
+	result := database.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, models.WebhookDeliveryDead).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return &models.ServiceError{Code: 1001, Message: "重放投递失败: " + result.Error.Error()}
+	}
+	if result.RowsAffected == 0 {
+		return &models.ServiceError{Code: 1002, Message: "死信投递不存在"}
+	}
+	return nil
+}
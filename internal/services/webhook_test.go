@@ -0,0 +1,325 @@
+package services
+
+import (
+	"CMS/internal/models"
+	"CMS/internal/pkg/database"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver 记录收到的请求，并按 status 返回状态码
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver(t *testing.T, status int) (*webhookReceiver, *httptest.Server) {
+	t.Helper()
+	r := &webhookReceiver{status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		status := r.status
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func setupWebhookTest(t *testing.T) {
+	t.Helper()
+	setupTestConfig(t)
+	setupTestDB(t, &models.Webhook{}, &models.WebhookDelivery{})
+}
+
+func createTestDelivery(t *testing.T, url string, enabled bool) (models.Webhook, models.WebhookDelivery) {
+	t.Helper()
+	webhook := models.Webhook{URL: url, Events: models.WebhookEventPostCreated, Secret: "s3cret", Enabled: true}
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !enabled {
+		database.DB.Model(&webhook).Update("enabled", false)
+		webhook.Enabled = false
+	}
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         models.WebhookEventPostCreated,
+		Payload:       `{"event":"post.created","data":{"id":1}}`,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return webhook, delivery
+}
+
+func reloadDelivery(t *testing.T, id uint) models.WebhookDelivery {
+	t.Helper()
+	var d models.WebhookDelivery
+	if err := database.DB.First(&d, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// makeDue 将投递的下次尝试时间提前，模拟退避时间已到
+func makeDue(t *testing.T, id uint) {
+	t.Helper()
+	database.DB.Model(&models.WebhookDelivery{}).Where("id = ?", id).Update("next_attempt_at", time.Now().Add(-time.Second))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	got := SignWebhookPayload("key", "1700000000", []byte(`{"a":1}`))
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac key
+	want := "sha256=a438e398bfafc57e4396bb7fc2304422f0f768e965d073ca313cb52e22e6ad03"
+	if got != want {
+		t.Fatalf("签名错误: got %s, want %s", got, want)
+	}
+	if got != SignWebhookPayload("key", "1700000000", []byte(`{"a":1}`)) {
+		t.Fatal("相同输入的签名应一致")
+	}
+	if got == SignWebhookPayload("key", "1700000001", []byte(`{"a":1}`)) {
+		t.Fatal("时间戳参与签名")
+	}
+	if got == SignWebhookPayload("other", "1700000000", []byte(`{"a":1}`)) {
+		t.Fatal("密钥参与签名")
+	}
+}
+
+func TestSendWebhookHeadersAndSignature(t *testing.T) {
+	setupWebhookTest(t)
+	receiver, srv := newWebhookReceiver(t, http.StatusNoContent)
+	webhook, delivery := createTestDelivery(t, srv.URL, true)
+
+	status, err := sendWebhook(srv.Client(), webhook, delivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("投递失败: status=%d, err=%v", status, err)
+	}
+	if receiver.count() != 1 {
+		t.Fatalf("应收到1个请求，实际 %d", receiver.count())
+	}
+	req, body := receiver.requests[0], receiver.bodies[0]
+	if req.Method != http.MethodPost {
+		t.Errorf("方法应为 POST，实际 %s", req.Method)
+	}
+	if string(body) != delivery.Payload {
+		t.Errorf("请求体不一致: %s", body)
+	}
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		"User-Agent":     webhookUserAgent,
+		"X-CMS-Event":    models.WebhookEventPostCreated,
+		"X-CMS-Delivery": strconv.Itoa(int(delivery.ID)),
+	}
+	for name, want := range headers {
+		if got := req.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	timestamp := req.Header.Get("X-CMS-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Fatalf("时间戳无效: %q", timestamp)
+	}
+	// 订阅方按 timestamp + "." + body 验签
+	if got, want := req.Header.Get("X-CMS-Signature"), SignWebhookPayload(webhook.Secret, timestamp, body); got != want {
+		t.Errorf("签名不匹配: got %s, want %s", got, want)
+	}
+}
+
+func TestSendWebhookNon2xxIsError(t *testing.T) {
+	setupWebhookTest(t)
+	_, srv := newWebhookReceiver(t, http.StatusInternalServerError)
+	webhook, delivery := createTestDelivery(t, srv.URL, true)
+
+	status, err := sendWebhook(srv.Client(), webhook, delivery)
+	if err == nil || status != http.StatusInternalServerError {
+		t.Fatalf("非2xx应视为失败: status=%d, err=%v", status, err)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	setupTestConfig(t).Webhook.BaseDelaySeconds = 30
+
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, webhookMaxBackoff},
+		{100, webhookMaxBackoff},
+	}
+	prev := time.Duration(0)
+	for _, c := range cases {
+		got := webhookBackoff(c.attempts)
+		if got != c.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", c.attempts, got, c.want)
+		}
+		if got < prev {
+			t.Errorf("退避间隔不应减小: attempts=%d", c.attempts)
+		}
+		prev = got
+	}
+}
+
+func TestDeliverySuccess(t *testing.T) {
+	setupWebhookTest(t)
+	receiver, srv := newWebhookReceiver(t, http.StatusOK)
+	_, delivery := createTestDelivery(t, srv.URL, true)
+
+	ProcessWebhookDeliveries()
+
+	d := reloadDelivery(t, delivery.ID)
+	if d.Status != models.WebhookDeliverySuccess || d.Attempts != 1 || d.LastStatusCode != http.StatusOK {
+		t.Fatalf("投递状态错误: %+v", d)
+	}
+	if receiver.count() != 1 {
+		t.Fatalf("应收到1个请求，实际 %d", receiver.count())
+	}
+}
+
+func TestDeliveryRetriesThenDeadLetter(t *testing.T) {
+	cfg := setupTestConfig(t)
+	setupTestDB(t, &models.Webhook{}, &models.WebhookDelivery{})
+	cfg.Webhook.MaxAttempts = 3
+	receiver, srv := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	_, delivery := createTestDelivery(t, srv.URL, true)
+
+	for attempt := 1; attempt < cfg.Webhook.MaxAttempts; attempt++ {
+		before := time.Now()
+		ProcessWebhookDeliveries()
+		d := reloadDelivery(t, delivery.ID)
+		if d.Status != models.WebhookDeliveryPending || d.Attempts != attempt {
+			t.Fatalf("第%d次失败后应等待重试: %+v", attempt, d)
+		}
+		if d.LastStatusCode != http.StatusServiceUnavailable || d.LastError == "" {
+			t.Fatalf("应记录失败原因: %+v", d)
+		}
+		if d.NextAttemptAt.Before(before.Add(webhookBackoff(attempt) - time.Second)) {
+			t.Fatalf("下次尝试时间应按退避推后: %v", d.NextAttemptAt)
+		}
+
+		// 退避时间未到时不会再次投递
+		ProcessWebhookDeliveries()
+		if receiver.count() != attempt {
+			t.Fatalf("退避期间不应投递: 已收到 %d 个请求", receiver.count())
+		}
+		makeDue(t, delivery.ID)
+	}
+
+	ProcessWebhookDeliveries()
+	d := reloadDelivery(t, delivery.ID)
+	if d.Status != models.WebhookDeliveryDead || d.Attempts != cfg.Webhook.MaxAttempts {
+		t.Fatalf("达到最大次数后应进入死信: %+v", d)
+	}
+
+	dead, total, err := GetDeadWebhookDeliveries(1, 20)
+	if err != nil || total != 1 || len(dead) != 1 || dead[0].ID != delivery.ID {
+		t.Fatalf("死信列表错误: total=%d, list=%+v, err=%v", total, dead, err)
+	}
+
+	// 死信不再自动投递
+	makeDue(t, delivery.ID)
+	ProcessWebhookDeliveries()
+	if receiver.count() != cfg.Webhook.MaxAttempts {
+		t.Fatalf("死信不应再投递: 已收到 %d 个请求", receiver.count())
+	}
+}
+
+func TestReplayDeadDelivery(t *testing.T) {
+	cfg := setupTestConfig(t)
+	setupTestDB(t, &models.Webhook{}, &models.WebhookDelivery{})
+	cfg.Webhook.MaxAttempts = 1
+	receiver, srv := newWebhookReceiver(t, http.StatusBadGateway)
+	_, delivery := createTestDelivery(t, srv.URL, true)
+
+	ProcessWebhookDeliveries()
+	if d := reloadDelivery(t, delivery.ID); d.Status != models.WebhookDeliveryDead {
+		t.Fatalf("应进入死信: %+v", d)
+	}
+
+	// 只有死信可以重放
+	if err := ReplayWebhookDelivery(delivery.ID + 100); err == nil || err.Code != 1002 {
+		t.Fatalf("不存在的死信应返回错误: %v", err)
+	}
+
+	receiver.mu.Lock()
+	receiver.status = http.StatusOK
+	receiver.mu.Unlock()
+
+	if err := ReplayWebhookDelivery(delivery.ID); err != nil {
+		t.Fatalf("重放失败: %v", err)
+	}
+	d := reloadDelivery(t, delivery.ID)
+	if d.Status != models.WebhookDeliveryPending || d.Attempts != 0 {
+		t.Fatalf("重放后应重置为待投递: %+v", d)
+	}
+	if err := ReplayWebhookDelivery(delivery.ID); err == nil {
+		t.Fatal("待投递的记录不能重复重放")
+	}
+
+	ProcessWebhookDeliveries()
+	d = reloadDelivery(t, delivery.ID)
+	if d.Status != models.WebhookDeliverySuccess || d.Attempts != 1 {
+		t.Fatalf("重放后应投递成功: %+v", d)
+	}
+	if receiver.count() != 2 {
+		t.Fatalf("应共收到2个请求，实际 %d", receiver.count())
+	}
+}
+
+func TestDisabledWebhookDeliveryIsParked(t *testing.T) {
+	setupWebhookTest(t)
+	receiver, srv := newWebhookReceiver(t, http.StatusOK)
+	webhook, delivery := createTestDelivery(t, srv.URL, false)
+
+	// 停用期间多次轮询都不投递，也不计入尝试次数
+	for i := 0; i < 5; i++ {
+		ProcessWebhookDeliveries()
+	}
+	d := reloadDelivery(t, delivery.ID)
+	if d.Status != models.WebhookDeliveryPending || d.Attempts != 0 || d.LastError != "" {
+		t.Fatalf("停用的Webhook投递应暂停: %+v", d)
+	}
+	if receiver.count() != 0 {
+		t.Fatalf("停用期间不应投递: 已收到 %d 个请求", receiver.count())
+	}
+
+	// 查询后才被停用时撤回租约
+	deliverWebhook(srv.Client(), d)
+	if d2 := reloadDelivery(t, delivery.ID); d2.Attempts != 0 || !d2.NextAttemptAt.Equal(d.NextAttemptAt) {
+		t.Fatalf("停用的Webhook不应计入尝试次数: %+v", d2)
+	}
+
+	// 重新启用后继续投递
+	database.DB.Model(&webhook).Update("enabled", true)
+	ProcessWebhookDeliveries()
+	if d := reloadDelivery(t, delivery.ID); d.Status != models.WebhookDeliverySuccess || d.Attempts != 1 {
+		t.Fatalf("重新启用后应投递成功: %+v", d)
+	}
+	if receiver.count() != 1 {
+		t.Fatalf("应收到1个请求，实际 %d", receiver.count())
+	}
+}
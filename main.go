@@ -3,10 +3,12 @@ package main
 
 import (
 	"CMS/config"
+	"CMS/internal/gateway"
 	"CMS/internal/pkg/database"
 	"CMS/internal/router"
 	"CMS/internal/services"
 	"CMS/pkg/redis"
+	"CMS/pkg/storage"
 	"log"
 	"strconv"
 	"time"
@@ -22,13 +24,29 @@ func main() {
 	if err != nil {
 		log.Fatal("加载配置失败:", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("配置校验失败:", err)
+	}
 	config.LoadedConfig = cfg // 注入全局配置（需在config/config.go中添加LoadedConfig变量）
 
 	database.Init()
-	redis.Init() // 初始化Redis
+	redis.Init()   // 初始化Redis
+	storage.Init() // 初始化文件存储
 
 	// 启动定时同步任务
 	go startLikeSyncTask()
+	// 订阅实时事件频道（跨实例推送），网关监听需先注册
+	gateway.Init()
+	go services.StartEventSubscriber()
+	go gateway.StartSubscriber()
+	// 启动Webhook投递任务
+	go startWebhookDeliveryTask()
+	// 启动定时发布任务
+	go startScheduledPublishTask()
+	// 启动孤立附件清理任务
+	go startAttachmentCleanupTask()
+	// 启动个人数据导出任务
+	go startDataExportTask()
 
 	r := gin.Default()
 	router.Init(r)
@@ -51,3 +69,43 @@ func startLikeSyncTask() {
 		services.SyncDiffLikesToRedis()
 	}
 }
+
+// startWebhookDeliveryTask 启动Webhook投递任务，每10秒处理一次到期的投递
+func startWebhookDeliveryTask() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		services.ProcessWebhookDeliveries()
+	}
+}
+
+// startScheduledPublishTask 启动定时发布任务，每15秒发布一次到期的帖子
+func startScheduledPublishTask() {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		services.PublishDuePosts()
+	}
+}
+
+// startAttachmentCleanupTask 启动孤立附件清理任务，每小时清理一次
+func startAttachmentCleanupTask() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		services.CleanupOrphanAttachments()
+	}
+}
+
+// startDataExportTask 启动个人数据导出任务，每30秒生成一次排队中的导出文件并清理过期文件
+func startDataExportTask() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		services.ProcessDataExports()
+	}
+}
@@ -0,0 +1,237 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxMentionLength = 32 // @提及的最大字符数
+	maxTagLength     = 32 // #话题的最大字符数
+	maxInlineDepth   = 8  // 粗体/斜体等嵌套的最大层数
+)
+
+// 链接允许的协议
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// scanWord 从 start 开始读取连续的单词字符，最多 limit 个，返回结束位置
+func scanWord(s []rune, start, limit int) int {
+	end := start
+	for end < len(s) && end-start < limit && isWordRune(s[end]) {
+		end++
+	}
+	return end
+}
+
+// atWordBoundary 判断位置 i 之前是否为单词边界，用于区分 @提及 与邮箱地址等
+func atWordBoundary(s []rune, i int) bool {
+	return i == 0 || !(isWordRune(s[i-1]) || s[i-1] == '@' || s[i-1] == '#' || s[i-1] == '/')
+}
+
+// scanMention 识别位置 i 处的 @提及，返回被提及的名称和结束位置
+func scanMention(s []rune, i int) (string, int) {
+	if s[i] != '@' || !atWordBoundary(s, i) {
+		return "", i
+	}
+	end := scanWord(s, i+1, maxMentionLength)
+	if end == i+1 {
+		return "", i
+	}
+	return string(s[i+1 : end]), end
+}
+
+// scanTag 识别位置 i 处的 #话题，返回话题名和结束位置
+func scanTag(s []rune, i int) (string, int) {
+	if s[i] != '#' || !atWordBoundary(s, i) {
+		return "", i
+	}
+	end := scanWord(s, i+1, maxTagLength)
+	if end == i+1 {
+		return "", i
+	}
+	return string(s[i+1 : end]), end
+}
+
+// SafeURL 判断链接是否使用允许的协议，站内相对路径同样允许
+func SafeURL(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.ContainsAny(raw, " \t\n\"'<>`") {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// 只允许以 / 开头的站内路径，拒绝 //evil.com 这类协议相对地址
+		return strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//")
+	}
+	return allowedSchemes[strings.ToLower(u.Scheme)]
+}
+
+func renderInline(text string) string {
+	var b strings.Builder
+	writeInline(&b, []rune(text), 0, true)
+	return b.String()
+}
+
+// findClosing 在 s[from:] 中查找分隔符 delim，返回其起始位置
+func findClosing(s []rune, from int, delim string) int {
+	d := []rune(delim)
+	for i := from; i+len(d) <= len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if string(s[i:i+len(d)]) == delim {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeLink(b *strings.Builder, href string, label func()) {
+	b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer" target="_blank">`)
+	label()
+	b.WriteString("</a>")
+}
+
+// writeInline 渲染行内元素，allowLinks 为 false 时不再生成链接（链接文字内部）
+func writeInline(b *strings.Builder, s []rune, depth int, allowLinks bool) {
+	for i := 0; i < len(s); i++ {
+		r := s[i]
+
+		// 反斜杠转义标点符号
+		if r == '\\' && i+1 < len(s) && unicode.IsPunct(s[i+1]) {
+			b.WriteString(html.EscapeString(string(s[i+1])))
+			i++
+			continue
+		}
+
+		if r == '`' {
+			if end := findClosing(s, i+1, "`"); end > i+1 {
+				b.WriteString("<code>" + html.EscapeString(string(s[i+1:end])) + "</code>")
+				i = end
+				continue
+			}
+		}
+
+		if depth < maxInlineDepth {
+			if emitted, next := writeEmphasis(b, s, i, depth, allowLinks); emitted {
+				i = next
+				continue
+			}
+		}
+
+		if allowLinks && r == '[' {
+			if textEnd := findClosing(s, i+1, "]("); textEnd > i+1 {
+				if urlEnd := findClosing(s, textEnd+2, ")"); urlEnd > textEnd+2 {
+					href := strings.TrimSpace(string(s[textEnd+2 : urlEnd]))
+					if SafeURL(href) {
+						writeLink(b, href, func() { writeInline(b, s[i+1:textEnd], depth+1, false) })
+						i = urlEnd
+						continue
+					}
+				}
+			}
+		}
+
+		if allowLinks && (r == 'h' || r == 'H') && atWordBoundary(s, i) {
+			if end := scanAutoLink(s, i); end > i {
+				href := string(s[i:end])
+				writeLink(b, href, func() { b.WriteString(html.EscapeString(href)) })
+				i = end - 1
+				continue
+			}
+		}
+
+		if name, end := scanMention(s, i); end > i {
+			b.WriteString(`<span class="mention" data-mention="` + html.EscapeString(name) + `">@` + html.EscapeString(name) + `</span>`)
+			i = end - 1
+			continue
+		}
+
+		if tag, end := scanTag(s, i); end > i {
+			b.WriteString(`<span class="tag" data-tag="` + html.EscapeString(tag) + `">#` + html.EscapeString(tag) + `</span>`)
+			i = end - 1
+			continue
+		}
+
+		b.WriteString(html.EscapeString(string(r)))
+	}
+}
+
+// writeEmphasis 处理 **粗体**、*斜体*、~~删除线~~
+func writeEmphasis(b *strings.Builder, s []rune, i, depth int, allowLinks bool) (bool, int) {
+	for _, e := range []struct{ delim, tag string }{
+		{"**", "strong"},
+		{"~~", "del"},
+		{"*", "em"},
+	} {
+		d := []rune(e.delim)
+		if i+len(d) > len(s) || string(s[i:i+len(d)]) != e.delim {
+			continue
+		}
+		start := i + len(d)
+		// 分隔符后紧跟空白时不视为强调，例如 "a * b"
+		if start >= len(s) || unicode.IsSpace(s[start]) {
+			continue
+		}
+		end := findClosing(s, start, e.delim)
+		if end <= start || unicode.IsSpace(s[end-1]) {
+			continue
+		}
+		b.WriteString("<" + e.tag + ">")
+		writeInline(b, s[start:end], depth+1, allowLinks)
+		b.WriteString("</" + e.tag + ">")
+		return true, end + len(d) - 1
+	}
+	return false, i
+}
+
+// scanAutoLink 识别裸露的 http(s) 链接，返回结束位置，末尾的标点不计入链接
+func scanAutoLink(s []rune, i int) int {
+	rest := strings.ToLower(string(s[i:min(len(s), i+8)]))
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+		return i
+	}
+	end := i
+	for end < len(s) && !unicode.IsSpace(s[end]) && !strings.ContainsRune("<>\"'`", s[end]) && s[end] < unicode.MaxASCII {
+		end++
+	}
+	for end > i && strings.ContainsRune(".,;:!?)]", s[end-1]) {
+		end--
+	}
+	if !SafeURL(string(s[i:end])) || end-i <= len("https://") {
+		return i
+	}
+	return end
+}
+
+var mentionAttrPattern = regexp.MustCompile(`data-mention="([^"]*)"`)
+
+// ExtractMentions 从 Render 的输出中提取被 @提及的名称（去重，保持出现顺序），
+// 基于渲染结果提取可以保证代码块等不会被误识别为提及
+func ExtractMentions(renderedHTML string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range mentionAttrPattern.FindAllStringSubmatch(renderedHTML, -1) {
+		name := html.UnescapeString(m[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
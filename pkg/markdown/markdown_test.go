@@ -0,0 +1,186 @@
+package markdown
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderLinks(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"站内路径", "[帖子](/post/1)", `<p><a href="/post/1" rel="nofollow noopener noreferrer" target="_blank">帖子</a></p>`},
+		{"https", "[x](https://a.com)", `<p><a href="https://a.com" rel="nofollow noopener noreferrer" target="_blank">x</a></p>`},
+		{"javascript协议", "[x](javascript:alert(1))", `<p>[x](javascript:alert(1))</p>`},
+		{"大小写混合的javascript", "[x](JaVaScRiPt:alert(1))", `<p>[x](JaVaScRiPt:alert(1))</p>`},
+		{"前导空白的javascript", "[x]( javascript:alert(1))", `<p>[x]( javascript:alert(1))</p>`},
+		{"data协议", "[x](data:text/html,abc)", `<p>[x](data:text/html,abc)</p>`},
+		{"协议相对地址", "[x](//evil.com)", `<p>[x](//evil.com)</p>`},
+		{"图片语法不生成链接", "![x](javascript:1)", `<p>![x](javascript:1)</p>`},
+		{"链接文字内不再生成链接", "[https://a.com](/p)", `<p><a href="/p" rel="nofollow noopener noreferrer" target="_blank">https://a.com</a></p>`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := strings.TrimSpace(Render(c.input)); got != c.want {
+				t.Errorf("Render(%q)\n got %s\nwant %s", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://a.com":          true,
+		"http://a.com/x?y=1":     true,
+		"mailto:a@b.com":         true,
+		"/post/1":                true,
+		"javascript:alert(1)":    false,
+		"JAVASCRIPT:alert(1)":    false,
+		"vbscript:msgbox":        false,
+		"data:text/html,x":       false,
+		"//evil.com":             false,
+		"post/1":                 false,
+		"https://a.com/\"x":      false,
+		"https://a.com/ onclick": false,
+		"":                       false,
+	}
+	for input, want := range cases {
+		if got := SafeURL(input); got != want {
+			t.Errorf("SafeURL(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestRenderEscapesRawHTML(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"script", "<script>alert(1)</script>", `<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>`},
+		{"img onerror", "<img src=x onerror=alert(1)>", `<p>&lt;img src=x onerror=alert(1)&gt;</p>`},
+		{"a标签", `<a href="javascript:x">y</a>`, `<p>&lt;a href=&#34;javascript:x&#34;&gt;y&lt;/a&gt;</p>`},
+		{"实体不被还原", "a&lt;b", `<p>a&amp;lt;b</p>`},
+		{"代码块中的script", "```\n<script>alert(1)</script>\n```", `<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>`},
+		{"代码语言不能带引号", "```js\" onclick=\"x\ncode\n```", "<p>```js&#34; onclick=&#34;x<br>\ncode</p>\n<pre><code></code></pre>"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := strings.TrimSpace(Render(c.input)); got != c.want {
+				t.Errorf("Render(%q)\n got %s\nwant %s", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRenderAttributeBreakingQuotes(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"自动链接中的双引号", `https://a.com/"onmouseover="alert(1)`,
+			`<p><a href="https://a.com/" rel="nofollow noopener noreferrer" target="_blank">https://a.com/</a>&#34;onmouseover=&#34;alert(1)</p>`},
+		{"自动链接中的单引号", `https://a.com/'onmouseover='alert(1)`,
+			`<p><a href="https://a.com/" rel="nofollow noopener noreferrer" target="_blank">https://a.com/</a>&#39;onmouseover=&#39;alert(1)</p>`},
+		{"自动链接中的尖括号", `https://a.com/<script>`,
+			`<p><a href="https://a.com/" rel="nofollow noopener noreferrer" target="_blank">https://a.com/</a>&lt;script&gt;</p>`},
+		{"提及中的双引号", `@张三" onclick="x`,
+			`<p><span class="mention" data-mention="张三">@张三</span>&#34; onclick=&#34;x</p>`},
+		{"话题中的双引号", `#话题" onclick="x`,
+			`<p><span class="tag" data-tag="话题">#话题</span>&#34; onclick=&#34;x</p>`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := strings.TrimSpace(Render(c.input)); got != c.want {
+				t.Errorf("Render(%q)\n got %s\nwant %s", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestExtractMentionsSkipsCode(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"普通提及", "@张三 和 @李四，再次 @张三", []string{"张三", "李四"}},
+		{"行内代码", "`@张三` 和 @李四", []string{"李四"}},
+		{"围栏代码块", "```\n@张三\n```\n@李四", []string{"李四"}},
+		{"邮箱地址", "a@b.com", nil},
+		{"转义的@", `\@张三`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ExtractMentions(Render(c.input)); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("ExtractMentions(%q) = %v, want %v", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+var tagPattern = regexp.MustCompile(`</?(strong|em|del)>`)
+
+// maxNesting 返回强调标签的最大嵌套层数，标签不配对时返回 -1
+func maxNesting(rendered string) int {
+	depth, max := 0, 0
+	for _, tag := range tagPattern.FindAllString(rendered, -1) {
+		if strings.HasPrefix(tag, "</") {
+			depth--
+		} else {
+			depth++
+		}
+		if depth < 0 {
+			return -1
+		}
+		if depth > max {
+			max = depth
+		}
+	}
+	if depth != 0 {
+		return -1
+	}
+	return max
+}
+
+func TestRenderNestedEmphasis(t *testing.T) {
+	got := strings.TrimSpace(Render("~~a **b *c* b** a~~"))
+	if want := `<p><del>a <strong>b <em>c</em> b</strong> a</del></p>`; got != want {
+		t.Errorf("嵌套强调\n got %s\nwant %s", got, want)
+	}
+
+	inputs := []string{
+		strings.Repeat("*", 10000),
+		strings.Repeat("**~~*a", 200) + strings.Repeat("a*~~**", 200),
+		strings.Repeat("*a **b ~~c ", 100) + strings.Repeat("c~~ b** a* ", 100),
+	}
+	for _, input := range inputs {
+		rendered := Render(input)
+		if depth := maxNesting(rendered); depth < 0 || depth > maxInlineDepth {
+			t.Errorf("强调标签应配对且嵌套不超过%d层，实际 %d", maxInlineDepth, depth)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"丢弃script内容", "<p>a<script>alert(1)</script>b</p>", "<p>ab</p>"},
+		{"移除img", `<p><img src=x onerror="alert(1)">a</p>`, "<p>a</p>"},
+		{"丢弃事件属性", `<p onclick="x">a</p>`, "<p>a</p>"},
+		{"不安全链接整体丢弃", `<a href="javascript:alert(1)">a</a>`, "a"},
+		{"缺少href的链接", `<a>a</a>`, "a"},
+		{"class只允许渲染器的取值", `<span class="evil" data-mention="x">a</span>`, `<span data-mention="x">a</span>`},
+		{"代码语言class", `<code class="language-go">x</code>`, `<code class="language-go">x</code>`},
+		{"属性值重新转义", `<span data-tag="a&quot;b">x</span>`, `<span data-tag="a&#34;b">x</span>`},
+		{"补全未闭合的标签", "<p><strong>a", "<p><strong>a</strong></p>"},
+		{"忽略多余的闭合标签", "a</p></strong>", "a"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Sanitize(c.input); got != c.want {
+				t.Errorf("Sanitize(%q)\n got %s\nwant %s", c.input, got, c.want)
+			}
+		})
+	}
+}
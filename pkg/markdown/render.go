@@ -0,0 +1,128 @@
+// Package markdown 将帖子使用的 Markdown 子集渲染为安全的 HTML
+//
+// 支持的语法：标题、段落、换行、引用、无序/有序列表、分隔线、围栏代码块、
+// 行内代码、粗体、斜体、删除线、链接、自动链接、@提及和 #话题。
+// 不支持原始 HTML，输入中的 HTML 会被当作普通文本转义。
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxQuoteDepth = 3 // 引用最大嵌套层数
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	unorderedPattern   = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	rulePattern        = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fencePattern       = regexp.MustCompile("^\\s{0,3}(```|~~~)\\s*([A-Za-z0-9_+-]*)\\s*$")
+	quotePrefixPattern = regexp.MustCompile(`^\s{0,3}>\s?`)
+)
+
+// Render 渲染 Markdown 并经过白名单清洗后返回 HTML
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), 0)
+	return Sanitize(b.String())
+}
+
+func renderBlocks(b *strings.Builder, lines []string, depth int) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			b.WriteString(renderInline(strings.TrimSpace(line)))
+		}
+		b.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			flush()
+			var code []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != m[1]; i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code")
+			if m[2] != "" {
+				b.WriteString(` class="language-` + m[2] + `"`)
+			}
+			b.WriteString(">")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			continue
+		}
+
+		if rulePattern.MatchString(line) {
+			flush()
+			b.WriteString("<hr>\n")
+			continue
+		}
+
+		if quotePrefixPattern.MatchString(line) {
+			flush()
+			var quoted []string
+			for ; i < len(lines) && quotePrefixPattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePrefixPattern.ReplaceAllString(lines[i], ""))
+			}
+			i--
+			b.WriteString("<blockquote>\n")
+			if depth < maxQuoteDepth {
+				renderBlocks(b, quoted, depth+1)
+			} else {
+				b.WriteString("<p>" + renderInline(strings.Join(quoted, " ")) + "</p>\n")
+			}
+			b.WriteString("</blockquote>\n")
+			continue
+		}
+
+		if unorderedPattern.MatchString(line) || orderedPattern.MatchString(line) {
+			flush()
+			pattern, tag := unorderedPattern, "ul"
+			if !unorderedPattern.MatchString(line) {
+				pattern, tag = orderedPattern, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for ; i < len(lines); i++ {
+				m := pattern.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				b.WriteString("<li>" + renderInline(strings.TrimSpace(m[1])) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+	}
+	flush()
+}
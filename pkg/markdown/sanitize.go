@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// allowedTags 允许输出的标签及其允许的属性
+var allowedTags = map[string]map[string]bool{
+	"p":          {},
+	"br":         {},
+	"hr":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"strong":     {},
+	"em":         {},
+	"del":        {},
+	"code":       {"class": true},
+	"pre":        {},
+	"blockquote": {},
+	"ul":         {},
+	"ol":         {},
+	"li":         {},
+	"a":          {"href": true, "rel": true, "target": true},
+	"span":       {"class": true, "data-mention": true, "data-tag": true},
+}
+
+// 内容需要整体丢弃的标签
+var droppedContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"textarea": true,
+	"title":    true,
+}
+
+// class 属性只允许渲染器生成的取值
+var classPattern = regexp.MustCompile(`^(mention|tag|language-[A-Za-z0-9_+-]+)$`)
+
+// Sanitize 按白名单清洗 HTML：不在白名单中的标签被移除（保留文字），
+// 不在白名单中的属性被丢弃，链接只允许安全协议
+func Sanitize(input string) string {
+	var b strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(input))
+	var open []string // 已输出且尚未闭合的标签
+	skip := 0         // 处于需丢弃内容的标签内部的层数
+
+	for {
+		tt := tokenizer.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tt {
+		case xhtml.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedContentTags[token.Data] {
+				if tt == xhtml.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[token.Data]
+			if !ok || skip > 0 {
+				continue
+			}
+			if token.Data == "a" && !hasSafeHref(token.Attr) {
+				continue
+			}
+			b.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if !attrs[attr.Key] || (attr.Key == "class" && !classPattern.MatchString(attr.Val)) {
+					continue
+				}
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			b.WriteString(">")
+			if token.Data != "br" && token.Data != "hr" {
+				open = append(open, token.Data)
+			}
+
+		case xhtml.EndTagToken:
+			if droppedContentTags[token.Data] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			// 只闭合确实打开过的标签，同时闭合其内部未闭合的标签
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == token.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func hasSafeHref(attrs []xhtml.Attribute) bool {
+	for _, attr := range attrs {
+		if attr.Key == "href" {
+			return SafeURL(attr.Val)
+		}
+	}
+	return false
+}
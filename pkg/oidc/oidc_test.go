@@ -0,0 +1,241 @@
+package oidc_test
+
+import (
+	"CMS/pkg/oidc"
+	"CMS/pkg/oidc/oidctest"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "cms-client"
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+	srv := oidctest.NewServer(testClientID)
+	t.Cleanup(srv.Close)
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:      srv.Issuer,
+		ClientID:    testClientID,
+		RedirectURL: "http://cms.test/api/user/oidc/callback",
+		Scopes:      []string{"openid", "profile"},
+	}, srv.Client())
+	return srv, provider
+}
+
+func TestChallengeRFC7636(t *testing.T) {
+	// RFC 7636 附录 B 的示例
+	got := oidc.Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Fatalf("Challenge = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateVerifier(t *testing.T) {
+	a, err := oidc.GenerateVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := oidc.GenerateVerifier()
+	// RFC 7636 要求 43-128 个 unreserved 字符
+	if len(a) != 43 || strings.ContainsAny(a, "+/=") {
+		t.Fatalf("verifier 格式错误: %q", a)
+	}
+	if a == b {
+		t.Fatal("verifier 应随机生成")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	verifier, _ := oidc.GenerateVerifier()
+
+	raw, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+	if !strings.HasPrefix(raw, srv.URL+"/authorize?") {
+		t.Fatalf("授权地址错误: %s", raw)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        oidc.Challenge(verifier),
+		"code_challenge_method": "S256",
+		"scope":                 "openid profile",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if q.Get("code_verifier") != "" {
+		t.Error("授权地址不能包含 code_verifier")
+	}
+}
+
+func TestExchangeAndVerify(t *testing.T) {
+	for _, method := range []string{"RS256", "ES256"} {
+		t.Run(method, func(t *testing.T) {
+			srv, provider := newTestProvider(t)
+			srv.SigningMethod = method
+			ctx := context.Background()
+			verifier, _ := oidc.GenerateVerifier()
+			authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-abc", verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, code, err := srv.Authorize(authURL, jwt.MapClaims{"sub": "user-1", "student_id": "2023001"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := provider.Exchange(ctx, code, verifier)
+			if err != nil {
+				t.Fatalf("换取 token 失败: %v", err)
+			}
+			claims, err := provider.VerifyIDToken(ctx, raw, "nonce-abc")
+			if err != nil {
+				t.Fatalf("校验 ID Token 失败: %v", err)
+			}
+			if claims["sub"] != "user-1" || claims["student_id"] != "2023001" {
+				t.Fatalf("声明错误: %v", claims)
+			}
+
+			// 授权码只能使用一次
+			if _, err := provider.Exchange(ctx, code, verifier); err == nil {
+				t.Fatal("重复使用授权码应失败")
+			}
+		})
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	ctx := context.Background()
+	verifier, _ := oidc.GenerateVerifier()
+	authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+	_, code, err := srv.Authorize(authURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := oidc.GenerateVerifier()
+	if _, err := provider.Exchange(ctx, code, other); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("code_verifier 不匹配时应失败: %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	ctx := context.Background()
+
+	hs256 := func(claims jwt.MapClaims) string {
+		// 用客户端ID作为 HMAC 密钥伪造的 token（算法混淆攻击）
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = oidctest.RSAKeyID
+		raw, _ := token.SignedString([]byte(testClientID))
+		return raw
+	}
+	none := func(claims jwt.MapClaims) string {
+		raw, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		return raw
+	}
+
+	cases := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		sign   func(jwt.MapClaims) string
+		nonce  string
+		errIs  error
+	}{
+		{name: "nonce不一致", nonce: "other-nonce", errIs: oidc.ErrNonceMismatch},
+		{name: "缺少nonce", mutate: func(c jwt.MapClaims) { delete(c, "nonce") }, errIs: oidc.ErrNonceMismatch},
+		{name: "issuer错误", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, errIs: jwt.ErrTokenInvalidIssuer},
+		{name: "audience错误", mutate: func(c jwt.MapClaims) { c["aud"] = "another-client" }, errIs: jwt.ErrTokenInvalidAudience},
+		{name: "已过期", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Minute).Unix() }, errIs: jwt.ErrTokenExpired},
+		{name: "缺少exp", mutate: func(c jwt.MapClaims) { delete(c, "exp") }, errIs: jwt.ErrTokenRequiredClaimMissing},
+		{name: "HS256算法", sign: hs256, errIs: jwt.ErrTokenSignatureInvalid},
+		{name: "none算法", sign: none, errIs: jwt.ErrTokenSignatureInvalid},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			claims := srv.Claims("nonce-1", "user-1")
+			if c.mutate != nil {
+				c.mutate(claims)
+			}
+			var raw string
+			if c.sign != nil {
+				raw = c.sign(claims)
+			} else {
+				var err error
+				if raw, err = srv.Sign(claims); err != nil {
+					t.Fatal(err)
+				}
+			}
+			nonce := "nonce-1"
+			if c.nonce != "" {
+				nonce = c.nonce
+			}
+			_, err := provider.VerifyIDToken(ctx, raw, nonce)
+			if err == nil {
+				t.Fatal("应校验失败")
+			}
+			if !errors.Is(err, c.errIs) {
+				t.Fatalf("错误类型不符: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenForeignKey(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	// 另一个身份提供方签发、kid 相同的 token 不能通过签名校验
+	other := oidctest.NewServer(testClientID)
+	defer other.Close()
+	other.Issuer = srv.Issuer
+
+	raw, err := other.Sign(other.Claims("nonce", "user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), raw, "nonce"); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("签名密钥不符时应失败: %v", err)
+	}
+}
+
+func TestVerifyIDTokenWithoutKeyID(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	srv.SingleKey = true
+	ctx := context.Background()
+
+	// 第一次校验拉取 JWKS，之后在最小刷新间隔内继续使用缓存中唯一的密钥
+	for i := 0; i < 3; i++ {
+		raw, err := srv.Sign(srv.Claims("nonce", "user-1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := provider.VerifyIDToken(ctx, raw, "nonce"); err != nil {
+			t.Fatalf("第%d次校验不带 kid 的 token 失败: %v", i+1, err)
+		}
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	srv, provider := newTestProvider(t)
+	srv.Issuer = "https://evil.example"
+
+	verifier, _ := oidc.GenerateVerifier()
+	if _, err := provider.AuthCodeURL(context.Background(), "s", "n", verifier); err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("发现文档 issuer 不一致时应失败: %v", err)
+	}
+}
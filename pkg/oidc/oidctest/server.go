@@ -0,0 +1,213 @@
+// Package oidctest 提供用于测试的本地 OpenID Connect 身份提供方：服务发现、JWKS 和授权码换取 token，
+// 授权码换取时按 PKCE S256 校验 code_verifier
+package oidctest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	RSAKeyID = "rsa-1"
+	ECKeyID  = "ec-1"
+)
+
+type authRequest struct {
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+// Server 本地身份提供方，Issuer 默认为服务地址，可在首次服务发现前修改以模拟 issuer 不一致
+type Server struct {
+	*httptest.Server
+	Issuer        string
+	ClientID      string
+	SigningMethod string // 签发 ID Token 的算法：RS256（默认）或 ES256
+	SingleKey     bool   // JWKS 只发布 SigningMethod 对应的一个密钥，签发的 ID Token 不带 kid
+
+	RSAKey *rsa.PrivateKey
+	ECKey  *ecdsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+// NewServer 启动身份提供方，测试结束时需调用 Close
+func NewServer(clientID string) *Server {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		ClientID:      clientID,
+		SigningMethod: "RS256",
+		RSAKey:        rsaKey,
+		ECKey:         ecKey,
+		codes:         make(map[string]authRequest),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	mux.HandleFunc("/token", s.handleToken)
+	s.Server = httptest.NewServer(mux)
+	s.Issuer = s.URL
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func b64(n *big.Int, size int) string {
+	buf := n.Bytes()
+	if size > len(buf) {
+		buf = append(make([]byte, size-len(buf)), buf...)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.Issuer,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	rsaKey := map[string]string{
+		"kty": "RSA", "kid": RSAKeyID, "use": "sig", "alg": "RS256",
+		"n": b64(s.RSAKey.N, 0), "e": b64(big.NewInt(int64(s.RSAKey.E)), 0),
+	}
+	ecKey := map[string]string{
+		"kty": "EC", "kid": ECKeyID, "use": "sig", "alg": "ES256", "crv": "P-256",
+		"x": b64(s.ECKey.X, 32), "y": b64(s.ECKey.Y, 32),
+	}
+	keys := []map[string]string{rsaKey, ecKey}
+	if s.SingleKey {
+		key := rsaKey
+		if s.SigningMethod == "ES256" {
+			key = ecKey
+		}
+		keys = []map[string]string{key}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	req, ok := s.codes[code]
+	delete(s.codes, code) // 授权码只能使用一次
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	claims := s.Claims(req.nonce, "")
+	for k, v := range req.claims {
+		claims[k] = v
+	}
+	idToken, err := s.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// Claims 返回一组有效的 ID Token 标准声明
+func (s *Server) Claims(nonce, subject string) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.Issuer,
+		"aud": s.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if subject != "" {
+		claims["sub"] = subject
+	}
+	return claims
+}
+
+// Sign 按 SigningMethod 签发 ID Token
+func (s *Server) Sign(claims jwt.MapClaims) (string, error) {
+	if s.SigningMethod == "ES256" {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		if !s.SingleKey {
+			token.Header["kid"] = ECKeyID
+		}
+		return token.SignedString(s.ECKey)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if !s.SingleKey {
+		token.Header["kid"] = RSAKeyID
+	}
+	return token.SignedString(s.RSAKey)
+}
+
+// Authorize 模拟用户在身份提供方完成登录：解析授权地址中的 state、nonce 和 code_challenge，
+// 返回 state 和一次性授权码；claims 会合并到签发的 ID Token 中（可覆盖 nonce 等标准声明）
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) (state, code string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		return "", "", errors.New("oidctest: missing PKCE challenge")
+	}
+	if q.Get("client_id") != s.ClientID || q.Get("response_type") != "code" {
+		return "", "", errors.New("oidctest: invalid authorization request")
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	code = hex.EncodeToString(buf)
+	s.mu.Lock()
+	s.codes[code] = authRequest{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+	s.mu.Unlock()
+	return q.Get("state"), code, nil
+}
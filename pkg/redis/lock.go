@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// unlockScript 只有持有者（token 一致）才能释放锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// TryLock 尝试获取分布式锁，成功时返回用于释放锁的 token
+func TryLock(key string, ttl time.Duration) (string, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := RedisClient.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// Unlock 释放分布式锁
+func Unlock(key, token string) error {
+	return unlockScript.Run(ctx, RedisClient, []string{key}, token).Err()
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"CMS/config"
+	"errors"
+	"io"
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("storage: file not found")
+
+// Storage 文件存储后端，key 为相对路径（如 2025/08/14/xxx.jpg）
+type Storage interface {
+	Save(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Store 全局存储实例
+var Store Storage
+
+// Init 根据配置初始化存储后端，目前只支持本地文件系统
+func Init() {
+	cfg := config.LoadedConfig
+	switch cfg.Storage.Driver {
+	case "local", "":
+		Store = NewLocalStorage(cfg.Storage.LocalPath)
+	default:
+		panic("不支持的存储类型: " + cfg.Storage.Driver)
+	}
+}
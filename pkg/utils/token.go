@@ -10,33 +10,39 @@ import (
 
 type UserClaims struct {
 	UserID uint `json:"user_id"`
+	MFA    bool `json:"mfa,omitempty"` // 登录时是否通过了两步验证
 	jwt.RegisteredClaims
 }
 
 var ErrTokenHandlingFailed = errors.New("token handling failed")
 
-// GenerateToken 用于生成 JWT token
-func GenerateToken(userID uint) (string, error) {
+// GenerateSessionToken 为登录会话生成 JWT token，sessionID 写入 jti，mfa 标记本次登录是否通过了两步验证；
+// 同时返回 token 的过期时间，用于会话记录
+func GenerateSessionToken(userID uint, sessionID string, mfa bool) (string, time.Time, error) {
 	// 加载配置
 	cfg, err := config.Load()
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	lifespan := cfg.JWT.ExpirationHours
 	secretKey := cfg.JWT.SecretKey
 
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(lifespan) * time.Hour)
 	claims := UserClaims{
 		UserID: userID,
+		MFA:    mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(lifespan) * time.Hour)), // 过期时间
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                                          // 签发时间
-			NotBefore: jwt.NewNumericDate(time.Now()),                                          // 生效时间
+			ID:        sessionID,                     // 会话ID
+			ExpiresAt: jwt.NewNumericDate(expiresAt), // 过期时间
+			IssuedAt:  jwt.NewNumericDate(now),       // 签发时间
+			NotBefore: jwt.NewNumericDate(now),       // 生效时间
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(secretKey))
-	return tokenString, err
+	return tokenString, expiresAt, err
 }
 
 // ExtractToken 用于从 JWT token 中提取 user_id
@@ -0,0 +1,222 @@
+// Package xlsx 读取 Excel 2007+（.xlsx）文件第一个工作表的单元格文本，
+// 只依赖标准库的 zip 和 xml 解析，不支持公式计算和样式
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxPartSize = 64 << 20 // 单个解压后的 xml 部件大小上限，防止压缩炸弹
+	maxColumns  = 16384    // Excel 的最大列数
+)
+
+var ErrNoSheet = errors.New("xlsx: workbook has no sheet")
+
+type workbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText 共享字符串和内联字符串：纯文本在 t 中，富文本分散在多个 r/t 中
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richText) String() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var b strings.Builder
+	b.WriteString(r.T)
+	for _, run := range r.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+type sharedStrings struct {
+	Items []richText `xml:"si"`
+}
+
+type worksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx: missing part %s", name)
+	}
+	if f.UncompressedSize64 > maxPartSize {
+		return fmt.Errorf("xlsx: part %s too large", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v)
+}
+
+// firstSheetPath 按 workbook.xml 中的顺序找到第一个工作表的部件路径
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var wb workbook
+	if err := readPart(files, "xl/workbook.xml", &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrNoSheet
+	}
+	var rels relationships
+	if err := readPart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", ErrNoSheet
+}
+
+// columnIndex 将单元格引用（如 "AB12"）的列字母转换为从0开始的列号
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 || col > maxColumns {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// formatNumber 整数值去掉科学计数法和小数部分，避免学号等长数字变形
+func formatNumber(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return v
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return v
+}
+
+// ReadFirstSheet 读取第一个工作表，返回按行排列的单元格文本；空行和行内缺失的单元格以空字符串补齐
+func ReadFirstSheet(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared sharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := readPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var ws worksheet
+	if err := readPart(files, sheetPath, &ws); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range ws.Rows {
+		// 行号缺失时按顺序排列；跳过的空行补齐为空行，保证行号与表格一致
+		rowIndex := len(rows)
+		if row.R > 0 {
+			rowIndex = row.R - 1
+		}
+		for len(rows) < rowIndex {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				var ok bool
+				if col, ok = columnIndex(cell.Ref); !ok {
+					return nil, fmt.Errorf("xlsx: invalid cell reference %q", cell.Ref)
+				}
+			}
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("xlsx: invalid shared string index in %s", cell.Ref)
+				}
+				value = shared.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "str", "e":
+				value = cell.Value
+			case "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.Value]
+			default:
+				value = formatNumber(cell.Value)
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			if col < len(cells) {
+				cells[col] = value
+			} else {
+				cells = append(cells, value)
+			}
+		}
+		if rowIndex < len(rows) {
+			rows[rowIndex] = cells
+		} else {
+			rows = append(rows, cells)
+		}
+	}
+	return rows, nil
+}